@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/cache"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/cache/redis"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/database"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/database/mongodb"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/errorhandler"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/logger"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/metrics"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/tracing"
+	"github.com/getsentry/sentry-go"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// the requests which are running when the API receives SIGTERM get this long to finish
+	shutdownTimeout = 25 * time.Second
+
+	// the events which are buffered by sentry get this long to be sent on shutdown
+	sentryFlushTimeout = 2 * time.Second
+)
+
+// application owns the connections which are shared by all the handlers of the API so they are opened once and closed
+// when the API stops
+type application struct {
+	mongoClient  *mongo.Client
+	db           database.DB
+	cache        cache.Cache
+	errorHandler errorhandler.ErrorHandler
+	logger       logger.Logger
+}
+
+// newApplication opens the connections of the API
+func newApplication() (*application, error) {
+	errorHandler, err := errorhandler.NewSentryErrorHandler(sentry.ClientOptions{
+		// Either set your DSN here or set the SENTRY_DSN environment variable.
+		Dsn: os.Getenv("SENTRY_DSN"),
+		// Enable printing of SDK debug messages.
+		// Useful when getting started or trying to figure something out.
+		Debug: true,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot initialize sentry")
+	}
+
+	mongoClient, err := mongo.Connect(context.Background(), options.Client().ApplyURI(os.Getenv("MONGODB_URI")).SetMonitor(metrics.NewMongoDBMonitor(tracing.NewMongoDBMonitor())))
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot connect to mongoDB")
+	}
+
+	return &application{
+		mongoClient: mongoClient,
+		db:          mongodb.NewMongoDB(mongoClient.Database(os.Getenv("MONGODB_DB_NAME"))),
+		cache: redis.NewClient(redis.Options{
+			Address:  os.Getenv("REDIS_ADDRESS"),
+			Password: os.Getenv("REDIS_PASSWORD"),
+			DB:       0,
+		}),
+		errorHandler: errorHandler,
+		logger:       logger.NewGoKitLogger(os.Stdout),
+	}, nil
+}
+
+// serve runs the server until it fails or the process receives SIGINT or SIGTERM. On a signal the server stops
+// accepting connections and waits for the requests which are running to finish.
+func (app *application) serve(server *http.Server) error {
+	serverErrors := make(chan error, 1)
+	go func() {
+		serverErrors <- server.ListenAndServe()
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	select {
+	case err := <-serverErrors:
+		return errors.Wrap(err, "cannot serve the API")
+	case received := <-signals:
+		log.Printf("received %s, waiting for the running requests to finish", received)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	err := server.Shutdown(ctx)
+	if err != nil {
+		return errors.Wrap(err, "cannot drain the running requests")
+	}
+
+	return nil
+}
+
+// Close disconnects from mongodb and redis and sends the events which are buffered by sentry
+func (app *application) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	// every connection is closed even when closing another one failed
+	var failures []string
+	if err := app.mongoClient.Disconnect(ctx); err != nil {
+		failures = append(failures, errors.Wrap(err, "cannot disconnect from mongoDB").Error())
+	}
+
+	if err := app.cache.Close(); err != nil {
+		failures = append(failures, errors.Wrap(err, "cannot close the redis client").Error())
+	}
+
+	// sentry has no client when SENTRY_DSN is not set
+	if sentry.CurrentHub().Client() != nil && !sentry.Flush(sentryFlushTimeout) {
+		failures = append(failures, "cannot send the buffered sentry events")
+	}
+
+	if len(failures) > 0 {
+		return errors.New(strings.Join(failures, ", "))
+	}
+
+	return nil
+}
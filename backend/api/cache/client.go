@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"context"
 	"time"
 
 	"github.com/pkg/errors"
@@ -15,4 +16,15 @@ var (
 type Cache interface {
 	Set(key, value string, expiration time.Duration) error
 	Get(key string) (string, error)
+	Delete(key string) error
+	// SetIfNotExists sets the value only when the key does not exist. It returns false when the key already exists.
+	SetIfNotExists(key, value string, expiration time.Duration) (bool, error)
+	// GetAndDelete gets a value and deletes it in a single transaction so only one caller can get the value
+	GetAndDelete(key string) (string, error)
+	// Increment adds 1 to the number stored at key and returns the new number. The expiration is reset on every call.
+	Increment(key string, expiration time.Duration) (int64, error)
+	// Ping checks that the cache is reachable
+	Ping(ctx context.Context) error
+	// Close closes the connections to the cache
+	Close() error
 }
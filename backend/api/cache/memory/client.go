@@ -0,0 +1,142 @@
+package memory
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/cache"
+)
+
+type item struct {
+	value     string
+	expiresAt time.Time
+}
+
+// Client is a cache which keeps the values in memory. It is not shared between processes so it is only useful for
+// tests and local development.
+type Client struct {
+	mutex sync.Mutex
+	items map[string]item
+	now   func() time.Time
+}
+
+// NewClient creates a new in memory cache
+func NewClient() *Client {
+	return &Client{items: map[string]item{}, now: time.Now}
+}
+
+// SetClock replaces the clock which decides when the values expire
+func (client *Client) SetClock(now func() time.Time) {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+	client.now = now
+}
+
+// Set is responsible for setting a value in the cache
+func (client *Client) Set(key string, value string, expiration time.Duration) error {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	client.set(key, value, expiration)
+	return nil
+}
+
+// Get gets a value from the cache
+func (client *Client) Get(key string) (string, error) {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	value, ok := client.get(key)
+	if !ok {
+		return "", cache.ErrCacheMiss
+	}
+	return value.value, nil
+}
+
+// Delete removes a value from the cache
+func (client *Client) Delete(key string) error {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	delete(client.items, key)
+	return nil
+}
+
+// SetIfNotExists sets the value only when the key does not exist
+func (client *Client) SetIfNotExists(key string, value string, expiration time.Duration) (bool, error) {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	if _, ok := client.get(key); ok {
+		return false, nil
+	}
+
+	client.set(key, value, expiration)
+	return true, nil
+}
+
+// GetAndDelete gets a value and deletes it
+func (client *Client) GetAndDelete(key string) (string, error) {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	value, ok := client.get(key)
+	if !ok {
+		return "", cache.ErrCacheMiss
+	}
+
+	delete(client.items, key)
+	return value.value, nil
+}
+
+// Increment adds 1 to the number stored at key and resets the expiration
+func (client *Client) Increment(key string, expiration time.Duration) (int64, error) {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	var count int64
+	if value, ok := client.get(key); ok {
+		var err error
+		count, err = strconv.ParseInt(value.value, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	count++
+	client.set(key, strconv.FormatInt(count, 10), expiration)
+	return count, nil
+}
+
+// Ping always succeeds
+func (client *Client) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Close removes all the values
+func (client *Client) Close() error {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	client.items = map[string]item{}
+	return nil
+}
+
+func (client *Client) get(key string) (value item, ok bool) {
+	value, ok = client.items[key]
+	if ok && !value.expiresAt.IsZero() && !client.now().Before(value.expiresAt) {
+		delete(client.items, key)
+		return value, false
+	}
+	return value, ok
+}
+
+func (client *Client) set(key string, value string, expiration time.Duration) {
+	var expiresAt time.Time
+	if expiration > 0 {
+		expiresAt = client.now().Add(expiration)
+	}
+	client.items[key] = item{value: value, expiresAt: expiresAt}
+}
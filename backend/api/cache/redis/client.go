@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/cache"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/metrics"
 	"github.com/go-redis/redis/v8"
 )
 
@@ -39,6 +40,7 @@ func (client *Client) Set(key string, value string, expiration time.Duration) er
 func (client *Client) Get(key string) (result string, err error) {
 	result, err = client.db.Get(context.Background(), key).Result()
 	if err == redis.Nil {
+		metrics.CountCacheRequest("redis", false)
 		return result, cache.ErrCacheMiss
 	}
 
@@ -46,5 +48,64 @@ func (client *Client) Get(key string) (result string, err error) {
 		return result, err
 	}
 
+	metrics.CountCacheRequest("redis", true)
 	return result, err
 }
+
+// Delete removes a value from the cache
+func (client *Client) Delete(key string) error {
+	return client.db.Del(context.Background(), key).Err()
+}
+
+// SetIfNotExists sets the value only when the key does not exist
+func (client *Client) SetIfNotExists(key string, value string, expiration time.Duration) (bool, error) {
+	return client.db.SetNX(context.Background(), key, value, expiration).Result()
+}
+
+// GetAndDelete gets a value and deletes it in a single transaction
+func (client *Client) GetAndDelete(key string) (result string, err error) {
+	ctx := context.Background()
+
+	pipeline := client.db.TxPipeline()
+	get := pipeline.Get(ctx, key)
+	pipeline.Del(ctx, key)
+
+	_, err = pipeline.Exec(ctx)
+	if err == redis.Nil {
+		metrics.CountCacheRequest("redis", false)
+		return result, cache.ErrCacheMiss
+	}
+
+	if err != nil {
+		return result, err
+	}
+
+	metrics.CountCacheRequest("redis", true)
+	return get.Val(), nil
+}
+
+// Increment adds 1 to the number stored at key and sets the expiration in a single transaction
+func (client *Client) Increment(key string, expiration time.Duration) (int64, error) {
+	ctx := context.Background()
+
+	pipeline := client.db.TxPipeline()
+	increment := pipeline.Incr(ctx, key)
+	pipeline.Expire(ctx, key, expiration)
+
+	_, err := pipeline.Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return increment.Val(), nil
+}
+
+// Ping checks that the redis server is reachable
+func (client *Client) Ping(ctx context.Context) error {
+	return client.db.Ping(ctx).Err()
+}
+
+// Close closes the connections to the redis server
+func (client *Client) Close() error {
+	return client.db.Close()
+}
@@ -0,0 +1,14 @@
+package database
+
+import (
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/entities"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+)
+
+// CalculationResultRepository is the repository for the stored calculation results of imports
+type CalculationResultRepository interface {
+	// FindLatestByTransactionID returns the results of the last calculation run for an import
+	FindLatestByTransactionID(transactionID id.ID) ([]entities.CalculationResult, error)
+	// FindByTransactionID returns the results of all the calculation runs for an import with the newest run first
+	FindByTransactionID(transactionID id.ID) ([]entities.CalculationResult, error)
+}
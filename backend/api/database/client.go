@@ -1,13 +1,31 @@
 package database
 
-import "github.com/pkg/errors"
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
 
 var (
 	// ErrEntityNotFound is returned when an entity does not exist in the database
 	ErrEntityNotFound = errors.New("entity not found")
+
+	// ErrEmailTaken is returned when a user is stored with the email address of another user
+	ErrEmailTaken = errors.New("the email address is used by another user")
 )
 
 // DB is a collection of database repositories
 type DB interface {
 	UserRepository() UserRepository
+	ReviewQueueRepository() ReviewQueueRepository
+	StationRepository() StationRepository
+	RawRecordRepository() RawRecordRepository
+	JobRepository() JobRepository
+	ImportRepository() ImportRepository
+	CalculationResultRepository() CalculationResultRepository
+	UserDataRepository() UserDataRepository
+	// Ping checks that the database is reachable
+	Ping(ctx context.Context) error
+	// CreateIndexes creates the indexes which the repositories depend on e.g the unique indexes
+	CreateIndexes(ctx context.Context) error
 }
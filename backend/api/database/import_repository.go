@@ -0,0 +1,13 @@
+package database
+
+import (
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/entities"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+)
+
+// ImportRepository is the repository for the owners of the imports
+type ImportRepository interface {
+	// Store saves the owner of an import. The owner of an existing import is not changed.
+	Store(entity entities.Import) error
+	FindByTransactionID(transactionID id.ID) (*entities.Import, error)
+}
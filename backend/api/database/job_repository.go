@@ -0,0 +1,17 @@
+package database
+
+import (
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/entities"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+)
+
+// JobRepository is the repository for background jobs
+type JobRepository interface {
+	// Store saves a job. A job with an idempotency key is stored once per user and a job without a key is not stored
+	// when a pending job of the same type already exists for the import.
+	Store(job entities.Job) error
+	FindByID(jobID id.ID) (*entities.Job, error)
+	FindByIdempotencyKey(userID id.ID, key string) (*entities.Job, error)
+	FindByStatus(status entities.JobStatus) ([]entities.Job, error)
+	FindByUserIDAndStatus(userID id.ID, status entities.JobStatus) ([]entities.Job, error)
+}
@@ -0,0 +1,129 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/database"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/entities"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// calculationResultDocument is the database representation of a calculation result
+type calculationResultDocument struct {
+	ID                string    `bson:"id"`
+	RunID             string    `bson:"run_id"`
+	TransactionID     string    `bson:"transaction_id"`
+	Product           string    `bson:"product"`
+	CompanyName       string    `bson:"company_name"`
+	RuleSetVersion    string    `bson:"rule_set_version"`
+	FareTableVersion  string    `bson:"fare_table_version"`
+	CalculatedAt      time.Time `bson:"calculated_at"`
+	Currency          string    `bson:"currency"`
+	JourneyCount      int       `bson:"journey_count"`
+	JourneysPrice     int       `bson:"journeys_price"`
+	SubscriptionPrice int       `bson:"subscription_price"`
+	TotalPrice        int       `bson:"total_price"`
+	IsCheapest        bool      `bson:"is_cheapest"`
+}
+
+// CalculationResultRepository is the mongodb repository for calculation results
+type CalculationResultRepository struct {
+	repository
+}
+
+// NewCalculationResultRepository creates a new instance of the calculation result repository
+func NewCalculationResultRepository(db *mongo.Database, collection string) *CalculationResultRepository {
+	return &CalculationResultRepository{repository{db, collection}}
+}
+
+// FindLatestByTransactionID returns the results of the last calculation run for an import
+func (repository *CalculationResultRepository) FindLatestByTransactionID(transactionID id.ID) (results []entities.CalculationResult, err error) {
+	var latest calculationResultDocument
+	err = repository.Collection().FindOne(
+		repository.DefaultTimeoutContext(),
+		bson.M{"transaction_id": transactionID.String()},
+		options.FindOne().SetSort(bson.M{"calculated_at": -1}),
+	).Decode(&latest)
+
+	if err == mongo.ErrNoDocuments {
+		return results, database.ErrEntityNotFound
+	}
+	if err != nil {
+		return results, errors.Wrap(err, "error fetching the latest calculation result from the database")
+	}
+
+	return repository.find(bson.M{"run_id": latest.RunID})
+}
+
+// FindByTransactionID returns the results of all the calculation runs for an import with the newest run first
+func (repository *CalculationResultRepository) FindByTransactionID(transactionID id.ID) ([]entities.CalculationResult, error) {
+	return repository.find(bson.M{"transaction_id": transactionID.String()})
+}
+
+func (repository *CalculationResultRepository) find(filter bson.M) (results []entities.CalculationResult, err error) {
+	ctx := repository.DefaultTimeoutContext()
+	cursor, err := repository.Collection().Find(
+		ctx,
+		filter,
+		options.Find().SetSort(bson.D{{Key: "calculated_at", Value: -1}, {Key: "product", Value: 1}}),
+	)
+	if err != nil {
+		return results, errors.Wrap(err, "error fetching calculation results from the database")
+	}
+	defer func() { _ = cursor.Close(context.Background()) }()
+
+	for cursor.Next(ctx) {
+		var document calculationResultDocument
+		err = cursor.Decode(&document)
+		if err != nil {
+			return results, errors.Wrap(err, "cannot decode calculation result")
+		}
+
+		result, err := repository.hydrateResultFromDocument(document)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, result)
+	}
+
+	return results, cursor.Err()
+}
+
+func (repository *CalculationResultRepository) hydrateResultFromDocument(document calculationResultDocument) (result entities.CalculationResult, err error) {
+	resultID, err := id.FromString(document.ID)
+	if err != nil {
+		return result, errors.Wrap(err, "could not decode calculation result id from string")
+	}
+
+	runID, err := id.FromString(document.RunID)
+	if err != nil {
+		return result, errors.Wrap(err, "could not decode run id from string")
+	}
+
+	transactionID, err := id.FromString(document.TransactionID)
+	if err != nil {
+		return result, errors.Wrap(err, "could not decode transaction id from string")
+	}
+
+	return entities.CalculationResult{
+		ID:                resultID,
+		RunID:             runID,
+		TransactionID:     transactionID,
+		Product:           document.Product,
+		CompanyName:       document.CompanyName,
+		RuleSetVersion:    document.RuleSetVersion,
+		FareTableVersion:  document.FareTableVersion,
+		CalculatedAt:      document.CalculatedAt,
+		Currency:          document.Currency,
+		JourneyCount:      document.JourneyCount,
+		JourneysPrice:     document.JourneysPrice,
+		SubscriptionPrice: document.SubscriptionPrice,
+		TotalPrice:        document.TotalPrice,
+		IsCheapest:        document.IsCheapest,
+	}, nil
+}
@@ -6,10 +6,14 @@ import (
 
 	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/database"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
 const (
 	dbOperationTimeout = 5 * time.Second
+
+	// duplicateKeyErrorCode is the code of the error which is returned when a write violates a unique index
+	duplicateKeyErrorCode = 11000
 )
 
 type repository struct {
@@ -42,3 +46,68 @@ func NewMongoDB(client *mongo.Database) database.DB {
 func (db *MongoDB) UserRepository() database.UserRepository {
 	return NewUserRepository(db.client, "users")
 }
+
+// ReviewQueueRepository returns the review queue repository
+func (db *MongoDB) ReviewQueueRepository() database.ReviewQueueRepository {
+	return NewReviewQueueRepository(db.client, "review_queue")
+}
+
+// StationRepository returns the NS stations repository
+func (db *MongoDB) StationRepository() database.StationRepository {
+	return NewStationRepository(db.client, "ns_stations")
+}
+
+// RawRecordRepository returns the raw records repository
+func (db *MongoDB) RawRecordRepository() database.RawRecordRepository {
+	return NewRawRecordRepository(db.client, "raw_records")
+}
+
+// JobRepository returns the background jobs repository
+func (db *MongoDB) JobRepository() database.JobRepository {
+	return NewJobRepository(db.client, "jobs")
+}
+
+// ImportRepository returns the repository for the owners of the imports
+func (db *MongoDB) ImportRepository() database.ImportRepository {
+	return NewImportRepository(db.client, "imports")
+}
+
+// CalculationResultRepository returns the calculation results repository
+func (db *MongoDB) CalculationResultRepository() database.CalculationResultRepository {
+	return NewCalculationResultRepository(db.client, "calculation_results")
+}
+
+// UserDataRepository returns the repository which exports and deletes the data of a user
+func (db *MongoDB) UserDataRepository() database.UserDataRepository {
+	return NewUserDataRepository(db.client, "users", "jobs", "imports")
+}
+
+// CreateIndexes creates the indexes which the repositories depend on
+func (db *MongoDB) CreateIndexes(ctx context.Context) error {
+	err := NewUserRepository(db.client, "users").CreateIndexes(ctx)
+	if err != nil {
+		return err
+	}
+
+	return NewJobRepository(db.client, "jobs").CreateIndexes(ctx)
+}
+
+// isDuplicateKeyError checks if a write failed because of a unique index
+func isDuplicateKeyError(err error) bool {
+	switch err := err.(type) {
+	case mongo.WriteException:
+		for _, writeError := range err.WriteErrors {
+			if writeError.Code == duplicateKeyErrorCode {
+				return true
+			}
+		}
+	case mongo.CommandError:
+		return err.Code == duplicateKeyErrorCode
+	}
+	return false
+}
+
+// Ping checks that the primary of the mongodb deployment is reachable
+func (db *MongoDB) Ping(ctx context.Context) error {
+	return db.client.Client().Ping(ctx, readpref.Primary())
+}
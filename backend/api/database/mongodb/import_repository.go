@@ -0,0 +1,87 @@
+package mongodb
+
+import (
+	"time"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/database"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/entities"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// importDocument is the database representation of the owner of an import
+type importDocument struct {
+	TransactionID string    `bson:"transaction_id"`
+	UserID        string    `bson:"user_id"`
+	CreatedAt     time.Time `bson:"created_at"`
+}
+
+// ImportRepository is the mongodb repository for the owners of the imports
+type ImportRepository struct {
+	repository
+}
+
+// NewImportRepository creates a new instance of the import repository
+func NewImportRepository(db *mongo.Database, collection string) *ImportRepository {
+	return &ImportRepository{repository{db, collection}}
+}
+
+// Store saves the owner of an import. The owner of an existing import is not changed.
+func (repository *ImportRepository) Store(entity entities.Import) error {
+	_, err := repository.Collection().UpdateOne(
+		repository.DefaultTimeoutContext(),
+		bson.M{"transaction_id": entity.TransactionID.String()},
+		bson.M{"$setOnInsert": importDocument{
+			TransactionID: entity.TransactionID.String(),
+			UserID:        entity.UserID.String(),
+			CreatedAt:     entity.CreatedAt,
+		}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "cannot store the owner of import '%s'", entity.TransactionID.String())
+	}
+
+	return nil
+}
+
+// FindByTransactionID finds the owner of an import
+func (repository *ImportRepository) FindByTransactionID(transactionID id.ID) (entity *entities.Import, err error) {
+	var document importDocument
+	err = repository.Collection().FindOne(
+		repository.DefaultTimeoutContext(),
+		bson.M{"transaction_id": transactionID.String()},
+	).Decode(&document)
+
+	if err == mongo.ErrNoDocuments {
+		return entity, database.ErrEntityNotFound
+	}
+	if err != nil {
+		return entity, errors.Wrap(err, "error fetching the owner of an import from the database")
+	}
+
+	result, err := repository.hydrateImportFromDocument(document)
+	if err != nil {
+		return entity, err
+	}
+
+	return &result, nil
+}
+
+func (repository *ImportRepository) hydrateImportFromDocument(document importDocument) (entity entities.Import, err error) {
+	entity.TransactionID, err = id.FromString(document.TransactionID)
+	if err != nil {
+		return entity, errors.Wrapf(err, "invalid transaction id '%s'", document.TransactionID)
+	}
+
+	entity.UserID, err = id.FromString(document.UserID)
+	if err != nil {
+		return entity, errors.Wrapf(err, "invalid user id '%s'", document.UserID)
+	}
+
+	entity.CreatedAt = document.CreatedAt
+	return entity, nil
+}
@@ -0,0 +1,216 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/database"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/entities"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/jobs"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// jobDocument is the database representation of a job
+type jobDocument struct {
+	ID             string             `bson:"id"`
+	Type           string             `bson:"type"`
+	Status         string             `bson:"status"`
+	TransactionID  string             `bson:"transaction_id"`
+	UserID         string             `bson:"user_id"`
+	IdempotencyKey string             `bson:"idempotency_key"`
+	Reason         string             `bson:"reason"`
+	Import         *importJobDocument `bson:"import"`
+	ProcessedItems int                `bson:"processed_items"`
+	TotalItems     int                `bson:"total_items"`
+	Attempts       int                `bson:"attempts"`
+	MaxAttempts    int                `bson:"max_attempts"`
+	RunAfter       time.Time          `bson:"run_after"`
+	Error          string             `bson:"error"`
+	CreatedAt      time.Time          `bson:"created_at"`
+	UpdatedAt      time.Time          `bson:"updated_at"`
+}
+
+// importJobDocument is the database representation of the card details of an import job
+type importJobDocument struct {
+	CardNumber string    `bson:"card_number"`
+	StartDate  time.Time `bson:"start_date"`
+	EndDate    time.Time `bson:"end_date"`
+}
+
+// JobRepository is the mongodb repository for background jobs
+type JobRepository struct {
+	repository
+}
+
+// NewJobRepository creates a new instance of the job repository
+func NewJobRepository(db *mongo.Database, collection string) *JobRepository {
+	return &JobRepository{repository{db, collection}}
+}
+
+// CreateIndexes creates the indexes of the jobs
+func (repository *JobRepository) CreateIndexes(ctx context.Context) error {
+	return jobs.CreateIndexes(ctx, repository.Collection())
+}
+
+// Store saves a job. A job with an idempotency key is stored once per user and a job without a key is not stored
+// when a pending job of the same type already exists for the import.
+func (repository *JobRepository) Store(job entities.Job) error {
+	document := bson.M{
+		"id":              job.ID.String(),
+		"type":            job.Type,
+		"status":          job.Status,
+		"transaction_id":  job.TransactionID.String(),
+		"reason":          job.Reason,
+		"processed_items": job.ProcessedItems,
+		"total_items":     job.TotalItems,
+		"attempts":        job.Attempts,
+		"max_attempts":    job.MaxAttempts,
+		"run_after":       job.RunAfter,
+		"created_at":      job.CreatedAt,
+		"updated_at":      job.UpdatedAt,
+	}
+	if job.UserID != nil {
+		document["user_id"] = job.UserID.String()
+	}
+	if job.IdempotencyKey != "" {
+		document["idempotency_key"] = job.IdempotencyKey
+	}
+	if job.Import != nil {
+		document["import"] = bson.M{
+			"card_number": job.Import.CardNumber,
+			"start_date":  job.Import.StartDate,
+			"end_date":    job.Import.EndDate,
+		}
+	}
+
+	return jobs.Store(repository.DefaultTimeoutContext(), repository.Collection(), document)
+}
+
+// FindByID finds a job in the database using it's ID
+func (repository *JobRepository) FindByID(jobID id.ID) (job *entities.Job, err error) {
+	var document jobDocument
+	err = repository.Collection().FindOne(repository.DefaultTimeoutContext(), bson.M{"id": jobID.String()}).Decode(&document)
+
+	if err == mongo.ErrNoDocuments {
+		return job, database.ErrEntityNotFound
+	}
+	if err != nil {
+		return job, errors.Wrap(err, "error fetching single job from the database by id")
+	}
+
+	result, err := repository.hydrateJobFromDocument(document)
+	if err != nil {
+		return job, err
+	}
+
+	return &result, nil
+}
+
+// FindByIdempotencyKey finds the job which a user queued with an idempotency key
+func (repository *JobRepository) FindByIdempotencyKey(userID id.ID, key string) (job *entities.Job, err error) {
+	var document jobDocument
+	err = repository.Collection().FindOne(
+		repository.DefaultTimeoutContext(),
+		bson.M{"user_id": userID.String(), "idempotency_key": key},
+	).Decode(&document)
+
+	if err == mongo.ErrNoDocuments {
+		return job, database.ErrEntityNotFound
+	}
+	if err != nil {
+		return job, errors.Wrap(err, "error fetching single job from the database by idempotency key")
+	}
+
+	result, err := repository.hydrateJobFromDocument(document)
+	if err != nil {
+		return job, err
+	}
+
+	return &result, nil
+}
+
+// FindByStatus returns the jobs with a status, the newest jobs are returned first
+func (repository *JobRepository) FindByStatus(status entities.JobStatus) ([]entities.Job, error) {
+	return repository.find(bson.M{"status": status})
+}
+
+// FindByUserIDAndStatus returns the jobs of a user with a status, the newest jobs are returned first
+func (repository *JobRepository) FindByUserIDAndStatus(userID id.ID, status entities.JobStatus) ([]entities.Job, error) {
+	return repository.find(bson.M{"user_id": userID.String(), "status": status})
+}
+
+func (repository *JobRepository) find(filter bson.M) (jobs []entities.Job, err error) {
+	ctx := repository.DefaultTimeoutContext()
+	cursor, err := repository.Collection().Find(ctx, filter, options.Find().SetSort(bson.M{"created_at": -1}))
+	if err != nil {
+		return jobs, errors.Wrap(err, "error fetching jobs from the database")
+	}
+	defer func() { _ = cursor.Close(context.Background()) }()
+
+	for cursor.Next(ctx) {
+		var document jobDocument
+		err = cursor.Decode(&document)
+		if err != nil {
+			return jobs, errors.Wrap(err, "cannot decode job")
+		}
+
+		job, err := repository.hydrateJobFromDocument(document)
+		if err != nil {
+			return jobs, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, cursor.Err()
+}
+
+func (repository *JobRepository) hydrateJobFromDocument(document jobDocument) (job entities.Job, err error) {
+	jobID, err := id.FromString(document.ID)
+	if err != nil {
+		return job, errors.Wrap(err, "could not decode job id from string")
+	}
+
+	transactionID, err := id.FromString(document.TransactionID)
+	if err != nil {
+		return job, errors.Wrap(err, "could not decode transaction id from string")
+	}
+
+	job = entities.Job{
+		ID:             jobID,
+		Type:           entities.JobType(document.Type),
+		Status:         entities.JobStatus(document.Status),
+		TransactionID:  transactionID,
+		IdempotencyKey: document.IdempotencyKey,
+		Reason:         document.Reason,
+		ProcessedItems: document.ProcessedItems,
+		TotalItems:     document.TotalItems,
+		Attempts:       document.Attempts,
+		MaxAttempts:    document.MaxAttempts,
+		RunAfter:       document.RunAfter,
+		Error:          document.Error,
+		CreatedAt:      document.CreatedAt,
+		UpdatedAt:      document.UpdatedAt,
+	}
+
+	if document.Import != nil {
+		job.Import = &entities.ImportJobOptions{
+			CardNumber: document.Import.CardNumber,
+			StartDate:  document.Import.StartDate,
+			EndDate:    document.Import.EndDate,
+		}
+	}
+
+	if document.UserID != "" {
+		userID, err := id.FromString(document.UserID)
+		if err != nil {
+			return job, errors.Wrap(err, "could not decode user id from string")
+		}
+		job.UserID = &userID
+	}
+
+	return job, nil
+}
@@ -0,0 +1,39 @@
+package mongodb
+
+import (
+	"time"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/database"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// RawRecordRepository is the mongodb repository for raw ov-chipkaart transactions
+type RawRecordRepository struct {
+	repository
+}
+
+// NewRawRecordRepository creates a new instance of the raw record repository
+func NewRawRecordRepository(db *mongo.Database, collection string) *RawRecordRepository {
+	return &RawRecordRepository{repository{db, collection}}
+}
+
+// Ignore marks a raw record so that it's skipped when the records are enriched
+func (repository *RawRecordRepository) Ignore(rawRecordID id.ID) error {
+	result, err := repository.Collection().UpdateOne(
+		repository.DefaultTimeoutContext(),
+		bson.M{"id": rawRecordID.String()},
+		bson.M{"$set": bson.M{"is_ignored": true, "updated_at": time.Now().UTC()}},
+	)
+	if err != nil {
+		return errors.Wrapf(err, "cannot ignore raw record with id '%s'", rawRecordID.String())
+	}
+
+	if result.MatchedCount == 0 {
+		return database.ErrEntityNotFound
+	}
+
+	return nil
+}
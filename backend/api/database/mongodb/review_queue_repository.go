@@ -0,0 +1,174 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/database"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/entities"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// reviewQueueItemDocument is the database representation of a review queue item
+type reviewQueueItemDocument struct {
+	ID          string `bson:"id"`
+	Type        string `bson:"type"`
+	Text        string `bson:"text"`
+	Status      string `bson:"status"`
+	Occurrences int    `bson:"occurrences"`
+	Suggestions []struct {
+		Code       string  `bson:"code"`
+		Name       string  `bson:"name"`
+		Confidence float64 `bson:"confidence"`
+	} `bson:"suggestions"`
+	Reason        string    `bson:"reason"`
+	StationName   string    `bson:"station_name"`
+	RawRecordID   string    `bson:"raw_record_id"`
+	TransactionID string    `bson:"transaction_id"`
+	CreatedAt     time.Time `bson:"created_at"`
+	UpdatedAt     time.Time `bson:"updated_at"`
+}
+
+// ReviewQueueRepository is the mongodb repository for the review queue
+type ReviewQueueRepository struct {
+	repository
+}
+
+// NewReviewQueueRepository creates a new instance of the review queue repository
+func NewReviewQueueRepository(db *mongo.Database, collection string) *ReviewQueueRepository {
+	return &ReviewQueueRepository{repository{db, collection}}
+}
+
+// FindByStatus returns the review queue items with a status. The items which occur most often are returned first.
+func (repository *ReviewQueueRepository) FindByStatus(status entities.ReviewQueueItemStatus) (items []entities.ReviewQueueItem, err error) {
+	findOptions := options.Find().SetSort(bson.D{
+		{Key: "occurrences", Value: -1},
+		{Key: "created_at", Value: 1},
+	})
+
+	return repository.find(bson.M{"status": status}, findOptions)
+}
+
+// FindByID finds a review queue item using it's ID
+func (repository *ReviewQueueRepository) FindByID(itemID id.ID) (item *entities.ReviewQueueItem, err error) {
+	var document reviewQueueItemDocument
+	err = repository.Collection().FindOne(repository.DefaultTimeoutContext(), bson.M{"id": itemID.String()}).Decode(&document)
+
+	if err == mongo.ErrNoDocuments {
+		return item, database.ErrEntityNotFound
+	}
+	if err != nil {
+		return item, errors.Wrap(err, "error fetching single review queue item from the database by id")
+	}
+
+	result, err := repository.hydrateItemFromDocument(document)
+	if err != nil {
+		return item, err
+	}
+
+	return &result, nil
+}
+
+// FindOpenForStationName returns the open items which are caused by an unresolved station name
+func (repository *ReviewQueueRepository) FindOpenForStationName(stationName string) (items []entities.ReviewQueueItem, err error) {
+	return repository.find(bson.M{
+		"status": entities.ReviewQueueItemStatusOpen,
+		"$or": bson.A{
+			bson.M{"type": entities.ReviewQueueItemTypeUnresolvedStationName, "text": stationName},
+			bson.M{"type": entities.ReviewQueueItemTypeFailedEnrichment, "station_name": stationName},
+		},
+	}, options.Find())
+}
+
+// UpdateStatus changes the status of review queue items
+func (repository *ReviewQueueRepository) UpdateStatus(itemIDs []id.ID, status entities.ReviewQueueItemStatus) error {
+	var ids bson.A
+	for _, itemID := range itemIDs {
+		ids = append(ids, itemID.String())
+	}
+
+	_, err := repository.Collection().UpdateMany(
+		repository.DefaultTimeoutContext(),
+		bson.M{"id": bson.M{"$in": ids}},
+		bson.M{"$set": bson.M{"status": status, "updated_at": time.Now().UTC()}},
+	)
+	if err != nil {
+		return errors.Wrap(err, "cannot update the status of review queue items")
+	}
+
+	return nil
+}
+
+func (repository *ReviewQueueRepository) find(filter bson.M, findOptions *options.FindOptions) (items []entities.ReviewQueueItem, err error) {
+	ctx := repository.DefaultTimeoutContext()
+	cursor, err := repository.Collection().Find(ctx, filter, findOptions)
+	if err != nil {
+		return items, errors.Wrap(err, "error fetching review queue items from the database")
+	}
+	defer func() { _ = cursor.Close(context.Background()) }()
+
+	for cursor.Next(ctx) {
+		var document reviewQueueItemDocument
+		err = cursor.Decode(&document)
+		if err != nil {
+			return items, errors.Wrap(err, "cannot decode review queue item")
+		}
+
+		item, err := repository.hydrateItemFromDocument(document)
+		if err != nil {
+			return items, err
+		}
+		items = append(items, item)
+	}
+
+	return items, cursor.Err()
+}
+
+func (repository *ReviewQueueRepository) hydrateItemFromDocument(document reviewQueueItemDocument) (item entities.ReviewQueueItem, err error) {
+	itemID, err := id.FromString(document.ID)
+	if err != nil {
+		return item, errors.Wrap(err, "could not decode review queue item id from string")
+	}
+
+	item = entities.ReviewQueueItem{
+		ID:          itemID,
+		Type:        entities.ReviewQueueItemType(document.Type),
+		Text:        document.Text,
+		Status:      entities.ReviewQueueItemStatus(document.Status),
+		Occurrences: document.Occurrences,
+		Reason:      document.Reason,
+		StationName: document.StationName,
+		CreatedAt:   document.CreatedAt,
+		UpdatedAt:   document.UpdatedAt,
+	}
+
+	if document.RawRecordID != "" {
+		rawRecordID, err := id.FromString(document.RawRecordID)
+		if err != nil {
+			return item, errors.Wrap(err, "could not decode raw record id from string")
+		}
+		item.RawRecordID = &rawRecordID
+	}
+
+	if document.TransactionID != "" {
+		transactionID, err := id.FromString(document.TransactionID)
+		if err != nil {
+			return item, errors.Wrap(err, "could not decode transaction id from string")
+		}
+		item.TransactionID = &transactionID
+	}
+
+	for _, suggestion := range document.Suggestions {
+		item.Suggestions = append(item.Suggestions, entities.ReviewQueueSuggestion{
+			Code:       suggestion.Code,
+			Name:       suggestion.Name,
+			Confidence: suggestion.Confidence,
+		})
+	}
+
+	return item, nil
+}
@@ -0,0 +1,52 @@
+package mongodb
+
+import (
+	"strings"
+	"time"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/database"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// StationRepository is the mongodb repository for NS stations
+type StationRepository struct {
+	repository
+}
+
+// NewStationRepository creates a new instance of the station repository
+func NewStationRepository(db *mongo.Database, collection string) *StationRepository {
+	return &StationRepository{repository{db, collection}}
+}
+
+// AddAlias copies the station with the code and stores it with a different name.
+// Names and codes of stations are stored in lowercase.
+func (repository *StationRepository) AddAlias(name string, code string) error {
+	station := bson.M{}
+	err := repository.Collection().FindOne(
+		repository.DefaultTimeoutContext(),
+		bson.M{"code": strings.ToLower(code), "is_depreciated": false},
+	).Decode(&station)
+
+	if err == mongo.ErrNoDocuments {
+		return database.ErrEntityNotFound
+	}
+	if err != nil {
+		return errors.Wrap(err, "error fetching station from the database by code")
+	}
+
+	delete(station, "_id")
+	station["id"] = id.New().String()
+	station["name"] = strings.ToLower(name)
+	station["created_at"] = time.Now().UTC()
+	station["updated_at"] = time.Now().UTC()
+
+	_, err = repository.Collection().InsertOne(repository.DefaultTimeoutContext(), station)
+	if err != nil {
+		return errors.Wrapf(err, "cannot store alias '%s' for station '%s'", name, code)
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package mongodb
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// userDataOperationTimeout is longer than dbOperationTimeout because a user can have many imports
+	userDataOperationTimeout = time.Minute
+)
+
+// importCollections are the collections with documents which belong to an import
+var importCollections = []string{"raw_records", "ns_enriched_records", "calculation_results", "review_queue"}
+
+// UserDataRepository exports and deletes the data of a user across collections
+type UserDataRepository struct {
+	db                *mongo.Database
+	usersCollection   string
+	jobsCollection    string
+	importsCollection string
+}
+
+// NewUserDataRepository creates a new instance of the UserDataRepository
+func NewUserDataRepository(db *mongo.Database, usersCollection string, jobsCollection string, importsCollection string) *UserDataRepository {
+	return &UserDataRepository{db, usersCollection, jobsCollection, importsCollection}
+}
+
+// Export returns the documents of a user grouped by the collection they are stored in
+func (repository *UserDataRepository) Export(userID id.ID) (result map[string][]json.RawMessage, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), userDataOperationTimeout)
+	defer cancel()
+
+	importIDs, err := repository.importIDs(ctx, userID)
+	if err != nil {
+		return result, err
+	}
+
+	result = map[string][]json.RawMessage{}
+
+	// the password hash and the two-factor secrets are not useful to the user and should never leave the database
+	result[repository.usersCollection], err = repository.find(
+		ctx,
+		repository.usersCollection,
+		bson.M{"id": userID.String()},
+		options.Find().SetProjection(bson.M{"password": 0, "two_factor_secret": 0, "recovery_codes": 0}),
+	)
+	if err != nil {
+		return result, err
+	}
+
+	result[repository.jobsCollection], err = repository.find(ctx, repository.jobsCollection, bson.M{"user_id": userID.String()}, options.Find())
+	if err != nil {
+		return result, err
+	}
+
+	result[repository.importsCollection], err = repository.find(ctx, repository.importsCollection, bson.M{"user_id": userID.String()}, options.Find())
+	if err != nil {
+		return result, err
+	}
+
+	for _, collection := range importCollections {
+		result[collection], err = repository.find(ctx, collection, bson.M{"transaction_id": bson.M{"$in": importIDs}}, options.Find())
+		if err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// DeleteAll deletes the data of the imports of a user, the jobs of the user and the user.
+// The owners of the imports and the user are deleted last so a failed deletion can be retried.
+func (repository *UserDataRepository) DeleteAll(userID id.ID) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), userDataOperationTimeout)
+	defer cancel()
+
+	importIDs, err := repository.importIDs(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, collection := range importCollections {
+		_, err = repository.db.Collection(collection).DeleteMany(ctx, bson.M{"transaction_id": bson.M{"$in": importIDs}})
+		if err != nil {
+			return errors.Wrapf(err, "cannot delete the %s of user with ID: %s", collection, userID.String())
+		}
+	}
+
+	_, err = repository.db.Collection(repository.jobsCollection).DeleteMany(ctx, bson.M{"user_id": userID.String()})
+	if err != nil {
+		return errors.Wrapf(err, "cannot delete the jobs of user with ID: %s", userID.String())
+	}
+
+	_, err = repository.db.Collection(repository.importsCollection).DeleteMany(ctx, bson.M{"user_id": userID.String()})
+	if err != nil {
+		return errors.Wrapf(err, "cannot delete the imports of user with ID: %s", userID.String())
+	}
+
+	_, err = repository.db.Collection(repository.usersCollection).DeleteOne(ctx, bson.M{"id": userID.String()})
+	if err != nil {
+		return errors.Wrapf(err, "cannot delete user with ID: %s", userID.String())
+	}
+
+	return nil
+}
+
+// importIDs returns the transaction IDs of the imports which are owned by a user
+func (repository *UserDataRepository) importIDs(ctx context.Context, userID id.ID) (importIDs []interface{}, err error) {
+	importIDs, err = repository.db.Collection(repository.importsCollection).Distinct(ctx, "transaction_id", bson.M{"user_id": userID.String()})
+	if err != nil {
+		return importIDs, errors.Wrapf(err, "cannot fetch the imports of user with ID: %s", userID.String())
+	}
+
+	// $in needs an array so the result is never nil
+	if importIDs == nil {
+		importIDs = []interface{}{}
+	}
+	return importIDs, nil
+}
+
+func (repository *UserDataRepository) find(ctx context.Context, collection string, filter bson.M, findOptions *options.FindOptions) (documents []json.RawMessage, err error) {
+	cursor, err := repository.db.Collection(collection).Find(ctx, filter, findOptions.SetProjection(withoutObjectID(findOptions.Projection)))
+	if err != nil {
+		return documents, errors.Wrapf(err, "cannot fetch %s from the database", collection)
+	}
+	defer func() { _ = cursor.Close(ctx) }()
+
+	documents = []json.RawMessage{}
+	for cursor.Next(ctx) {
+		document, err := bson.MarshalExtJSON(cursor.Current, false, false)
+		if err != nil {
+			return documents, errors.Wrapf(err, "cannot encode a document in %s as JSON", collection)
+		}
+		documents = append(documents, document)
+	}
+
+	return documents, errors.Wrapf(cursor.Err(), "cannot fetch %s from the database", collection)
+}
+
+// withoutObjectID excludes the internal _id of the documents from a projection
+func withoutObjectID(projection interface{}) bson.M {
+	result := bson.M{"_id": 0}
+	if fields, ok := projection.(bson.M); ok {
+		for field, value := range fields {
+			result[field] = value
+		}
+	}
+	return result
+}
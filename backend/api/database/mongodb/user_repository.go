@@ -2,6 +2,7 @@ package mongodb
 
 import (
 	"context"
+	"time"
 
 	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/database"
 	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/entities"
@@ -10,6 +11,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // UserRepository creates a new instance of the user repository
@@ -22,20 +24,84 @@ func NewUserRepository(db *mongo.Database, collection string) *UserRepository {
 	return &UserRepository{repository{db, collection}}
 }
 
+// emailCollation compares email addresses case insensitively so users which were stored before the email addresses
+// were normalized are unique and found as well
+var emailCollation = &options.Collation{Locale: "en", Strength: 2}
+
+// CreateIndexes creates the indexes of the users. The email address is unique so two requests cannot create users with
+// the same email address.
+func (repository *UserRepository) CreateIndexes(ctx context.Context) error {
+	_, err := repository.Collection().Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "email", Value: 1}},
+		Options: options.Index().SetName("email").SetUnique(true).SetCollation(emailCollation),
+	})
+	return errors.Wrap(err, "cannot create the indexes of the users")
+}
+
 // Store stores a user on the mongodb repository
 func (repository *UserRepository) Store(user entities.User) error {
-	_, err := repository.Collection().InsertOne(context.Background(), bson.M{
-		"id":         user.ID.String(),
-		"first_name": user.FirstName,
-		"last_name":  user.LastName,
-		"email":      user.Email,
-		"password":   user.Password,
-		"created_at": user.CreatedAt,
-		"updated_at": user.UpdatedAt,
-	})
+	_, err := repository.Collection().InsertOne(context.Background(), repository.documentFromUser(user))
+	if isDuplicateKeyError(err) {
+		return database.ErrEmailTaken
+	}
 	return err
 }
 
+// Update saves the changes to an existing user
+func (repository *UserRepository) Update(user entities.User) error {
+	result, err := repository.Collection().UpdateOne(
+		repository.DefaultTimeoutContext(),
+		bson.M{"id": user.ID.String()},
+		bson.M{"$set": repository.documentFromUser(user)},
+	)
+	if isDuplicateKeyError(err) {
+		return database.ErrEmailTaken
+	}
+	if err != nil {
+		return errors.Wrapf(err, "cannot update user with ID: %s", user.ID.String())
+	}
+
+	if result.MatchedCount == 0 {
+		return database.ErrEntityNotFound
+	}
+
+	return nil
+}
+
+// RemoveRecoveryCode removes the hash of a recovery code from a user. The hash is only removed when the user still has
+// it so a code which is used by two requests at the same time is only accepted once.
+func (repository *UserRepository) RemoveRecoveryCode(userID id.ID, hash string) (bool, error) {
+	result, err := repository.Collection().UpdateOne(
+		repository.DefaultTimeoutContext(),
+		bson.M{"id": userID.String(), "recovery_codes": hash},
+		bson.M{
+			"$pull": bson.M{"recovery_codes": hash},
+			"$set":  bson.M{"updated_at": time.Now()},
+		},
+	)
+	if err != nil {
+		return false, errors.Wrapf(err, "cannot remove a recovery code of user with ID: %s", userID.String())
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+func (repository *UserRepository) documentFromUser(user entities.User) bson.M {
+	return bson.M{
+		"id":                user.ID.String(),
+		"first_name":        user.FirstName,
+		"last_name":         user.LastName,
+		"email":             user.Email,
+		"password":          user.Password,
+		"is_admin":          user.IsAdmin,
+		"email_verified_at": user.EmailVerifiedAt,
+		"two_factor_secret": user.TwoFactorSecret,
+		"recovery_codes":    user.RecoveryCodes,
+		"created_at":        user.CreatedAt,
+		"updated_at":        user.UpdatedAt,
+	}
+}
+
 // FindByID finds a user in the database using it's ID
 func (repository *UserRepository) FindByID(ID id.ID) (user *entities.User, err error) {
 	dbRecord := map[string]interface{}{}
@@ -54,7 +120,9 @@ func (repository *UserRepository) FindByID(ID id.ID) (user *entities.User, err e
 // FindByEmail searches a user using the email
 func (repository *UserRepository) FindByEmail(email string) (user *entities.User, err error) {
 	dbRecord := map[string]interface{}{}
-	err = repository.Collection().FindOne(repository.DefaultTimeoutContext(), bson.M{"email": email}).Decode(&dbRecord)
+	err = repository.Collection().
+		FindOne(repository.DefaultTimeoutContext(), bson.M{"email": entities.NormalizeEmail(email)}, options.FindOne().SetCollation(emailCollation)).
+		Decode(&dbRecord)
 
 	if err == mongo.ErrNoDocuments {
 		return user, database.ErrEntityNotFound
@@ -72,13 +140,35 @@ func (repository *UserRepository) hydrateUserFromDBRecord(dbRecord map[string]in
 		return user, errors.Wrap(err, "could not decode user id form string")
 	}
 
+	// users which were created before admins existed don't have the is_admin field
+	isAdmin, _ := dbRecord["is_admin"].(bool)
+
+	var emailVerifiedAt *time.Time
+	if value, ok := dbRecord["email_verified_at"].(primitive.DateTime); ok {
+		verifiedAt := value.Time()
+		emailVerifiedAt = &verifiedAt
+	}
+
+	// users which were created before two-factor authentication existed don't have the two-factor fields
+	twoFactorSecret, _ := dbRecord["two_factor_secret"].(string)
+	var recoveryCodes []string
+	if values, ok := dbRecord["recovery_codes"].(primitive.A); ok {
+		for _, value := range values {
+			recoveryCodes = append(recoveryCodes, value.(string))
+		}
+	}
+
 	return &entities.User{
-		ID:        userID,
-		FirstName: dbRecord["first_name"].(string),
-		LastName:  dbRecord["last_name"].(string),
-		Email:     dbRecord["email"].(string),
-		Password:  dbRecord["password"].(string),
-		CreatedAt: dbRecord["created_at"].(primitive.DateTime).Time(),
-		UpdatedAt: dbRecord["updated_at"].(primitive.DateTime).Time(),
+		ID:              userID,
+		FirstName:       dbRecord["first_name"].(string),
+		LastName:        dbRecord["last_name"].(string),
+		Email:           dbRecord["email"].(string),
+		Password:        dbRecord["password"].(string),
+		IsAdmin:         isAdmin,
+		EmailVerifiedAt: emailVerifiedAt,
+		TwoFactorSecret: twoFactorSecret,
+		RecoveryCodes:   recoveryCodes,
+		CreatedAt:       dbRecord["created_at"].(primitive.DateTime).Time(),
+		UpdatedAt:       dbRecord["updated_at"].(primitive.DateTime).Time(),
 	}, err
 }
@@ -0,0 +1,9 @@
+package database
+
+import "github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+
+// RawRecordRepository is the repository for the raw ov-chipkaart transactions
+type RawRecordRepository interface {
+	// Ignore excludes a raw record from the enrichment process
+	Ignore(rawRecordID id.ID) error
+}
@@ -0,0 +1,14 @@
+package database
+
+import (
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/entities"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+)
+
+// ReviewQueueRepository is the repository for items which need to be reviewed by an admin
+type ReviewQueueRepository interface {
+	FindByStatus(status entities.ReviewQueueItemStatus) ([]entities.ReviewQueueItem, error)
+	FindByID(itemID id.ID) (*entities.ReviewQueueItem, error)
+	FindOpenForStationName(stationName string) ([]entities.ReviewQueueItem, error)
+	UpdateStatus(itemIDs []id.ID, status entities.ReviewQueueItemStatus) error
+}
@@ -0,0 +1,7 @@
+package database
+
+// StationRepository is the repository for NS stations
+type StationRepository interface {
+	// AddAlias stores an extra name for the station with the given code
+	AddAlias(name string, code string) error
+}
@@ -0,0 +1,16 @@
+package database
+
+import (
+	"encoding/json"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+)
+
+// UserDataRepository exports and deletes everything which is stored about a user including the data of the imports
+// which the user started
+type UserDataRepository interface {
+	// Export returns the documents of a user grouped by the collection they are stored in
+	Export(userID id.ID) (map[string][]json.RawMessage, error)
+	// DeleteAll deletes the data of the imports of a user, the jobs of the user and the user
+	DeleteAll(userID id.ID) error
+}
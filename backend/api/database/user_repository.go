@@ -7,7 +7,13 @@ import (
 
 // UserRepository is an instance of the user repository
 type UserRepository interface {
+	// Store saves a new user, it returns ErrEmailTaken when another user has the same email address
 	Store(user entities.User) error
+	// Update saves the changes to an existing user, it returns ErrEmailTaken when another user has the same email address
+	Update(user entities.User) error
+	// RemoveRecoveryCode removes the hash of a recovery code from a user. It returns false when the user does not have
+	// the hash e.g because another request used the same code first.
+	RemoveRecoveryCode(userID id.ID, hash string) (bool, error)
 	FindByID(userID id.ID) (*entities.User, error)
 	FindByEmail(email string) (*entities.User, error)
 }
@@ -0,0 +1,25 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+)
+
+// CalculationResult is the price of the journeys of an import with a discount product
+type CalculationResult struct {
+	ID                id.ID
+	RunID             id.ID
+	TransactionID     id.ID
+	Product           string
+	CompanyName       string
+	RuleSetVersion    string
+	FareTableVersion  string
+	CalculatedAt      time.Time
+	Currency          string
+	JourneyCount      int
+	JourneysPrice     int
+	SubscriptionPrice int
+	TotalPrice        int
+	IsCheapest        bool
+}
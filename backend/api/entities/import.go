@@ -0,0 +1,14 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+)
+
+// Import is a batch of transactions of an ov-chipkaart which is owned by a user
+type Import struct {
+	TransactionID id.ID
+	UserID        id.ID
+	CreatedAt     time.Time
+}
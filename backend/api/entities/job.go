@@ -0,0 +1,88 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+)
+
+// JobType is the kind of work a job does
+type JobType string
+
+const (
+	// JobTypeImport fetches the transactions of an ov-chipkaart from the ov-chipkaart API
+	JobTypeImport = JobType("Import")
+
+	// JobTypeReEnrichment enriches the raw records of an import again
+	JobTypeReEnrichment = JobType("ReEnrichment")
+
+	// JobTypeRecalculation calculates the prices of the discount products for an import again
+	JobTypeRecalculation = JobType("Recalculation")
+)
+
+// JobStatus is the state of a job
+type JobStatus string
+
+const (
+	// JobStatusPending is a job which has not been started
+	JobStatusPending = JobStatus("pending")
+
+	// JobStatusRunning is a job which is being processed
+	JobStatusRunning = JobStatus("running")
+
+	// JobStatusDone is a job which finished successfully
+	JobStatusDone = JobStatus("done")
+
+	// JobStatusFailed is a job which finished with an error
+	JobStatusFailed = JobStatus("failed")
+)
+
+// JobMaxAttempts is the number of times a job is tried before it fails
+const JobMaxAttempts = 5
+
+// Job is work which is done in the background for an import
+type Job struct {
+	ID             id.ID
+	Type           JobType
+	Status         JobStatus
+	TransactionID  id.ID
+	UserID         *id.ID
+	IdempotencyKey string
+	Reason         string
+	Import         *ImportJobOptions
+	ProcessedItems int
+	TotalItems     int
+	Attempts       int
+	MaxAttempts    int
+	RunAfter       time.Time
+	Error          string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// ImportJobOptions are the transactions which are fetched by an import job
+type ImportJobOptions struct {
+	CardNumber string
+	StartDate  time.Time
+	EndDate    time.Time
+}
+
+// ImportCredentials are the credentials of the ov-chipkaart account of an import job.
+// They are kept in the cache until the job is done and are never stored in the database.
+type ImportCredentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// Progress returns the percentage of the items which have been processed
+func (job Job) Progress() float64 {
+	if job.Status == JobStatusDone {
+		return 100
+	}
+
+	if job.TotalItems == 0 {
+		return 0
+	}
+
+	return float64(job.ProcessedItems) * 100 / float64(job.TotalItems)
+}
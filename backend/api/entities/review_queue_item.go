@@ -0,0 +1,55 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+)
+
+// ReviewQueueItemType is the reason why an item needs to be reviewed
+type ReviewQueueItemType string
+
+const (
+	// ReviewQueueItemTypeUnresolvedStationName is a station name which could not be mapped to a station
+	ReviewQueueItemTypeUnresolvedStationName = ReviewQueueItemType("UnresolvedStationName")
+
+	// ReviewQueueItemTypeFailedEnrichment is a raw record which could not be enriched
+	ReviewQueueItemTypeFailedEnrichment = ReviewQueueItemType("FailedEnrichment")
+)
+
+// ReviewQueueItemStatus is the state of a review queue item
+type ReviewQueueItemStatus string
+
+const (
+	// ReviewQueueItemStatusOpen is an item which has not been reviewed
+	ReviewQueueItemStatusOpen = ReviewQueueItemStatus("open")
+
+	// ReviewQueueItemStatusResolved is an item which has been resolved by an admin
+	ReviewQueueItemStatusResolved = ReviewQueueItemStatus("resolved")
+
+	// ReviewQueueItemStatusIgnored is an item which an admin decided to ignore
+	ReviewQueueItemStatusIgnored = ReviewQueueItemStatus("ignored")
+)
+
+// ReviewQueueSuggestion is a possible station for an unresolved station name
+type ReviewQueueSuggestion struct {
+	Code       string
+	Name       string
+	Confidence float64
+}
+
+// ReviewQueueItem is something which could not be processed automatically and has to be reviewed by an admin
+type ReviewQueueItem struct {
+	ID            id.ID
+	Type          ReviewQueueItemType
+	Text          string
+	Status        ReviewQueueItemStatus
+	Occurrences   int
+	Reason        string
+	StationName   string
+	RawRecordID   *id.ID
+	TransactionID *id.ID
+	Suggestions   []ReviewQueueSuggestion
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
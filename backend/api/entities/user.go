@@ -1,6 +1,7 @@
 package entities
 
 import (
+	"strings"
 	"time"
 
 	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
@@ -13,6 +14,24 @@ type User struct {
 	LastName  string
 	Email     string
 	Password  string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	IsAdmin   bool
+	// EmailVerifiedAt is nil until the user opens the link in the verification email
+	EmailVerifiedAt *time.Time
+	// TwoFactorSecret is the TOTP secret of the authenticator app, it is empty when two-factor authentication is disabled
+	TwoFactorSecret string
+	// RecoveryCodes are the SHA-256 hashes of the recovery codes which have not been used
+	RecoveryCodes []string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// TwoFactorEnabled checks if the user needs a code from an authenticator app to log in
+func (user User) TwoFactorEnabled() bool {
+	return user.TwoFactorSecret != ""
+}
+
+// NormalizeEmail trims and lowercases an email address so an address is stored and searched the same way however the
+// user typed it
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
 }
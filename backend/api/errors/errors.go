@@ -1,11 +1,117 @@
 package errors
 
-import "github.com/pkg/errors"
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// The codes are added to the extensions of the GraphQL errors so the frontend can branch on the code instead of the message
+const (
+	// CodeUnauthenticated is the GraphQL error code when the user is not logged in
+	CodeUnauthenticated = "UNAUTHENTICATED"
+
+	// CodeForbidden is the GraphQL error code when the user is logged in but not allowed to perform an action
+	CodeForbidden = "FORBIDDEN"
+
+	// CodeValidation is the GraphQL error code when the input is invalid
+	CodeValidation = "VALIDATION"
+
+	// CodeNotFound is the GraphQL error code when the requested entity does not exist
+	CodeNotFound = "NOT_FOUND"
+
+	// CodeRateLimited is the GraphQL error code when a client sent too many requests
+	CodeRateLimited = "RATE_LIMITED"
+
+	// CodeAccountLocked is the GraphQL error code when an account is locked after too many failed logins
+	CodeAccountLocked = "ACCOUNT_LOCKED"
+
+	// CodeInternal is the GraphQL error code when the server failed, the details are only sent to the error handler
+	CodeInternal = "INTERNAL"
+)
 
 var (
 	// ErrInternalServerError is thrown when there's a server error
-	ErrInternalServerError = errors.New("internal server error")
+	ErrInternalServerError = New(CodeInternal, "internal server error")
 
 	// ErrValidationError the input is invalid
-	ErrValidationError = errors.New("input validation errors")
+	ErrValidationError = New(CodeValidation, "input validation errors")
+
+	// ErrUnauthenticated is thrown when the user is not logged in
+	ErrUnauthenticated = New(CodeUnauthenticated, "you must be logged in to perform this action")
+
+	// ErrForbidden is thrown when the user is not allowed to perform an action
+	ErrForbidden = New(CodeForbidden, "you are not allowed to perform this action")
+
+	// ErrNotFound is thrown when the requested entity does not exist
+	ErrNotFound = New(CodeNotFound, "the requested item does not exist")
+
+	// ErrEmailNotVerified is thrown when a user who has not verified the email address performs an action which needs it
+	ErrEmailNotVerified = New(CodeForbidden, "you must verify your email address to perform this action")
+
+	// ErrTwoFactorEnabled is thrown when a user enrols in two-factor authentication a second time
+	ErrTwoFactorEnabled = New(CodeValidation, "two-factor authentication is already enabled")
+
+	// ErrTwoFactorDisabled is thrown when a user disables two-factor authentication which is not enabled
+	ErrTwoFactorDisabled = New(CodeValidation, "two-factor authentication is not enabled")
 )
+
+// Error is an error with a message which can be shown to the user
+type Error struct {
+	Code    string
+	Message string
+}
+
+// New creates an error with a code from the catalogue
+func New(code string, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Error returns the message of the error
+func (err *Error) Error() string {
+	return err.Message
+}
+
+// Extensions adds the error code to the GraphQL error
+func (err *Error) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code": err.Code,
+	}
+}
+
+// RateLimitError is thrown when a request is not allowed until RetryAfter has passed
+type RateLimitError struct {
+	Code       string
+	RetryAfter time.Duration
+}
+
+// NewRateLimitError creates a RateLimitError for a client which sent too many requests
+func NewRateLimitError(retryAfter time.Duration) RateLimitError {
+	return RateLimitError{Code: CodeRateLimited, RetryAfter: retryAfter}
+}
+
+// NewAccountLockedError creates a RateLimitError for an account which is locked
+func NewAccountLockedError(retryAfter time.Duration) RateLimitError {
+	return RateLimitError{Code: CodeAccountLocked, RetryAfter: retryAfter}
+}
+
+// RetryAfterSeconds rounds the time until the request is allowed up to whole seconds
+func (err RateLimitError) RetryAfterSeconds() int {
+	return int(math.Ceil(err.RetryAfter.Seconds()))
+}
+
+// Error returns the message of the error
+func (err RateLimitError) Error() string {
+	if err.Code == CodeAccountLocked {
+		return fmt.Sprintf("this account is locked because of too many failed login attempts, try again in %d seconds", err.RetryAfterSeconds())
+	}
+	return fmt.Sprintf("too many requests, try again in %d seconds", err.RetryAfterSeconds())
+}
+
+// Extensions adds the error code and the number of seconds to wait to the GraphQL error
+func (err RateLimitError) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":       err.Code,
+		"retryAfter": err.RetryAfterSeconds(),
+	}
+}
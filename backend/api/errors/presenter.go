@@ -0,0 +1,63 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/errorhandler"
+	"github.com/pkg/errors"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// codedError is an error which adds a code to the extensions of the GraphQL error e.g Error and RateLimitError
+type codedError interface {
+	error
+	Extensions() map[string]interface{}
+}
+
+// NewPresenter creates the gqlgen error presenter. The errors of the catalogue are shown with their code, the other
+// errors are unexpected so they are reported to the error handler and the user only sees an internal server error.
+func NewPresenter(errorHandler errorhandler.ErrorHandler) graphql.ErrorPresenterFunc {
+	return func(ctx context.Context, err error) *gqlerror.Error {
+		// e.g the field errors which are added by the validator
+		if gqlErr, ok := err.(*gqlerror.Error); ok && gqlErr.Extensions["code"] != nil {
+			return graphql.DefaultErrorPresenter(ctx, gqlErr)
+		}
+
+		var coded codedError
+		if errors.As(err, &coded) {
+			return graphql.DefaultErrorPresenter(ctx, coded)
+		}
+
+		errorHandler.CaptureError(ctx, errors.Wrapf(err, "unexpected error in %s", describeRequest(ctx)))
+		return graphql.DefaultErrorPresenter(ctx, ErrInternalServerError)
+	}
+}
+
+// NewRecoverFunc creates the gqlgen recover func which reports a panic in a resolver to the error handler
+func NewRecoverFunc(errorHandler errorhandler.ErrorHandler) graphql.RecoverFunc {
+	return func(ctx context.Context, panicValue interface{}) error {
+		err, ok := panicValue.(error)
+		if !ok {
+			err = fmt.Errorf("%v", panicValue)
+		}
+
+		errorHandler.CaptureError(ctx, errors.Wrapf(err, "panic in %s", describeRequest(ctx)))
+		return ErrInternalServerError
+	}
+}
+
+// describeRequest returns the operation and the path of the field which is resolved e.g "operation Login at login.token"
+func describeRequest(ctx context.Context) string {
+	operationName := "anonymous operation"
+	if graphql.HasOperationContext(ctx) && graphql.GetOperationContext(ctx).OperationName != "" {
+		operationName = "operation " + graphql.GetOperationContext(ctx).OperationName
+	}
+
+	if fieldContext := graphql.GetFieldContext(ctx); fieldContext != nil {
+		return operationName + " at " + fieldContext.Path().String()
+	}
+
+	return operationName
+}
@@ -0,0 +1,136 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/pkg/errors"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// capturingErrorHandler keeps the errors which are reported
+type capturingErrorHandler struct {
+	errors []error
+}
+
+func (handler *capturingErrorHandler) CaptureError(_ context.Context, err error) {
+	handler.errors = append(handler.errors, err)
+}
+
+// loginContext is the context of the "login" field of the Login operation
+func loginContext() context.Context {
+	ctx := graphql.WithOperationContext(context.Background(), &graphql.OperationContext{OperationName: "Login"})
+	return graphql.WithFieldContext(ctx, &graphql.FieldContext{Field: graphql.CollectedField{Field: &ast.Field{Alias: "login"}}})
+}
+
+func TestNewPresenter(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		message    string
+		code       string
+		retryAfter interface{}
+		captured   string
+	}{
+		{
+			name:    "catalogue error",
+			err:     ErrNotFound,
+			message: ErrNotFound.Message,
+			code:    CodeNotFound,
+		},
+		{
+			name:    "wrapped catalogue error",
+			err:     errors.Wrap(ErrForbidden, "cannot delete the import"),
+			message: ErrForbidden.Message,
+			code:    CodeForbidden,
+		},
+		{
+			name:       "rate limit error",
+			err:        NewAccountLockedError(1500 * time.Millisecond),
+			message:    "this account is locked because of too many failed login attempts, try again in 2 seconds",
+			code:       CodeAccountLocked,
+			retryAfter: 2,
+		},
+		{
+			name:    "field error of the validator",
+			err:     &gqlerror.Error{Message: "the email address is required", Extensions: map[string]interface{}{"code": CodeValidation}},
+			message: "the email address is required",
+			code:    CodeValidation,
+		},
+		{
+			name:     "unexpected error",
+			err:      fmt.Errorf("connection refused"),
+			message:  ErrInternalServerError.Message,
+			code:     CodeInternal,
+			captured: "unexpected error in operation Login at login: connection refused",
+		},
+		{
+			name:     "graphql error without a code",
+			err:      gqlerror.Errorf("database timeout"),
+			message:  ErrInternalServerError.Message,
+			code:     CodeInternal,
+			captured: "unexpected error in operation Login at login: input: database timeout",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			errorHandler := &capturingErrorHandler{}
+			result := NewPresenter(errorHandler)(loginContext(), test.err)
+
+			if result.Message != test.message {
+				t.Errorf("message = %s, want %s", result.Message, test.message)
+			}
+			if result.Extensions["code"] != test.code {
+				t.Errorf("code = %v, want %s", result.Extensions["code"], test.code)
+			}
+			if result.Extensions["retryAfter"] != test.retryAfter {
+				t.Errorf("retryAfter = %v, want %v", result.Extensions["retryAfter"], test.retryAfter)
+			}
+			if result.Path.String() != "login" {
+				t.Errorf("path = %s, want login", result.Path.String())
+			}
+
+			if test.captured == "" {
+				if len(errorHandler.errors) != 0 {
+					t.Errorf("the expected error %v was reported", errorHandler.errors[0])
+				}
+				return
+			}
+			if len(errorHandler.errors) != 1 || errorHandler.errors[0].Error() != test.captured {
+				t.Errorf("reported errors = %v, want %s", errorHandler.errors, test.captured)
+			}
+		})
+	}
+}
+
+func TestNewRecoverFunc(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		value    interface{}
+		captured string
+	}{
+		{name: "panic with an error", ctx: loginContext(), value: errors.New("nil map"), captured: "panic in operation Login at login: nil map"},
+		{name: "panic with a string", ctx: loginContext(), value: "index out of range", captured: "panic in operation Login at login: index out of range"},
+		{name: "panic outside an operation", ctx: context.Background(), value: "boom", captured: "panic in anonymous operation: boom"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			errorHandler := &capturingErrorHandler{}
+			err := NewRecoverFunc(errorHandler)(test.ctx, test.value)
+
+			if err != ErrInternalServerError {
+				t.Errorf("error = %v, want %v", err, ErrInternalServerError)
+			}
+			if len(errorHandler.errors) != 1 || errorHandler.errors[0].Error() != test.captured {
+				t.Errorf("reported errors = %v, want %s", errorHandler.errors, test.captured)
+			}
+		})
+	}
+}
@@ -3,14 +3,41 @@
 package model
 
 type AuthOutput struct {
-	User  *User  `json:"user"`
-	Token *Token `json:"token"`
+	User               *User               `json:"user"`
+	Token              *Token              `json:"token"`
+	TwoFactorChallenge *TwoFactorChallenge `json:"twoFactorChallenge"`
+}
+
+type CalculationResult struct {
+	ID                string `json:"id"`
+	Product           string `json:"product"`
+	CompanyName       string `json:"companyName"`
+	RuleSetVersion    string `json:"ruleSetVersion"`
+	FareTableVersion  string `json:"fareTableVersion"`
+	Currency          string `json:"currency"`
+	JourneyCount      int    `json:"journeyCount"`
+	JourneysPrice     int    `json:"journeysPrice"`
+	SubscriptionPrice int    `json:"subscriptionPrice"`
+	TotalPrice        int    `json:"totalPrice"`
+	IsCheapest        bool   `json:"isCheapest"`
+}
+
+type CalculationRun struct {
+	ID           string               `json:"id"`
+	ImportID     string               `json:"importId"`
+	CalculatedAt string               `json:"calculatedAt"`
+	Results      []*CalculationResult `json:"results"`
 }
 
 type CancelTokenInput struct {
 	Token string `json:"token"`
 }
 
+type ChangePasswordInput struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
+
 type CreateUserInput struct {
 	FirstName string `json:"firstName"`
 	LastName  string `json:"lastName"`
@@ -19,6 +46,49 @@ type CreateUserInput struct {
 	ReCaptcha string `json:"reCaptcha"`
 }
 
+type DataExport struct {
+	URL       string `json:"url"`
+	ExpiresAt string `json:"expiresAt"`
+}
+
+type DeleteAccountInput struct {
+	Password string `json:"password"`
+}
+
+type DisableTwoFactorInput struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+type IgnoreReviewQueueItemInput struct {
+	ID string `json:"id"`
+}
+
+type ImportCardInput struct {
+	CardNumber     string  `json:"cardNumber"`
+	Username       string  `json:"username"`
+	Password       string  `json:"password"`
+	StartDate      string  `json:"startDate"`
+	EndDate        *string `json:"endDate"`
+	IdempotencyKey *string `json:"idempotencyKey"`
+}
+
+type Job struct {
+	ID             string  `json:"id"`
+	Type           string  `json:"type"`
+	Status         string  `json:"status"`
+	ImportID       string  `json:"importId"`
+	Reason         string  `json:"reason"`
+	ProcessedItems int     `json:"processedItems"`
+	TotalItems     int     `json:"totalItems"`
+	Progress       float64 `json:"progress"`
+	Attempts       int     `json:"attempts"`
+	MaxAttempts    int     `json:"maxAttempts"`
+	Error          *string `json:"error"`
+	CreatedAt      string  `json:"createdAt"`
+	UpdatedAt      string  `json:"updatedAt"`
+}
+
 type LoginInput struct {
 	Email      string `json:"email"`
 	Password   string `json:"password"`
@@ -26,19 +96,88 @@ type LoginInput struct {
 	ReCaptcha  string `json:"reCaptcha"`
 }
 
+type MapStationNameInput struct {
+	StationName string `json:"stationName"`
+	StationCode string `json:"stationCode"`
+}
+
 type RefreshTokenInput struct {
 	Token string `json:"token"`
 }
 
+type RequestPasswordResetInput struct {
+	Email     string `json:"email"`
+	ReCaptcha string `json:"reCaptcha"`
+}
+
+type ResetPasswordInput struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type ReviewQueueItem struct {
+	ID          string                   `json:"id"`
+	Type        string                   `json:"type"`
+	Text        string                   `json:"text"`
+	Status      string                   `json:"status"`
+	Occurrences int                      `json:"occurrences"`
+	Reason      *string                  `json:"reason"`
+	StationName *string                  `json:"stationName"`
+	RawRecordID *string                  `json:"rawRecordId"`
+	ImportID    *string                  `json:"importId"`
+	Suggestions []*ReviewQueueSuggestion `json:"suggestions"`
+	CreatedAt   string                   `json:"createdAt"`
+	UpdatedAt   string                   `json:"updatedAt"`
+}
+
+type ReviewQueueSuggestion struct {
+	Code       string  `json:"code"`
+	Name       string  `json:"name"`
+	Confidence float64 `json:"confidence"`
+}
+
 type Token struct {
-	Value string `json:"value"`
+	Value        string `json:"value"`
+	ExpiresAt    string `json:"expiresAt"`
+	RefreshToken string `json:"refreshToken"`
 }
 
-type User struct {
-	ID        string `json:"id"`
+type TwoFactorChallenge struct {
+	Token     string `json:"token"`
+	ExpiresAt string `json:"expiresAt"`
+}
+
+type TwoFactorEnrolment struct {
+	Secret string `json:"secret"`
+	URL    string `json:"url"`
+}
+
+type UpdateProfileInput struct {
 	FirstName string `json:"firstName"`
 	LastName  string `json:"lastName"`
 	Email     string `json:"email"`
-	CreatedAt string `json:"createdAt"`
-	UpdatedAt string `json:"updatedAt"`
+}
+
+type User struct {
+	ID               string  `json:"id"`
+	FirstName        string  `json:"firstName"`
+	LastName         string  `json:"lastName"`
+	Email            string  `json:"email"`
+	EmailVerifiedAt  *string `json:"emailVerifiedAt"`
+	TwoFactorEnabled bool    `json:"twoFactorEnabled"`
+	CreatedAt        string  `json:"createdAt"`
+	UpdatedAt        string  `json:"updatedAt"`
+}
+
+type VerifyEmailInput struct {
+	Token string `json:"token"`
+}
+
+type VerifyTwoFactorInput struct {
+	Code string `json:"code"`
+}
+
+type VerifyTwoFactorLoginInput struct {
+	ChallengeToken string `json:"challengeToken"`
+	Code           string `json:"code"`
 }
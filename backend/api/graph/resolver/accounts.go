@@ -0,0 +1,110 @@
+package resolver
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/database"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/entities"
+	internalErrors "github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/errors"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/graph/validator"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/services/mailer"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/services/verification"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+	pkgErrors "github.com/pkg/errors"
+)
+
+const (
+	emailVerificationExpiration = 48 * time.Hour
+	passwordResetExpiration     = time.Hour
+
+	// DataExportPath is the path where the archives which are created by exportMyData are downloaded
+	DataExportPath = "/exports/"
+)
+
+// sendVerificationEmail sends the link which verifies the email address of a user
+func (r *Resolver) sendVerificationEmail(user entities.User) error {
+	token, err := r.verificationService.CreateForEmail(verification.PurposeEmailVerification, user.ID, user.Email, emailVerificationExpiration)
+	if err != nil {
+		return err
+	}
+
+	return r.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: "Hi " + user.FirstName + ",\n\n" +
+			"Open the link below to verify your email address. The link expires in 48 hours.\n\n" +
+			r.frontendLink("/verify-email", token) + "\n",
+	})
+}
+
+// sendPasswordResetEmail sends the link which lets a user choose a new password
+func (r *Resolver) sendPasswordResetEmail(user entities.User) error {
+	token, err := r.verificationService.CreateForEmail(verification.PurposePasswordReset, user.ID, user.Email, passwordResetExpiration)
+	if err != nil {
+		return err
+	}
+
+	return r.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: "Hi " + user.FirstName + ",\n\n" +
+			"Open the link below to choose a new password. The link expires in 1 hour.\n" +
+			"You can ignore this email if you did not request a password reset.\n\n" +
+			r.frontendLink("/reset-password", token) + "\n",
+	})
+}
+
+func (r *Resolver) frontendLink(path string, token string) string {
+	return r.frontendURL + path + "?token=" + url.QueryEscape(token)
+}
+
+// deleteImportCredentials deletes the ov-chipkaart credentials of the jobs which have not run yet.
+// The credentials of the other jobs have already been deleted by the worker.
+func (r *Resolver) deleteImportCredentials(userID id.ID) error {
+	for _, status := range []entities.JobStatus{entities.JobStatusPending, entities.JobStatusRunning} {
+		jobs, err := r.db.JobRepository().FindByUserIDAndStatus(userID, status)
+		if err != nil {
+			return err
+		}
+
+		for _, job := range jobs {
+			err = r.cache.Delete(keyPrefixImportCredentials + job.ID.String())
+			if err != nil {
+				return pkgErrors.Wrapf(err, "cannot delete the credentials of job with ID: %s", job.ID.String())
+			}
+		}
+	}
+
+	return nil
+}
+
+// consumeVerificationToken returns the user of a single use token
+func (r *Resolver) consumeVerificationToken(ctx context.Context, purpose verification.Purpose, token string) (*entities.User, error) {
+	userID, email, err := r.verificationService.ConsumeForEmail(purpose, token)
+	if err == verification.ErrInvalidToken {
+		return nil, validator.NewValidationResult(ctx, []validator.FieldError{{Field: "token", Message: err.Error()}}).Error
+	}
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot use %s token", purpose))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	user, err := r.db.UserRepository().FindByID(userID)
+	if err == database.ErrEntityNotFound {
+		return nil, validator.NewValidationResult(ctx, []validator.FieldError{{Field: "token", Message: verification.ErrInvalidToken.Error()}}).Error
+	}
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot find user with ID: %s", userID.String()))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	// the email was sent to an address which the user has changed since
+	if !strings.EqualFold(email, user.Email) {
+		return nil, validator.NewValidationResult(ctx, []validator.FieldError{{Field: "token", Message: verification.ErrInvalidToken.Error()}}).Error
+	}
+
+	return user, nil
+}
@@ -0,0 +1,277 @@
+package resolver
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"time"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/database"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/entities"
+	internalErrors "github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/errors"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/graph/model"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/graph/validator"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/services/verification"
+	internalTime "github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/time"
+	pkgErrors "github.com/pkg/errors"
+)
+
+func (r *mutationResolver) VerifyEmail(ctx context.Context, input model.VerifyEmailInput) (*model.User, error) {
+	user, err := r.consumeVerificationToken(ctx, verification.PurposeEmailVerification, input.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return userToModel(*user), nil
+	}
+
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	user.UpdatedAt = now
+
+	err = r.db.UserRepository().Update(*user)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot verify email of user with ID: %s", user.ID.String()))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	return userToModel(*user), nil
+}
+
+func (r *mutationResolver) RequestEmailVerification(ctx context.Context) (bool, error) {
+	user, err := r.authorizeUser(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return true, nil
+	}
+
+	err = r.sendVerificationEmail(*user)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot send verification email to user with ID: %s", user.ID.String()))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	return true, nil
+}
+
+func (r *mutationResolver) RequestPasswordReset(ctx context.Context, input model.RequestPasswordResetInput) (bool, error) {
+	err := r.limitByClientIP(ctx, "password-reset", passwordResetLimit, passwordResetLimitWindow)
+	if err != nil {
+		return false, err
+	}
+
+	input.Email = entities.NormalizeEmail(input.Email)
+	validationResult := r.validator.ValidateRequestPasswordResetInput(ctx, input)
+	if validationResult.HasError {
+		return false, validationResult.Error
+	}
+
+	user, err := r.db.UserRepository().FindByEmail(input.Email)
+	if err == database.ErrEntityNotFound {
+		return true, nil
+	}
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrap(err, "cannot find user by email"))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	err = r.sendPasswordResetEmail(*user)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot send password reset email to user with ID: %s", user.ID.String()))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	return true, nil
+}
+
+func (r *mutationResolver) ResetPassword(ctx context.Context, input model.ResetPasswordInput) (bool, error) {
+	validationResult := r.validator.ValidateResetPasswordInput(ctx, input)
+	if validationResult.HasError {
+		return false, validationResult.Error
+	}
+
+	user, err := r.consumeVerificationToken(ctx, verification.PurposePasswordReset, input.Token)
+	if err != nil {
+		return false, err
+	}
+
+	hashedPassword, err := r.passwordService.HashPassword(input.Password)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrap(err, "could not hash password"))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	now := time.Now()
+	user.Password = hashedPassword
+	user.UpdatedAt = now
+
+	// the reset link was sent to the email address so it has been verified
+	if user.EmailVerifiedAt == nil {
+		user.EmailVerifiedAt = &now
+	}
+
+	err = r.db.UserRepository().Update(*user)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot reset password of user with ID: %s", user.ID.String()))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	// whoever knew the old password should not stay logged in
+	err = r.jwtService.RevokeUserSessions(user.ID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, err)
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	return true, nil
+}
+
+func (r *mutationResolver) UpdateProfile(ctx context.Context, input model.UpdateProfileInput) (*model.User, error) {
+	user, err := r.authorizeUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	input.Email = entities.NormalizeEmail(input.Email)
+	validationResult := r.validator.ValidateUpdateProfileInput(ctx, *user, input)
+	if validationResult.HasError {
+		return nil, validationResult.Error
+	}
+
+	emailChanged := input.Email != entities.NormalizeEmail(user.Email)
+
+	updated := *user
+	updated.FirstName = input.FirstName
+	updated.LastName = input.LastName
+	updated.Email = input.Email
+	updated.UpdatedAt = time.Now()
+	if emailChanged {
+		updated.EmailVerifiedAt = nil
+	}
+
+	err = r.db.UserRepository().Update(updated)
+	// another request took the email address after it was validated
+	if err == database.ErrEmailTaken {
+		return nil, emailTakenError(ctx)
+	}
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot update profile of user with ID: %s", user.ID.String()))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	if emailChanged {
+		err = r.sendVerificationEmail(updated)
+		if err != nil {
+			r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot send verification email to user with ID: %s", user.ID.String()))
+		}
+	}
+
+	return userToModel(updated), nil
+}
+
+func (r *mutationResolver) ChangePassword(ctx context.Context, input model.ChangePasswordInput) (*model.Token, error) {
+	user, err := r.authorizeUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	validationResult := r.validator.ValidateChangePasswordInput(ctx, input)
+	if validationResult.HasError {
+		return nil, validationResult.Error
+	}
+
+	err = r.checkPassword(ctx, *user, input.CurrentPassword)
+	if err == errWrongPassword {
+		return nil, validator.NewValidationResult(ctx, []validator.FieldError{{Field: "currentPassword", Message: "the current password is not correct"}}).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := r.passwordService.HashPassword(input.NewPassword)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrap(err, "could not hash password"))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	updated := *user
+	updated.Password = hashedPassword
+	updated.UpdatedAt = time.Now()
+
+	err = r.db.UserRepository().Update(updated)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot change password of user with ID: %s", user.ID.String()))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	err = r.jwtService.RevokeUserSessions(user.ID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, err)
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	session, err := r.jwtService.CreateSession(user.ID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot create session for user with ID: %s", user.ID.String()))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	return sessionToModel(session), nil
+}
+
+func (r *mutationResolver) DeleteAccount(ctx context.Context, input model.DeleteAccountInput) (bool, error) {
+	user, err := r.authorizeUser(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	err = r.checkPassword(ctx, *user, input.Password)
+	if err == errWrongPassword {
+		return false, validator.NewValidationResult(ctx, []validator.FieldError{{Field: "password", Message: "the password is not correct"}}).Error
+	}
+	if err != nil {
+		return false, err
+	}
+
+	err = r.deleteImportCredentials(user.ID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot delete import credentials of user with ID: %s", user.ID.String()))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	err = r.db.UserDataRepository().DeleteAll(user.ID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, err)
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	err = r.jwtService.RevokeUserSessions(user.ID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, err)
+	}
+
+	return true, nil
+}
+
+func (r *mutationResolver) ExportMyData(ctx context.Context) (*model.DataExport, error) {
+	user, err := r.authorizeUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	export, err := r.dataExportService.Create(user.ID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, err)
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	return &model.DataExport{
+		URL:       DataExportPath + export.Token,
+		ExpiresAt: export.ExpiresAt.Format(internalTime.DefaultFormat),
+	}, nil
+}
@@ -0,0 +1,48 @@
+package resolver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/cache/memory"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/entities"
+	internalErrors "github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/errors"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/services/verification"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+	pkgErrors "github.com/pkg/errors"
+)
+
+func TestResolverConsumeVerificationTokenAfterEmailChange(t *testing.T) {
+	user := entities.User{ID: id.New(), Email: "jane@example.com"}
+	users := &userRepository{users: map[id.ID]entities.User{user.ID: user}}
+	verificationService := verification.NewService(memory.NewClient())
+	resolver := &Resolver{
+		db:                  fakeDB{users: users},
+		errorHandler:        discardErrorHandler{},
+		verificationService: verificationService,
+	}
+
+	oldToken, err := verificationService.CreateForEmail(verification.PurposeEmailVerification, user.ID, user.Email, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user.Email = "Jane@Example.org"
+	users.users[user.ID] = user
+
+	newToken, err := verificationService.CreateForEmail(verification.PurposeEmailVerification, user.ID, user.Email, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = resolver.consumeVerificationToken(context.Background(), verification.PurposeEmailVerification, oldToken)
+	if pkgErrors.Cause(err) != internalErrors.ErrValidationError {
+		t.Errorf("consumeVerificationToken() with the token of the old email error = %v, want %v", err, internalErrors.ErrValidationError)
+	}
+
+	consumed, err := resolver.consumeVerificationToken(context.Background(), verification.PurposeEmailVerification, newToken)
+	if err != nil || consumed.ID != user.ID {
+		t.Errorf("consumeVerificationToken() with the token of the new email = %v, %v, want user %s", consumed, err, user.ID)
+	}
+}
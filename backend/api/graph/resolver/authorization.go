@@ -0,0 +1,110 @@
+package resolver
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/database"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/entities"
+	internalErrors "github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/errors"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/middlewares"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+	pkgErrors "github.com/pkg/errors"
+)
+
+type contextKey string
+
+// keyUser is the context key of the user which is loaded by the @authenticated directive
+const keyUser = contextKey("user")
+
+// Authenticated implements the @authenticated directive. The logged in user is loaded once and added to the context of
+// the field so the resolvers don't fetch it again.
+func (r *Resolver) Authenticated(ctx context.Context, _ interface{}, next graphql.Resolver) (interface{}, error) {
+	user, err := r.authorizeUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return next(context.WithValue(ctx, keyUser, user))
+}
+
+// authorizeUser returns the logged in user
+func (r *Resolver) authorizeUser(ctx context.Context) (*entities.User, error) {
+	if user, ok := ctx.Value(keyUser).(*entities.User); ok {
+		return user, nil
+	}
+
+	userID, ok := ctx.Value(middlewares.KeyUserID).(id.ID)
+	if !ok {
+		return nil, internalErrors.ErrUnauthenticated
+	}
+
+	user, err := r.db.UserRepository().FindByID(userID)
+	if err == database.ErrEntityNotFound {
+		return nil, internalErrors.ErrUnauthenticated
+	}
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot find user with ID: %s", userID.String()))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	return user, nil
+}
+
+// authorizeAdmin returns the logged in user if the user is an admin
+func (r *Resolver) authorizeAdmin(ctx context.Context) (*entities.User, error) {
+	user, err := r.authorizeUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsAdmin {
+		return nil, internalErrors.ErrForbidden
+	}
+
+	return user, nil
+}
+
+// authorizeImport returns the ID of an import which the logged in user is allowed to view.
+// Imports are owned by the user who requested them, admins can view all imports.
+func (r *Resolver) authorizeImport(ctx context.Context, importID string) (transactionID id.ID, err error) {
+	user, err := r.authorizeUser(ctx)
+	if err != nil {
+		return transactionID, err
+	}
+
+	transactionID, err = id.FromString(importID)
+	if err != nil {
+		return transactionID, internalErrors.ErrNotFound
+	}
+
+	if user.IsAdmin {
+		return transactionID, nil
+	}
+
+	ownerID, err := r.importOwnerID(transactionID)
+	if err == database.ErrEntityNotFound {
+		return transactionID, internalErrors.ErrNotFound
+	}
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot find the owner of import: %s", importID))
+		return transactionID, internalErrors.ErrInternalServerError
+	}
+
+	// Users should not know that the imports of other users exist
+	if ownerID != user.ID {
+		return transactionID, internalErrors.ErrNotFound
+	}
+
+	return transactionID, nil
+}
+
+// importOwnerID returns the ID of the user who owns an import
+func (r *Resolver) importOwnerID(transactionID id.ID) (userID id.ID, err error) {
+	entity, err := r.db.ImportRepository().FindByTransactionID(transactionID)
+	if err != nil {
+		return userID, err
+	}
+
+	return entity.UserID, nil
+}
@@ -0,0 +1,48 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/entities"
+	internalErrors "github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/errors"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+)
+
+func TestResolverAuthorizeImport(t *testing.T) {
+	owner := &entities.User{ID: id.New()}
+	otherUser := &entities.User{ID: id.New()}
+	admin := &entities.User{ID: id.New(), IsAdmin: true}
+
+	ownedImport, unknownImport := id.New(), id.New()
+
+	resolver := &Resolver{
+		db:           fakeDB{imports: importRepository{ownedImport: owner.ID}},
+		errorHandler: discardErrorHandler{},
+	}
+
+	tests := []struct {
+		name     string
+		user     *entities.User
+		importID string
+		err      error
+	}{
+		{name: "owner", user: owner, importID: ownedImport.String()},
+		{name: "other user", user: otherUser, importID: ownedImport.String(), err: internalErrors.ErrNotFound},
+		{name: "admin", user: admin, importID: ownedImport.String()},
+		{name: "unknown import", user: owner, importID: unknownImport.String(), err: internalErrors.ErrNotFound},
+		{name: "invalid ID", user: owner, importID: "invalid", err: internalErrors.ErrNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			transactionID, err := resolver.authorizeImport(contextWithUser(test.user), test.importID)
+			if err != test.err {
+				t.Fatalf("authorizeImport() error = %v, want %v", err, test.err)
+			}
+
+			if err == nil && transactionID.String() != test.importID {
+				t.Errorf("authorizeImport() = %s, want %s", transactionID, test.importID)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package resolver
+
+import (
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/entities"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/graph/model"
+	internalTime "github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/time"
+)
+
+// calculationRunsToModel groups the calculation results by the run in which they were calculated
+func calculationRunsToModel(results []entities.CalculationResult) []*model.CalculationRun {
+	runs := []*model.CalculationRun{}
+	runIndexes := map[string]int{}
+
+	for _, result := range results {
+		index, ok := runIndexes[result.RunID.String()]
+		if !ok {
+			index = len(runs)
+			runIndexes[result.RunID.String()] = index
+			runs = append(runs, &model.CalculationRun{
+				ID:           result.RunID.String(),
+				ImportID:     result.TransactionID.String(),
+				CalculatedAt: result.CalculatedAt.Format(internalTime.DefaultFormat),
+				Results:      []*model.CalculationResult{},
+			})
+		}
+
+		runs[index].Results = append(runs[index].Results, calculationResultToModel(result))
+	}
+
+	return runs
+}
+
+func calculationResultToModel(result entities.CalculationResult) *model.CalculationResult {
+	return &model.CalculationResult{
+		ID:                result.ID.String(),
+		Product:           result.Product,
+		CompanyName:       result.CompanyName,
+		RuleSetVersion:    result.RuleSetVersion,
+		FareTableVersion:  result.FareTableVersion,
+		Currency:          result.Currency,
+		JourneyCount:      result.JourneyCount,
+		JourneysPrice:     result.JourneysPrice,
+		SubscriptionPrice: result.SubscriptionPrice,
+		TotalPrice:        result.TotalPrice,
+		IsCheapest:        result.IsCheapest,
+	}
+}
@@ -0,0 +1,51 @@
+package resolver
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/database"
+	internalErrors "github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/errors"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/graph/model"
+	pkgErrors "github.com/pkg/errors"
+)
+
+func (r *queryResolver) CalculationResults(ctx context.Context, importID string) (*model.CalculationRun, error) {
+	transactionID, err := r.authorizeImport(ctx, importID)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := r.db.CalculationResultRepository().FindLatestByTransactionID(transactionID)
+	if err == database.ErrEntityNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot fetch calculation results for import: %s", importID))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	runs := calculationRunsToModel(results)
+	if len(runs) == 0 {
+		return nil, nil
+	}
+
+	return runs[0], nil
+}
+
+func (r *queryResolver) CalculationHistory(ctx context.Context, importID string) ([]*model.CalculationRun, error) {
+	transactionID, err := r.authorizeImport(ctx, importID)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := r.db.CalculationResultRepository().FindByTransactionID(transactionID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot fetch calculation history for import: %s", importID))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	return calculationRunsToModel(results), nil
+}
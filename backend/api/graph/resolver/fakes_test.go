@@ -0,0 +1,107 @@
+package resolver
+
+import (
+	"context"
+	"sync"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/database"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/entities"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+)
+
+// fakeDB is a database.DB which only implements the repositories which are used in the tests
+type fakeDB struct {
+	database.DB
+	imports importRepository
+	jobs    jobRepository
+	users   *userRepository
+}
+
+func (db fakeDB) UserRepository() database.UserRepository {
+	return db.users
+}
+
+func (db fakeDB) ImportRepository() database.ImportRepository {
+	return db.imports
+}
+
+func (db fakeDB) JobRepository() database.JobRepository {
+	return db.jobs
+}
+
+// importRepository keeps the owners of the imports in memory
+type importRepository map[id.ID]id.ID
+
+func (repository importRepository) Store(entity entities.Import) error {
+	if _, ok := repository[entity.TransactionID]; !ok {
+		repository[entity.TransactionID] = entity.UserID
+	}
+	return nil
+}
+
+func (repository importRepository) FindByTransactionID(transactionID id.ID) (*entities.Import, error) {
+	userID, ok := repository[transactionID]
+	if !ok {
+		return nil, database.ErrEntityNotFound
+	}
+	return &entities.Import{TransactionID: transactionID, UserID: userID}, nil
+}
+
+// jobRepository keeps the stored jobs in memory
+type jobRepository struct {
+	database.JobRepository
+	jobs *[]entities.Job
+}
+
+func (repository jobRepository) Store(job entities.Job) error {
+	*repository.jobs = append(*repository.jobs, job)
+	return nil
+}
+
+// discardErrorHandler ignores all the errors
+type discardErrorHandler struct{}
+
+func (handler discardErrorHandler) CaptureError(ctx context.Context, err error) {}
+
+// contextWithUser returns a context where the user is logged in
+func contextWithUser(user *entities.User) context.Context {
+	return context.WithValue(context.Background(), keyUser, user)
+}
+
+// userRepository keeps the users in memory
+type userRepository struct {
+	database.UserRepository
+	mutex sync.Mutex
+	users map[id.ID]entities.User
+}
+
+func (repository *userRepository) RemoveRecoveryCode(userID id.ID, hash string) (bool, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	user, ok := repository.users[userID]
+	if !ok {
+		return false, nil
+	}
+
+	for index, storedHash := range user.RecoveryCodes {
+		if storedHash == hash {
+			user.RecoveryCodes = append(user.RecoveryCodes[:index:index], user.RecoveryCodes[index+1:]...)
+			repository.users[userID] = user
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (repository *userRepository) FindByID(userID id.ID) (*entities.User, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	user, ok := repository.users[userID]
+	if !ok {
+		return nil, database.ErrEntityNotFound
+	}
+	return &user, nil
+}
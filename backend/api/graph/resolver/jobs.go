@@ -0,0 +1,69 @@
+package resolver
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/entities"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/graph/model"
+	internalTime "github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/time"
+	"github.com/pkg/errors"
+)
+
+// The worker reads the credentials of an import job from the cache using this key followed by the job ID
+const keyPrefixImportCredentials = "import-credentials:"
+
+// The credentials are deleted by the worker when the job is done and expire if the job never runs
+const importCredentialsExpiration = 24 * time.Hour
+
+// canViewJob determines if a user is allowed to see the progress of a job
+func canViewJob(user *entities.User, job *entities.Job) bool {
+	return user.IsAdmin || (job.UserID != nil && *job.UserID == user.ID)
+}
+
+func jobToModel(job entities.Job) *model.Job {
+	result := &model.Job{
+		ID:             job.ID.String(),
+		Type:           string(job.Type),
+		Status:         string(job.Status),
+		ImportID:       job.TransactionID.String(),
+		Reason:         job.Reason,
+		ProcessedItems: job.ProcessedItems,
+		TotalItems:     job.TotalItems,
+		Progress:       job.Progress(),
+		Attempts:       job.Attempts,
+		MaxAttempts:    job.MaxAttempts,
+		CreatedAt:      job.CreatedAt.Format(internalTime.DefaultFormat),
+		UpdatedAt:      job.UpdatedAt.Format(internalTime.DefaultFormat),
+	}
+
+	if job.Error != "" {
+		result.Error = &job.Error
+	}
+
+	return result
+}
+
+// storeImportCredentials keeps the credentials of the ov-chipkaart account in the cache until the import job runs
+func (r *Resolver) storeImportCredentials(job entities.Job, credentials entities.ImportCredentials) error {
+	value, err := json.Marshal(credentials)
+	if err != nil {
+		return errors.Wrap(err, "cannot encode import credentials")
+	}
+
+	err = r.cache.Set(keyPrefixImportCredentials+job.ID.String(), string(value), importCredentialsExpiration)
+	if err != nil {
+		return errors.Wrapf(err, "cannot store credentials for job with ID: %s", job.ID.String())
+	}
+
+	return nil
+}
+
+// deleteJobImportCredentials deletes the credentials of an import job which was not stored
+func (r *Resolver) deleteJobImportCredentials(ctx context.Context, job entities.Job) {
+	err := r.cache.Delete(keyPrefixImportCredentials + job.ID.String())
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, errors.Wrapf(err, "cannot delete the credentials of job with ID: %s", job.ID.String()))
+	}
+}
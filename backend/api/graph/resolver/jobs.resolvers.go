@@ -0,0 +1,163 @@
+package resolver
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"time"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/database"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/entities"
+	internalErrors "github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/errors"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/graph/model"
+	internalID "github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+	internalTime "github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/time"
+	pkgErrors "github.com/pkg/errors"
+)
+
+func (r *mutationResolver) ImportCard(ctx context.Context, input model.ImportCardInput) (*model.Job, error) {
+	user, err := r.authorizeUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.EmailVerifiedAt == nil {
+		return nil, internalErrors.ErrEmailNotVerified
+	}
+
+	validationResult := r.validator.ValidateImportCardInput(ctx, input)
+	if validationResult.HasError {
+		return nil, validationResult.Error
+	}
+
+	if input.IdempotencyKey != nil {
+		existing, err := r.db.JobRepository().FindByIdempotencyKey(user.ID, *input.IdempotencyKey)
+		if err == nil {
+			return jobToModel(*existing), nil
+		}
+		if err != database.ErrEntityNotFound {
+			r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot find job with idempotency key: %s", *input.IdempotencyKey))
+			return nil, internalErrors.ErrInternalServerError
+		}
+	}
+
+	startDate, _ := time.Parse(internalTime.DateFormat, input.StartDate)
+	endDate := time.Now().UTC()
+	if input.EndDate != nil {
+		endDate, _ = time.Parse(internalTime.DateFormat, *input.EndDate)
+	}
+
+	job := entities.Job{
+		ID:            internalID.New(),
+		Type:          entities.JobTypeImport,
+		Status:        entities.JobStatusPending,
+		TransactionID: internalID.New(),
+		UserID:        &user.ID,
+		Reason:        "the transactions of card " + input.CardNumber + " were requested",
+		Import: &entities.ImportJobOptions{
+			CardNumber: input.CardNumber,
+			StartDate:  startDate,
+			EndDate:    endDate,
+		},
+		MaxAttempts: entities.JobMaxAttempts,
+		RunAfter:    time.Now().UTC(),
+		CreatedAt:   time.Now().UTC(),
+		UpdatedAt:   time.Now().UTC(),
+	}
+	if input.IdempotencyKey != nil {
+		job.IdempotencyKey = *input.IdempotencyKey
+	}
+
+	// The credentials are stored first so that a worker never claims the job before they exist
+	err = r.storeImportCredentials(job, entities.ImportCredentials{Username: input.Username, Password: input.Password})
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, err)
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	err = r.db.JobRepository().Store(job)
+	if err != nil {
+		r.deleteJobImportCredentials(ctx, job)
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrap(err, "cannot store import job"))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	// Another request with the same idempotency key could have stored its job first, the credentials of the job which
+	// was not stored are deleted so they are not kept until they expire
+	stored, err := r.db.JobRepository().FindByID(job.ID)
+	if err == database.ErrEntityNotFound && job.IdempotencyKey != "" {
+		r.deleteJobImportCredentials(ctx, job)
+		stored, err = r.db.JobRepository().FindByIdempotencyKey(user.ID, job.IdempotencyKey)
+	}
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot find import job with ID: %s", job.ID.String()))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	err = r.db.ImportRepository().Store(entities.Import{TransactionID: stored.TransactionID, UserID: user.ID, CreatedAt: time.Now().UTC()})
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot store the owner of import: %s", stored.TransactionID.String()))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	return jobToModel(*stored), nil
+}
+
+func (r *queryResolver) Job(ctx context.Context, id string) (*model.Job, error) {
+	user, err := r.authorizeUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	jobID, err := internalID.FromString(id)
+	if err != nil {
+		return nil, internalErrors.ErrNotFound
+	}
+
+	job, err := r.db.JobRepository().FindByID(jobID)
+	if err == database.ErrEntityNotFound {
+		return nil, internalErrors.ErrNotFound
+	}
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot find job with ID: %s", id))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	// Users should not know that the jobs of other users exist
+	if !canViewJob(user, job) {
+		return nil, internalErrors.ErrNotFound
+	}
+
+	return jobToModel(*job), nil
+}
+
+func (r *queryResolver) Jobs(ctx context.Context, status *string) ([]*model.Job, error) {
+	user, err := r.authorizeUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	jobStatus := entities.JobStatusRunning
+	if status != nil {
+		jobStatus = entities.JobStatus(*status)
+	}
+
+	var jobs []entities.Job
+	if user.IsAdmin {
+		jobs, err = r.db.JobRepository().FindByStatus(jobStatus)
+	} else {
+		jobs, err = r.db.JobRepository().FindByUserIDAndStatus(user.ID, jobStatus)
+	}
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrap(err, "cannot fetch jobs"))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	result := []*model.Job{}
+	for _, job := range jobs {
+		result = append(result, jobToModel(job))
+	}
+
+	return result, nil
+}
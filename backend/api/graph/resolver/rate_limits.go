@@ -0,0 +1,112 @@
+package resolver
+
+import (
+	"context"
+	"time"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/entities"
+	internalErrors "github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/errors"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/middlewares"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+	pkgErrors "github.com/pkg/errors"
+)
+
+// The limits are per IP address and are stricter than the limit of the rate limit middleware
+const (
+	loginLimit               = 10
+	loginLimitWindow         = time.Minute
+	signUpLimit              = 5
+	signUpLimitWindow        = time.Hour
+	passwordResetLimit       = 5
+	passwordResetLimitWindow = time.Hour
+)
+
+// errWrongPassword is returned by checkPassword when the password does not match
+var errWrongPassword = pkgErrors.New("the password is not correct")
+
+// limitByClientIP returns a RateLimitError when the client has performed an action too often
+func (r *Resolver) limitByClientIP(ctx context.Context, action string, limit int, window time.Duration) error {
+	result, err := r.limiter.Allow(action+":"+clientIP(ctx), limit, window)
+	if err != nil {
+		// the action is allowed when the cache is down so users can still log in
+		r.errorHandler.CaptureError(ctx, err)
+		return nil
+	}
+
+	if !result.Allowed {
+		return internalErrors.NewRateLimitError(result.RetryAfter)
+	}
+
+	return nil
+}
+
+// clientIP returns the IP address of the client which sent the request
+func clientIP(ctx context.Context) string {
+	clientIP, ok := ctx.Value(middlewares.KeyClientIP).(string)
+	if !ok {
+		return "unknown"
+	}
+	return clientIP
+}
+
+// lockoutAccount is the account of a user in the lockout
+func lockoutAccount(userID id.ID) string {
+	return "user:" + userID.String()
+}
+
+// unknownLockoutAccount is the account of an email address which is not registered in the lockout. Unknown emails are
+// locked like registered accounts so the lockout does not reveal which email addresses are registered.
+func unknownLockoutAccount(email string) string {
+	return "email:" + entities.NormalizeEmail(email)
+}
+
+// checkPassword compares a password with the password of a user. The account is locked with exponential backoff after
+// too many wrong passwords so the password cannot be brute forced.
+func (r *Resolver) checkPassword(ctx context.Context, user entities.User, password string) error {
+	err := r.checkLockout(ctx, lockoutAccount(user.ID))
+	if err != nil {
+		return err
+	}
+
+	if !r.passwordService.CheckPasswordHash(password, user.Password) {
+		return r.recordLoginFailure(ctx, lockoutAccount(user.ID), errWrongPassword)
+	}
+
+	return nil
+}
+
+// checkLockout returns an error when the account is locked after too many failed logins
+func (r *Resolver) checkLockout(ctx context.Context, account string) error {
+	lockedFor, err := r.lockout.LockedFor(account)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, err)
+	}
+
+	if lockedFor > 0 {
+		return internalErrors.NewAccountLockedError(lockedFor)
+	}
+
+	return nil
+}
+
+// recordLoginFailure returns the account locked error when the failure locks the account and failureErr otherwise
+func (r *Resolver) recordLoginFailure(ctx context.Context, account string, failureErr error) error {
+	lockedFor, err := r.lockout.RecordFailure(account)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, err)
+	}
+
+	if lockedFor > 0 {
+		return internalErrors.NewAccountLockedError(lockedFor)
+	}
+
+	return failureErr
+}
+
+// resetLockout forgets the failed logins of an account, it is called after a login with every factor succeeded
+func (r *Resolver) resetLockout(ctx context.Context, account string) {
+	err := r.lockout.Reset(account)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, err)
+	}
+}
@@ -0,0 +1,41 @@
+package resolver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/cache/memory"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/entities"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/services/password"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/services/ratelimit"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+)
+
+func TestResolverCheckPasswordDoesNotResetTheLockout(t *testing.T) {
+	passwordService := password.NewBcryptService()
+	hash, err := passwordService.HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := entities.User{ID: id.New(), Password: hash}
+	resolver := &Resolver{
+		passwordService: passwordService,
+		errorHandler:    discardErrorHandler{},
+		lockout:         ratelimit.NewLockout(memory.NewClient(), ratelimit.LockoutOptions{MaxFailures: 2, BaseDelay: time.Second, MaxDelay: time.Minute}),
+	}
+	ctx := contextWithUser(&user)
+
+	if err = resolver.checkPassword(ctx, user, "wrong"); err != errWrongPassword {
+		t.Fatalf("checkPassword() with a wrong password error = %v, want %v", err, errWrongPassword)
+	}
+
+	// the second factor of the login is still missing so the failures are kept
+	if err = resolver.checkPassword(ctx, user, "correct horse"); err != nil {
+		t.Fatalf("checkPassword() with the password error = %v", err)
+	}
+
+	if err = resolver.checkPassword(ctx, user, "wrong"); err == errWrongPassword || err == nil {
+		t.Errorf("checkPassword() after 2 wrong passwords error = %v, want the account to be locked", err)
+	}
+}
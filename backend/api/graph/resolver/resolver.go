@@ -1,10 +1,18 @@
 package resolver
 
 import (
+	"strings"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/cache"
 	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/database"
 	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/graph/validator"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/services/dataexport"
 	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/services/jwt"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/services/mailer"
 	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/services/password"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/services/ratelimit"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/services/twofactor"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/services/verification"
 	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/errorhandler"
 	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/logger"
 )
@@ -15,12 +23,20 @@ import (
 
 // Resolver resolves
 type Resolver struct {
-	db              database.DB
-	validator       validator.Validator
-	passwordService password.Service
-	errorHandler    errorhandler.ErrorHandler
-	logger          logger.Logger
-	jwtService      jwt.Service
+	db                  database.DB
+	validator           validator.Validator
+	passwordService     password.Service
+	errorHandler        errorhandler.ErrorHandler
+	logger              logger.Logger
+	jwtService          jwt.Service
+	cache               cache.Cache
+	mailer              mailer.Mailer
+	verificationService verification.Service
+	dataExportService   dataexport.Service
+	limiter             ratelimit.Limiter
+	lockout             ratelimit.Lockout
+	twoFactorService    twofactor.Service
+	frontendURL         string
 }
 
 // NewResolver creates a new instance of the resolver
@@ -31,14 +47,30 @@ func NewResolver(
 	errorHandler errorhandler.ErrorHandler,
 	logger logger.Logger,
 	jwtService jwt.Service,
+	cache cache.Cache,
+	mailer mailer.Mailer,
+	verificationService verification.Service,
+	dataExportService dataexport.Service,
+	limiter ratelimit.Limiter,
+	lockout ratelimit.Lockout,
+	twoFactorService twofactor.Service,
+	frontendURL string,
 ) *Resolver {
 
 	return &Resolver{
-		db:              db,
-		validator:       validator,
-		passwordService: passwordService,
-		errorHandler:    errorHandler,
-		logger:          logger,
-		jwtService:      jwtService,
+		db:                  db,
+		validator:           validator,
+		passwordService:     passwordService,
+		errorHandler:        errorHandler,
+		logger:              logger,
+		jwtService:          jwtService,
+		cache:               cache,
+		mailer:              mailer,
+		verificationService: verificationService,
+		dataExportService:   dataExportService,
+		limiter:             limiter,
+		lockout:             lockout,
+		twoFactorService:    twoFactorService,
+		frontendURL:         strings.TrimSuffix(frontendURL, "/"),
 	}
 }
@@ -0,0 +1,91 @@
+package resolver
+
+import (
+	"time"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/database"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/entities"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/graph/model"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+	internalTime "github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/time"
+	pkgErrors "github.com/pkg/errors"
+)
+
+// requestReEnrichment queues jobs which enrich the imports of the review queue items again. The jobs are owned by the
+// owner of the import so the owner can follow the progress, the jobs of an import without an owner are only shown to
+// the admins.
+func (r *Resolver) requestReEnrichment(items []entities.ReviewQueueItem, reason string) error {
+	transactionIDs := map[id.ID]bool{}
+	for _, item := range items {
+		if item.TransactionID == nil || transactionIDs[*item.TransactionID] {
+			continue
+		}
+		transactionIDs[*item.TransactionID] = true
+
+		job := entities.Job{
+			ID:            id.New(),
+			Type:          entities.JobTypeReEnrichment,
+			Status:        entities.JobStatusPending,
+			TransactionID: *item.TransactionID,
+			Reason:        reason,
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		}
+
+		ownerID, err := r.importOwnerID(*item.TransactionID)
+		if err != nil && err != database.ErrEntityNotFound {
+			return pkgErrors.Wrapf(err, "cannot find the owner of import: %s", item.TransactionID.String())
+		}
+		if err == nil {
+			job.UserID = &ownerID
+		}
+
+		err = r.db.JobRepository().Store(job)
+		if err != nil {
+			return pkgErrors.Wrap(err, "cannot queue re-enrichment job for import")
+		}
+	}
+
+	return nil
+}
+
+func reviewQueueItemToModel(item entities.ReviewQueueItem) *model.ReviewQueueItem {
+	result := &model.ReviewQueueItem{
+		ID:          item.ID.String(),
+		Type:        string(item.Type),
+		Text:        item.Text,
+		Status:      string(item.Status),
+		Occurrences: item.Occurrences,
+		Suggestions: []*model.ReviewQueueSuggestion{},
+		CreatedAt:   item.CreatedAt.Format(internalTime.DefaultFormat),
+		UpdatedAt:   item.UpdatedAt.Format(internalTime.DefaultFormat),
+	}
+
+	if item.Reason != "" {
+		result.Reason = &item.Reason
+	}
+
+	if item.StationName != "" {
+		result.StationName = &item.StationName
+	}
+
+	if item.RawRecordID != nil {
+		rawRecordID := item.RawRecordID.String()
+		result.RawRecordID = &rawRecordID
+	}
+
+	if item.TransactionID != nil {
+		importID := item.TransactionID.String()
+		result.ImportID = &importID
+	}
+
+	for _, suggestion := range item.Suggestions {
+		result.Suggestions = append(result.Suggestions, &model.ReviewQueueSuggestion{
+			Code:       suggestion.Code,
+			Name:       suggestion.Name,
+			Confidence: suggestion.Confidence,
+		})
+	}
+
+	return result
+}
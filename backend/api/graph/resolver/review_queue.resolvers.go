@@ -0,0 +1,140 @@
+package resolver
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"strings"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/database"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/entities"
+	internalErrors "github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/errors"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/graph/model"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+	pkgErrors "github.com/pkg/errors"
+)
+
+func (r *mutationResolver) MapStationName(ctx context.Context, input model.MapStationNameInput) ([]*model.ReviewQueueItem, error) {
+	_, err := r.authorizeAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stationName := strings.ToLower(strings.TrimSpace(input.StationName))
+	if stationName == "" || strings.TrimSpace(input.StationCode) == "" {
+		return nil, internalErrors.ErrValidationError
+	}
+
+	err = r.db.StationRepository().AddAlias(stationName, strings.TrimSpace(input.StationCode))
+	if err == database.ErrEntityNotFound {
+		return nil, internalErrors.ErrNotFound
+	}
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot map station name '%s' to code '%s'", stationName, input.StationCode))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	items, err := r.db.ReviewQueueRepository().FindOpenForStationName(stationName)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot find review queue items for station name '%s'", stationName))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	var itemIDs []id.ID
+	for index := range items {
+		itemIDs = append(itemIDs, items[index].ID)
+		items[index].Status = entities.ReviewQueueItemStatusResolved
+	}
+
+	if len(itemIDs) > 0 {
+		err = r.db.ReviewQueueRepository().UpdateStatus(itemIDs, entities.ReviewQueueItemStatusResolved)
+		if err != nil {
+			r.errorHandler.CaptureError(ctx, err)
+			return nil, internalErrors.ErrInternalServerError
+		}
+	}
+
+	err = r.requestReEnrichment(items, "station name '"+stationName+"' was mapped to '"+input.StationCode+"'")
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, err)
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	result := []*model.ReviewQueueItem{}
+	for _, item := range items {
+		result = append(result, reviewQueueItemToModel(item))
+	}
+
+	return result, nil
+}
+
+func (r *mutationResolver) IgnoreReviewQueueItem(ctx context.Context, input model.IgnoreReviewQueueItemInput) (*model.ReviewQueueItem, error) {
+	_, err := r.authorizeAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	itemID, err := id.FromString(input.ID)
+	if err != nil {
+		return nil, internalErrors.ErrNotFound
+	}
+
+	item, err := r.db.ReviewQueueRepository().FindByID(itemID)
+	if err == database.ErrEntityNotFound {
+		return nil, internalErrors.ErrNotFound
+	}
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot find review queue item with ID: %s", input.ID))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	// The raw record is skipped so the journeys of the import change and the import has to be enriched again
+	if item.RawRecordID != nil {
+		err = r.db.RawRecordRepository().Ignore(*item.RawRecordID)
+		if err != nil && err != database.ErrEntityNotFound {
+			r.errorHandler.CaptureError(ctx, err)
+			return nil, internalErrors.ErrInternalServerError
+		}
+
+		err = r.requestReEnrichment([]entities.ReviewQueueItem{*item}, "raw record '"+item.RawRecordID.String()+"' was ignored")
+		if err != nil {
+			r.errorHandler.CaptureError(ctx, err)
+			return nil, internalErrors.ErrInternalServerError
+		}
+	}
+
+	err = r.db.ReviewQueueRepository().UpdateStatus([]id.ID{item.ID}, entities.ReviewQueueItemStatusIgnored)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, err)
+		return nil, internalErrors.ErrInternalServerError
+	}
+	item.Status = entities.ReviewQueueItemStatusIgnored
+
+	return reviewQueueItemToModel(*item), nil
+}
+
+func (r *queryResolver) ReviewQueue(ctx context.Context, status *string) ([]*model.ReviewQueueItem, error) {
+	_, err := r.authorizeAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	itemStatus := entities.ReviewQueueItemStatusOpen
+	if status != nil {
+		itemStatus = entities.ReviewQueueItemStatus(*status)
+	}
+
+	items, err := r.db.ReviewQueueRepository().FindByStatus(itemStatus)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrap(err, "cannot fetch review queue items"))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	result := []*model.ReviewQueueItem{}
+	for _, item := range items {
+		result = append(result, reviewQueueItemToModel(item))
+	}
+
+	return result, nil
+}
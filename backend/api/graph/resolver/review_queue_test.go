@@ -0,0 +1,35 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/entities"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+)
+
+func TestResolverRequestReEnrichment(t *testing.T) {
+	ownerID, ownedImport, unknownImport := id.New(), id.New(), id.New()
+
+	var jobs []entities.Job
+	resolver := &Resolver{db: fakeDB{imports: importRepository{ownedImport: ownerID}, jobs: jobRepository{jobs: &jobs}}}
+
+	err := resolver.requestReEnrichment([]entities.ReviewQueueItem{
+		{TransactionID: &ownedImport},
+		{TransactionID: &ownedImport},
+		{TransactionID: &unknownImport},
+		{},
+	}, "station name was mapped")
+	if err != nil {
+		t.Fatalf("requestReEnrichment() error = %v", err)
+	}
+
+	if len(jobs) != 2 {
+		t.Fatalf("%d jobs queued, want one job per import", len(jobs))
+	}
+	if jobs[0].TransactionID != ownedImport || jobs[0].UserID == nil || *jobs[0].UserID != ownerID {
+		t.Errorf("job = %+v, want a job of %s owned by %s", jobs[0], ownedImport, ownerID)
+	}
+	if jobs[1].TransactionID != unknownImport || jobs[1].UserID != nil {
+		t.Errorf("job = %+v, want a job of %s without an owner", jobs[1], unknownImport)
+	}
+}
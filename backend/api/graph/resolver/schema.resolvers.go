@@ -5,13 +5,8 @@ package resolver
 
 import (
 	"context"
-	"fmt"
 	"time"
 
-	"github.com/99designs/gqlgen/graphql"
-	"github.com/vektah/gqlparser/v2/ast"
-	"github.com/vektah/gqlparser/v2/gqlerror"
-
 	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/database"
 	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/entities"
 	internalErrors "github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/errors"
@@ -19,12 +14,17 @@ import (
 	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/graph/model"
 	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/graph/validator"
 	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
-	internalTime "github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/time"
 	pkgErrors "github.com/pkg/errors"
 )
 
 func (r *mutationResolver) CreateUser(ctx context.Context, input model.CreateUserInput) (*model.AuthOutput, error) {
-	validationResult := r.validator.ValidateCreateUserInput(input)
+	err := r.limitByClientIP(ctx, "sign-up", signUpLimit, signUpLimitWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	input.Email = entities.NormalizeEmail(input.Email)
+	validationResult := r.validator.ValidateCreateUserInput(ctx, input)
 	if validationResult.HasError {
 		return nil, validationResult.Error
 	}
@@ -46,93 +46,124 @@ func (r *mutationResolver) CreateUser(ctx context.Context, input model.CreateUse
 	}
 
 	err = r.db.UserRepository().Store(user)
+	// another request created a user with the email address after it was validated
+	if err == database.ErrEmailTaken {
+		return nil, emailTakenError(ctx)
+	}
 	if err != nil {
 		r.errorHandler.CaptureError(ctx, pkgErrors.Wrap(err, "cannot save user in the database"))
 		return nil, internalErrors.ErrInternalServerError
 	}
 
-	token, err := r.jwtService.GenerateTokenForUserID(user.ID)
+	// the account is created even when the email cannot be sent, the user can request a new email
+	err = r.sendVerificationEmail(user)
 	if err != nil {
-		r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot generate jwt token for user with ID: %s", user.ID.String()))
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot send verification email to user with ID: %s", user.ID.String()))
+	}
+
+	session, err := r.jwtService.CreateSession(user.ID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot create session for user with ID: %s", user.ID.String()))
 		return nil, internalErrors.ErrInternalServerError
 	}
 
 	return &model.AuthOutput{
-		User: &model.User{
-			ID:        user.ID.String(),
-			FirstName: user.FirstName,
-			LastName:  user.LastName,
-			Email:     user.Email,
-			CreatedAt: user.CreatedAt.Format(internalTime.DefaultFormat),
-			UpdatedAt: user.UpdatedAt.Format(internalTime.DefaultFormat),
-		},
-		Token: &model.Token{
-			Value: token,
-		},
+		User:  userToModel(user),
+		Token: sessionToModel(session),
 	}, nil
 }
 
 func (r *mutationResolver) Login(ctx context.Context, input model.LoginInput) (*model.AuthOutput, error) {
-	validationResult := r.validator.ValidateLoginInput(input)
+	err := r.limitByClientIP(ctx, "login", loginLimit, loginLimitWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	input.Email = entities.NormalizeEmail(input.Email)
+	validationResult := r.validator.ValidateLoginInput(ctx, input)
 	if validationResult.HasError {
 		return nil, validationResult.Error
 	}
 
+	invalidEmailOrPasswordError := func() error {
+		return validator.NewValidationResult(ctx, []validator.FieldError{
+			{Field: "email", Message: validator.ErrInvalidEmailOrPassword.Error()},
+			{Field: "password", Message: validator.ErrInvalidEmailOrPassword.Error()},
+		}).Error
+	}
+
 	user, err := r.db.UserRepository().FindByEmail(input.Email)
 	if err == database.ErrEntityNotFound {
-		return nil, validator.ErrInvalidEmailOrPassword
+		err = r.checkLockout(ctx, unknownLockoutAccount(input.Email))
+		if err != nil {
+			return nil, err
+		}
+		return nil, r.recordLoginFailure(ctx, unknownLockoutAccount(input.Email), invalidEmailOrPasswordError())
 	}
 	if err != nil {
 		r.errorHandler.CaptureError(ctx, pkgErrors.Wrap(err, "cannot find user by email"))
 		return nil, internalErrors.ErrInternalServerError
 	}
 
-	passwordIsValid := r.passwordService.CheckPasswordHash(input.Password, user.Password)
-	if !passwordIsValid {
-		graphql.AddError(ctx, &gqlerror.Error{
-			Message: validator.ErrInvalidEmailOrPassword.Error(),
-			Path:    append(graphql.GetFieldContext(ctx).Path(), ast.PathName("email")),
-		})
+	err = r.checkPassword(ctx, *user, input.Password)
+	if err == errWrongPassword {
+		return nil, invalidEmailOrPasswordError()
+	}
+	if err != nil {
+		return nil, err
+	}
 
-		graphql.AddError(ctx, &gqlerror.Error{
-			Message: validator.ErrInvalidEmailOrPassword.Error(),
-			Path:    append(graphql.GetFieldContext(ctx).Path(), ast.PathName("password")),
-		})
+	if user.TwoFactorEnabled() {
+		challenge, err := r.createTwoFactorChallenge(*user)
+		if err != nil {
+			r.errorHandler.CaptureError(ctx, err)
+			return nil, internalErrors.ErrInternalServerError
+		}
 
-		return nil, internalErrors.ErrValidationError
+		return &model.AuthOutput{
+			TwoFactorChallenge: challenge,
+		}, nil
 	}
 
-	token, err := r.jwtService.GenerateTokenForUserID(user.ID)
+	r.resetLockout(ctx, lockoutAccount(user.ID))
+
+	session, err := r.jwtService.CreateSession(user.ID)
 	if err != nil {
-		r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot generate jwt token for user with ID: %s", user.ID.String()))
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot create session for user with ID: %s", user.ID.String()))
 		return nil, internalErrors.ErrInternalServerError
 	}
 
 	return &model.AuthOutput{
-		User: &model.User{
-			ID:        user.ID.String(),
-			FirstName: user.FirstName,
-			LastName:  user.LastName,
-			Email:     user.Email,
-			CreatedAt: user.CreatedAt.Format(internalTime.DefaultFormat),
-			UpdatedAt: user.UpdatedAt.Format(internalTime.DefaultFormat),
-		},
-		Token: &model.Token{
-			Value: token,
-		},
+		User:  userToModel(*user),
+		Token: sessionToModel(session),
 	}, nil
 }
 
 func (r *mutationResolver) CancelToken(ctx context.Context, input model.CancelTokenInput) (bool, error) {
-	panic(fmt.Errorf("not implemented"))
+	err := r.jwtService.CancelToken(input.Token)
+	if err != nil {
+		return false, r.handleTokenError(ctx, err)
+	}
+
+	return true, nil
 }
 
-func (r *mutationResolver) RefreshToken(ctx context.Context, input model.RefreshTokenInput) (string, error) {
-	panic(fmt.Errorf("not implemented"))
+func (r *mutationResolver) RefreshToken(ctx context.Context, input model.RefreshTokenInput) (*model.Token, error) {
+	session, err := r.jwtService.RefreshSession(input.Token)
+	if err != nil {
+		return nil, r.handleTokenError(ctx, err)
+	}
+
+	return sessionToModel(session), nil
 }
 
 func (r *queryResolver) User(ctx context.Context) (*model.User, error) {
-	return &model.User{}, nil
+	user, err := r.authorizeUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return userToModel(*user), nil
 }
 
 // Mutation returns generated.MutationResolver implementation.
@@ -0,0 +1,35 @@
+package resolver
+
+import (
+	"context"
+
+	internalErrors "github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/errors"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/graph/model"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/services/jwt"
+	internalTime "github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/time"
+	pkgErrors "github.com/pkg/errors"
+)
+
+// sessionToModel converts the tokens of a session into the token returned by the API
+func sessionToModel(session jwt.Session) *model.Token {
+	return &model.Token{
+		Value:        session.AccessToken,
+		ExpiresAt:    session.AccessTokenExpiresAt.Format(internalTime.DefaultFormat),
+		RefreshToken: session.RefreshToken,
+	}
+}
+
+// handleTokenError converts an error from the jwt service into an error which can be returned to the user
+func (r *Resolver) handleTokenError(ctx context.Context, err error) error {
+	switch pkgErrors.Cause(err) {
+	case jwt.ErrRefreshTokenReused:
+		// a refresh token is only reused when it has been stolen so the revoked session is reported
+		r.errorHandler.CaptureError(ctx, err)
+		return internalErrors.ErrUnauthenticated
+	case jwt.ErrInvalidRefreshToken, jwt.ErrSessionRevoked, jwt.ErrInvalidToken, jwt.ErrTokenBlacklisted:
+		return internalErrors.ErrUnauthenticated
+	default:
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrap(err, "cannot use token"))
+		return internalErrors.ErrInternalServerError
+	}
+}
@@ -0,0 +1,73 @@
+package resolver
+
+import (
+	"context"
+	"time"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/entities"
+	internalErrors "github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/errors"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/graph/model"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/services/twofactor"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/services/verification"
+	internalTime "github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/time"
+	pkgErrors "github.com/pkg/errors"
+)
+
+const (
+	twoFactorChallengeExpiration = 5 * time.Minute
+	twoFactorLoginLimit          = 10
+	twoFactorLoginLimitWindow    = time.Minute
+)
+
+// errWrongTwoFactorCode is returned by checkTwoFactorCode when the code is neither a valid TOTP code nor a recovery code
+var errWrongTwoFactorCode = pkgErrors.New("the code is not correct")
+
+// createTwoFactorChallenge creates the token which is exchanged for a session with a two-factor code
+func (r *Resolver) createTwoFactorChallenge(user entities.User) (*model.TwoFactorChallenge, error) {
+	expiresAt := time.Now().Add(twoFactorChallengeExpiration)
+
+	token, err := r.verificationService.Create(verification.PurposeTwoFactorLogin, user.ID, twoFactorChallengeExpiration)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TwoFactorChallenge{
+		Token:     token,
+		ExpiresAt: expiresAt.Format(internalTime.DefaultFormat),
+	}, nil
+}
+
+// checkTwoFactorCode verifies a code of the authenticator app or a recovery code. Wrong codes count as failed logins
+// so the codes cannot be brute forced. A recovery code is removed from the user after it is used.
+func (r *Resolver) checkTwoFactorCode(ctx context.Context, user *entities.User, code string) error {
+	err := r.checkLockout(ctx, lockoutAccount(user.ID))
+	if err != nil {
+		return err
+	}
+
+	isValid, err := r.twoFactorService.VerifyCode(user.ID, user.TwoFactorSecret, code)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, err)
+		return internalErrors.ErrInternalServerError
+	}
+
+	if !isValid {
+		hash, isRecoveryCode := twofactor.MatchRecoveryCode(user.RecoveryCodes, code)
+		if !isRecoveryCode {
+			return r.recordLoginFailure(ctx, lockoutAccount(user.ID), errWrongTwoFactorCode)
+		}
+
+		isRemoved, err := r.db.UserRepository().RemoveRecoveryCode(user.ID, hash)
+		if err != nil {
+			r.errorHandler.CaptureError(ctx, err)
+			return internalErrors.ErrInternalServerError
+		}
+
+		// another request used the recovery code first
+		if !isRemoved {
+			return r.recordLoginFailure(ctx, lockoutAccount(user.ID), errWrongTwoFactorCode)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,195 @@
+package resolver
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"time"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/database"
+	internalErrors "github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/errors"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/graph/model"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/graph/validator"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/services/twofactor"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/services/verification"
+	pkgErrors "github.com/pkg/errors"
+)
+
+func (r *mutationResolver) EnrolTwoFactor(ctx context.Context) (*model.TwoFactorEnrolment, error) {
+	user, err := r.authorizeUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TwoFactorEnabled() {
+		return nil, internalErrors.ErrTwoFactorEnabled
+	}
+
+	enrolment, err := r.twoFactorService.StartEnrolment(user.ID, user.Email)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, err)
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	return &model.TwoFactorEnrolment{
+		Secret: enrolment.Secret,
+		URL:    enrolment.URL,
+	}, nil
+}
+
+func (r *mutationResolver) VerifyTwoFactor(ctx context.Context, input model.VerifyTwoFactorInput) ([]string, error) {
+	user, err := r.authorizeUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TwoFactorEnabled() {
+		return nil, internalErrors.ErrTwoFactorEnabled
+	}
+
+	secret, err := r.twoFactorService.PendingSecret(user.ID)
+	if err == twofactor.ErrNoEnrolment {
+		return nil, validator.NewValidationResult(ctx, []validator.FieldError{{Field: "code", Message: err.Error()}}).Error
+	}
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, err)
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	isValid, err := r.twoFactorService.VerifyCode(user.ID, secret, input.Code)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, err)
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	if !isValid {
+		return nil, validator.NewValidationResult(ctx, []validator.FieldError{{Field: "code", Message: errWrongTwoFactorCode.Error()}}).Error
+	}
+
+	codes, hashes, err := twofactor.GenerateRecoveryCodes()
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, err)
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	user.TwoFactorSecret = secret
+	user.RecoveryCodes = hashes
+	user.UpdatedAt = time.Now()
+
+	err = r.db.UserRepository().Update(*user)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot enable two-factor authentication for user with ID: %s", user.ID.String()))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	err = r.twoFactorService.FinishEnrolment(user.ID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, err)
+	}
+
+	return codes, nil
+}
+
+func (r *mutationResolver) DisableTwoFactor(ctx context.Context, input model.DisableTwoFactorInput) (bool, error) {
+	user, err := r.authorizeUser(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if !user.TwoFactorEnabled() {
+		return false, internalErrors.ErrTwoFactorDisabled
+	}
+
+	err = r.checkPassword(ctx, *user, input.Password)
+	if err == errWrongPassword {
+		return false, validator.NewValidationResult(ctx, []validator.FieldError{{Field: "password", Message: "the password is not correct"}}).Error
+	}
+	if err != nil {
+		return false, err
+	}
+
+	err = r.checkTwoFactorCode(ctx, user, input.Code)
+	if err == errWrongTwoFactorCode {
+		return false, validator.NewValidationResult(ctx, []validator.FieldError{{Field: "code", Message: err.Error()}}).Error
+	}
+	if err != nil {
+		return false, err
+	}
+
+	user.TwoFactorSecret = ""
+	user.RecoveryCodes = nil
+	user.UpdatedAt = time.Now()
+
+	err = r.db.UserRepository().Update(*user)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot disable two-factor authentication for user with ID: %s", user.ID.String()))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	return true, nil
+}
+
+func (r *mutationResolver) VerifyTwoFactorLogin(ctx context.Context, input model.VerifyTwoFactorLoginInput) (*model.AuthOutput, error) {
+	err := r.limitByClientIP(ctx, "two-factor-login", twoFactorLoginLimit, twoFactorLoginLimitWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	invalidChallengeError := func() error {
+		return validator.NewValidationResult(ctx, []validator.FieldError{{Field: "challengeToken", Message: "the login has expired, please log in again"}}).Error
+	}
+
+	// the challenge is only used after a valid code so a typo does not end the login
+	userID, err := r.verificationService.Find(verification.PurposeTwoFactorLogin, input.ChallengeToken)
+	if err == verification.ErrInvalidToken {
+		return nil, invalidChallengeError()
+	}
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, err)
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	user, err := r.db.UserRepository().FindByID(userID)
+	if err == database.ErrEntityNotFound {
+		return nil, invalidChallengeError()
+	}
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot find user with ID: %s", userID.String()))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	if !user.TwoFactorEnabled() {
+		return nil, invalidChallengeError()
+	}
+
+	err = r.checkTwoFactorCode(ctx, user, input.Code)
+	if err == errWrongTwoFactorCode {
+		return nil, validator.NewValidationResult(ctx, []validator.FieldError{{Field: "code", Message: err.Error()}}).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.verificationService.Consume(verification.PurposeTwoFactorLogin, input.ChallengeToken)
+	if err == verification.ErrInvalidToken {
+		return nil, invalidChallengeError()
+	}
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, err)
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	r.resetLockout(ctx, lockoutAccount(user.ID))
+
+	session, err := r.jwtService.CreateSession(user.ID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot create session for user with ID: %s", user.ID.String()))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	return &model.AuthOutput{
+		User:  userToModel(*user),
+		Token: sessionToModel(session),
+	}, nil
+}
@@ -0,0 +1,51 @@
+package resolver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/cache/memory"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/entities"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/services/ratelimit"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/services/twofactor"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+)
+
+func TestResolverCheckTwoFactorCodeUsesARecoveryCodeOnce(t *testing.T) {
+	codes, hashes, err := twofactor.GenerateRecoveryCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := entities.User{ID: id.New(), TwoFactorSecret: "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", RecoveryCodes: hashes}
+	users := &userRepository{users: map[id.ID]entities.User{user.ID: user}}
+	cache := memory.NewClient()
+	resolver := &Resolver{
+		db:               fakeDB{users: users},
+		errorHandler:     discardErrorHandler{},
+		twoFactorService: twofactor.NewService(cache),
+		lockout:          ratelimit.NewLockout(cache, ratelimit.LockoutOptions{MaxFailures: 5, BaseDelay: time.Second, MaxDelay: time.Minute}),
+	}
+
+	// both requests loaded the user before the code was used
+	firstRequestUser, secondRequestUser := user, user
+
+	err = resolver.checkTwoFactorCode(contextWithUser(&firstRequestUser), &firstRequestUser, codes[0])
+	if err != nil {
+		t.Fatalf("checkTwoFactorCode() with a recovery code error = %v", err)
+	}
+
+	err = resolver.checkTwoFactorCode(contextWithUser(&secondRequestUser), &secondRequestUser, codes[0])
+	if err != errWrongTwoFactorCode {
+		t.Errorf("checkTwoFactorCode() with a used recovery code error = %v, want %v", err, errWrongTwoFactorCode)
+	}
+
+	if remaining := users.users[user.ID].RecoveryCodes; len(remaining) != len(hashes)-1 {
+		t.Errorf("the user has %d recovery codes, want %d", len(remaining), len(hashes)-1)
+	}
+
+	err = resolver.checkTwoFactorCode(contextWithUser(&secondRequestUser), &secondRequestUser, codes[1])
+	if err != nil {
+		t.Errorf("checkTwoFactorCode() with another recovery code error = %v", err)
+	}
+}
@@ -0,0 +1,34 @@
+package resolver
+
+import (
+	"context"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/entities"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/graph/model"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/graph/validator"
+	internalTime "github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/time"
+)
+
+func userToModel(user entities.User) *model.User {
+	result := &model.User{
+		ID:               user.ID.String(),
+		FirstName:        user.FirstName,
+		LastName:         user.LastName,
+		Email:            user.Email,
+		TwoFactorEnabled: user.TwoFactorEnabled(),
+		CreatedAt:        user.CreatedAt.Format(internalTime.DefaultFormat),
+		UpdatedAt:        user.UpdatedAt.Format(internalTime.DefaultFormat),
+	}
+
+	if user.EmailVerifiedAt != nil {
+		emailVerifiedAt := user.EmailVerifiedAt.Format(internalTime.DefaultFormat)
+		result.EmailVerifiedAt = &emailVerifiedAt
+	}
+
+	return result
+}
+
+// emailTakenError is the validation error of an email address which another user has
+func emailTakenError(ctx context.Context) error {
+	return validator.NewValidationResult(ctx, []validator.FieldError{{Field: "email", Message: validator.ErrEmailTaken.Error()}}).Error
+}
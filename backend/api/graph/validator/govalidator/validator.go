@@ -1,27 +1,222 @@
 package govalidator
 
 import (
+	"context"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode"
+
 	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/database"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/entities"
 	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/graph/model"
 	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/graph/validator"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/services/recaptcha"
+	internalTime "github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/time"
+)
+
+const (
+	minPasswordLength = 8
+	// bcrypt ignores the bytes after the 72nd byte
+	maxPasswordLength = 72
+	maxNameLength     = 100
 )
 
 // GoValidator is a validator using the govalidator package
 type GoValidator struct {
-	db database.DB
+	db                database.DB
+	recaptchaVerifier recaptcha.Verifier
 }
 
 // New creates a new go validator
-func New(db database.DB) validator.Validator {
-	return &GoValidator{db}
+func New(db database.DB, recaptchaVerifier recaptcha.Verifier) validator.Validator {
+	return &GoValidator{db, recaptchaVerifier}
 }
 
 // ValidateCreateUserInput validates the create user input request
-func (validator GoValidator) ValidateCreateUserInput(input model.CreateUserInput) (result validator.ValidationResult) {
-	return result
+func (goValidator GoValidator) ValidateCreateUserInput(ctx context.Context, input model.CreateUserInput) validator.ValidationResult {
+	var fieldErrors []validator.FieldError
+	fieldErrors = append(fieldErrors, goValidator.validateName("firstName", "first name", input.FirstName)...)
+	fieldErrors = append(fieldErrors, goValidator.validateName("lastName", "last name", input.LastName)...)
+
+	emailErrors := goValidator.validateEmail(input.Email)
+	if len(emailErrors) == 0 {
+		emailErrors = goValidator.validateEmailIsUnique(input.Email)
+	}
+	fieldErrors = append(fieldErrors, emailErrors...)
+
+	fieldErrors = append(fieldErrors, goValidator.validatePasswordStrength(input.Password)...)
+	fieldErrors = append(fieldErrors, goValidator.validateRecaptcha(input.ReCaptcha)...)
+
+	return validator.NewValidationResult(ctx, fieldErrors)
 }
 
 // ValidateLoginInput validates the login input
-func (validator GoValidator) ValidateLoginInput(input model.LoginInput) (result validator.ValidationResult) {
-	return result
+func (goValidator GoValidator) ValidateLoginInput(ctx context.Context, input model.LoginInput) validator.ValidationResult {
+	var fieldErrors []validator.FieldError
+	fieldErrors = append(fieldErrors, goValidator.validateEmail(input.Email)...)
+
+	if input.Password == "" {
+		fieldErrors = append(fieldErrors, validator.FieldError{Field: "password", Message: "the password is required"})
+	}
+
+	fieldErrors = append(fieldErrors, goValidator.validateRecaptcha(input.ReCaptcha)...)
+
+	return validator.NewValidationResult(ctx, fieldErrors)
+}
+
+// ValidateImportCardInput validates the input for importing the transactions of an ov-chipkaart
+func (goValidator GoValidator) ValidateImportCardInput(ctx context.Context, input model.ImportCardInput) validator.ValidationResult {
+	var fieldErrors []validator.FieldError
+	if strings.TrimSpace(input.CardNumber) == "" {
+		fieldErrors = append(fieldErrors, validator.FieldError{Field: "cardNumber", Message: "the card number is required"})
+	}
+
+	if strings.TrimSpace(input.Username) == "" {
+		fieldErrors = append(fieldErrors, validator.FieldError{Field: "username", Message: "the username of the ov-chipkaart account is required"})
+	}
+
+	if input.Password == "" {
+		fieldErrors = append(fieldErrors, validator.FieldError{Field: "password", Message: "the password of the ov-chipkaart account is required"})
+	}
+
+	startDate, err := time.Parse(internalTime.DateFormat, input.StartDate)
+	if err != nil {
+		fieldErrors = append(fieldErrors, validator.FieldError{Field: "startDate", Message: "the start date must have the format YYYY-MM-DD"})
+	}
+
+	if input.EndDate != nil {
+		endDate, err := time.Parse(internalTime.DateFormat, *input.EndDate)
+		if err != nil {
+			fieldErrors = append(fieldErrors, validator.FieldError{Field: "endDate", Message: "the end date must have the format YYYY-MM-DD"})
+		} else if endDate.Before(startDate) {
+			fieldErrors = append(fieldErrors, validator.FieldError{Field: "endDate", Message: "the end date cannot be before the start date"})
+		}
+	}
+
+	return validator.NewValidationResult(ctx, fieldErrors)
+}
+
+// ValidateRequestPasswordResetInput validates the input for requesting a password reset email
+func (goValidator GoValidator) ValidateRequestPasswordResetInput(ctx context.Context, input model.RequestPasswordResetInput) validator.ValidationResult {
+	var fieldErrors []validator.FieldError
+	fieldErrors = append(fieldErrors, goValidator.validateEmail(input.Email)...)
+	fieldErrors = append(fieldErrors, goValidator.validateRecaptcha(input.ReCaptcha)...)
+
+	return validator.NewValidationResult(ctx, fieldErrors)
+}
+
+// ValidateResetPasswordInput validates the input for choosing a new password with a password reset token
+func (goValidator GoValidator) ValidateResetPasswordInput(ctx context.Context, input model.ResetPasswordInput) validator.ValidationResult {
+	var fieldErrors []validator.FieldError
+	if strings.TrimSpace(input.Token) == "" {
+		fieldErrors = append(fieldErrors, validator.FieldError{Field: "token", Message: "the password reset token is required"})
+	}
+
+	fieldErrors = append(fieldErrors, goValidator.validatePasswordStrength(input.Password)...)
+
+	return validator.NewValidationResult(ctx, fieldErrors)
+}
+
+// ValidateUpdateProfileInput validates the new profile of a user
+func (goValidator GoValidator) ValidateUpdateProfileInput(ctx context.Context, user entities.User, input model.UpdateProfileInput) validator.ValidationResult {
+	var fieldErrors []validator.FieldError
+	fieldErrors = append(fieldErrors, goValidator.validateName("firstName", "first name", input.FirstName)...)
+	fieldErrors = append(fieldErrors, goValidator.validateName("lastName", "last name", input.LastName)...)
+
+	emailErrors := goValidator.validateEmail(input.Email)
+	if len(emailErrors) == 0 && entities.NormalizeEmail(input.Email) != entities.NormalizeEmail(user.Email) {
+		emailErrors = goValidator.validateEmailIsUnique(input.Email)
+	}
+	fieldErrors = append(fieldErrors, emailErrors...)
+
+	return validator.NewValidationResult(ctx, fieldErrors)
+}
+
+// ValidateChangePasswordInput validates the new password of a user. The current password is checked by the resolver.
+func (goValidator GoValidator) ValidateChangePasswordInput(ctx context.Context, input model.ChangePasswordInput) validator.ValidationResult {
+	var fieldErrors []validator.FieldError
+	if input.CurrentPassword == "" {
+		fieldErrors = append(fieldErrors, validator.FieldError{Field: "currentPassword", Message: "the current password is required"})
+	}
+
+	for _, fieldError := range goValidator.validatePasswordStrength(input.NewPassword) {
+		fieldError.Field = "newPassword"
+		fieldErrors = append(fieldErrors, fieldError)
+	}
+
+	return validator.NewValidationResult(ctx, fieldErrors)
+}
+
+func (goValidator GoValidator) validateName(field string, label string, name string) []validator.FieldError {
+	if strings.TrimSpace(name) == "" {
+		return []validator.FieldError{{Field: field, Message: "the " + label + " is required"}}
+	}
+
+	if len([]rune(name)) > maxNameLength {
+		return []validator.FieldError{{Field: field, Message: "the " + label + " cannot be longer than 100 characters"}}
+	}
+
+	return nil
+}
+
+func (goValidator GoValidator) validateEmail(email string) []validator.FieldError {
+	if strings.TrimSpace(email) == "" {
+		return []validator.FieldError{{Field: "email", Message: "the email address is required"}}
+	}
+
+	// ParseAddress also accepts addresses with a name e.g "John <john@example.com>"
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email || !strings.Contains(address.Address[strings.LastIndex(address.Address, "@"):], ".") {
+		return []validator.FieldError{{Field: "email", Message: "the email address is not valid"}}
+	}
+
+	return nil
+}
+
+func (goValidator GoValidator) validateEmailIsUnique(email string) []validator.FieldError {
+	_, err := goValidator.db.UserRepository().FindByEmail(entities.NormalizeEmail(email))
+	if err == database.ErrEntityNotFound {
+		return nil
+	}
+	if err != nil {
+		return []validator.FieldError{{Field: "email", Message: "the email address cannot be checked right now, please try again later"}}
+	}
+
+	return []validator.FieldError{{Field: "email", Message: validator.ErrEmailTaken.Error()}}
+}
+
+func (goValidator GoValidator) validatePasswordStrength(password string) []validator.FieldError {
+	if len(password) < minPasswordLength {
+		return []validator.FieldError{{Field: "password", Message: "the password must contain at least 8 characters"}}
+	}
+
+	if len(password) > maxPasswordLength {
+		return []validator.FieldError{{Field: "password", Message: "the password cannot be longer than 72 characters"}}
+	}
+
+	var hasLetter, hasDigit bool
+	for _, character := range password {
+		hasLetter = hasLetter || unicode.IsLetter(character)
+		hasDigit = hasDigit || unicode.IsDigit(character)
+	}
+
+	if !hasLetter || !hasDigit {
+		return []validator.FieldError{{Field: "password", Message: "the password must contain at least one letter and one number"}}
+	}
+
+	return nil
+}
+
+func (goValidator GoValidator) validateRecaptcha(token string) []validator.FieldError {
+	isValid, err := goValidator.recaptchaVerifier.Verify(token)
+	if err != nil {
+		return []validator.FieldError{{Field: "reCaptcha", Message: "the reCAPTCHA cannot be verified right now, please try again later"}}
+	}
+
+	if !isValid {
+		return []validator.FieldError{{Field: "reCaptcha", Message: "the reCAPTCHA is not valid, please try again"}}
+	}
+
+	return nil
 }
@@ -0,0 +1,168 @@
+package govalidator
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/database"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/entities"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/graph/model"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/graph/validator"
+	"github.com/pkg/errors"
+)
+
+// usersDB is a database where only the users with the emails exist. FindByEmail fails with err when it is set.
+type usersDB struct {
+	database.DB
+	emails []string
+	err    error
+}
+
+func (db usersDB) UserRepository() database.UserRepository {
+	return usersRepository{db: db}
+}
+
+type usersRepository struct {
+	database.UserRepository
+	db usersDB
+}
+
+func (repository usersRepository) FindByEmail(email string) (*entities.User, error) {
+	if repository.db.err != nil {
+		return nil, repository.db.err
+	}
+
+	for _, stored := range repository.db.emails {
+		if stored == email {
+			return &entities.User{Email: email}, nil
+		}
+	}
+	return nil, database.ErrEntityNotFound
+}
+
+// tokenVerifier accepts the reCAPTCHA token "solved" and fails with err when it is set
+type tokenVerifier struct {
+	err error
+}
+
+func (verifier tokenVerifier) Verify(token string) (bool, error) {
+	return token == "solved", verifier.err
+}
+
+// assertFieldErrors checks that the result only has errors for the fields
+func assertFieldErrors(t *testing.T, result validator.ValidationResult, fields []string) {
+	if result.HasError != (len(fields) > 0) {
+		t.Fatalf("HasError = %t with error %v, want errors for %v", result.HasError, result.Error, fields)
+	}
+
+	if !result.HasError {
+		return
+	}
+
+	if count := strings.Count(result.Error.Error(), ": "); count != len(fields)+1 {
+		t.Errorf("error = %s, want %d field errors", result.Error.Error(), len(fields))
+	}
+
+	for _, field := range fields {
+		if !strings.Contains(result.Error.Error(), field+": ") {
+			t.Errorf("error = %s, want an error for %s", result.Error.Error(), field)
+		}
+	}
+}
+
+func TestGoValidatorValidateCreateUserInput(t *testing.T) {
+	valid := model.CreateUserInput{FirstName: "Anne", LastName: "de Vries", Email: "anne@example.com", Password: "password1", ReCaptcha: "solved"}
+
+	tests := []struct {
+		name   string
+		update func(input *model.CreateUserInput)
+		db     usersDB
+		err    error
+		fields []string
+	}{
+		{name: "valid input", update: func(input *model.CreateUserInput) {}},
+		{name: "missing names", update: func(input *model.CreateUserInput) { input.FirstName, input.LastName = " ", "" }, fields: []string{"firstName", "lastName"}},
+		{name: "long name", update: func(input *model.CreateUserInput) { input.LastName = strings.Repeat("a", 101) }, fields: []string{"lastName"}},
+		{name: "email with a name", update: func(input *model.CreateUserInput) { input.Email = "Anne <anne@example.com>" }, fields: []string{"email"}},
+		{name: "email without a domain", update: func(input *model.CreateUserInput) { input.Email = "anne@localhost" }, fields: []string{"email"}},
+		{name: "email which is taken", update: func(input *model.CreateUserInput) {}, db: usersDB{emails: []string{"anne@example.com"}}, fields: []string{"email"}},
+		{name: "email which is taken in another case", update: func(input *model.CreateUserInput) { input.Email = "Anne@Example.com" }, db: usersDB{emails: []string{"anne@example.com"}}, fields: []string{"email"}},
+		{name: "email which cannot be checked", update: func(input *model.CreateUserInput) {}, db: usersDB{err: errors.New("timeout")}, fields: []string{"email"}},
+		{name: "short password", update: func(input *model.CreateUserInput) { input.Password = "pass1" }, fields: []string{"password"}},
+		{name: "long password", update: func(input *model.CreateUserInput) { input.Password = strings.Repeat("a1", 37) }, fields: []string{"password"}},
+		{name: "password without a number", update: func(input *model.CreateUserInput) { input.Password = "password" }, fields: []string{"password"}},
+		{name: "wrong reCAPTCHA", update: func(input *model.CreateUserInput) { input.ReCaptcha = "wrong" }, fields: []string{"reCaptcha"}},
+		{name: "reCAPTCHA which cannot be verified", update: func(input *model.CreateUserInput) {}, err: errors.New("timeout"), fields: []string{"reCaptcha"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			input := valid
+			test.update(&input)
+
+			result := New(test.db, tokenVerifier{test.err}).ValidateCreateUserInput(context.Background(), input)
+			assertFieldErrors(t, result, test.fields)
+		})
+	}
+}
+
+func TestGoValidatorValidateImportCardInput(t *testing.T) {
+	date := func(value string) *string { return &value }
+
+	tests := []struct {
+		name   string
+		input  model.ImportCardInput
+		fields []string
+	}{
+		{
+			name:  "valid input",
+			input: model.ImportCardInput{CardNumber: "3528", Username: "anne", Password: "secret", StartDate: "2020-01-01", EndDate: date("2020-02-01")},
+		},
+		{
+			name:   "missing account",
+			input:  model.ImportCardInput{StartDate: "2020-01-01"},
+			fields: []string{"cardNumber", "username", "password"},
+		},
+		{
+			name:   "invalid dates",
+			input:  model.ImportCardInput{CardNumber: "3528", Username: "anne", Password: "secret", StartDate: "01-01-2020", EndDate: date("2020-13-01")},
+			fields: []string{"startDate", "endDate"},
+		},
+		{
+			name:   "end date before the start date",
+			input:  model.ImportCardInput{CardNumber: "3528", Username: "anne", Password: "secret", StartDate: "2020-02-01", EndDate: date("2020-01-01")},
+			fields: []string{"endDate"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := New(usersDB{}, tokenVerifier{}).ValidateImportCardInput(context.Background(), test.input)
+			assertFieldErrors(t, result, test.fields)
+		})
+	}
+}
+
+func TestGoValidatorValidateUpdateProfileInput(t *testing.T) {
+	user := entities.User{Email: "anne@example.com"}
+	db := usersDB{emails: []string{"anne@example.com", "bob@example.com"}}
+
+	tests := []struct {
+		name   string
+		email  string
+		fields []string
+	}{
+		{name: "the same email", email: "anne@example.com"},
+		{name: "a new email", email: "anne@example.org"},
+		{name: "the email of another user", email: "bob@example.com", fields: []string{"email"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			input := model.UpdateProfileInput{FirstName: "Anne", LastName: "de Vries", Email: test.email}
+			result := New(db, tokenVerifier{}).ValidateUpdateProfileInput(context.Background(), user, input)
+			assertFieldErrors(t, result, test.fields)
+		})
+	}
+}
@@ -1,13 +1,24 @@
 package validator
 
 import (
+	"context"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/entities"
+	internalErrors "github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/errors"
 	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/graph/model"
 	"github.com/pkg/errors"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
 )
 
 var (
 	//ErrInvalidEmailOrPassword is thrown when the user's email/password is wrong.
 	ErrInvalidEmailOrPassword = errors.New("invalid email or password")
+
+	// ErrEmailTaken is thrown when another user has the email address
+	ErrEmailTaken = errors.New("an account with this email address already exists")
 )
 
 // ValidationResult stores the result of a validation
@@ -16,8 +27,53 @@ type ValidationResult struct {
 	Error    error
 }
 
+// FieldError is the validation message of an input field
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// NewValidationResult creates the result of a validation. Every field error is added to the GraphQL response with the
+// path of the field so the client can show the message next to the input.
+func NewValidationResult(ctx context.Context, fieldErrors []FieldError) (result ValidationResult) {
+	if len(fieldErrors) == 0 {
+		return result
+	}
+
+	result.HasError = true
+	result.Error = internalErrors.ErrValidationError
+
+	// the validator is not called by a resolver e.g in tests
+	if graphql.GetFieldContext(ctx) == nil {
+		var messages []string
+		for _, fieldError := range fieldErrors {
+			messages = append(messages, fieldError.Field+": "+fieldError.Message)
+		}
+		result.Error = errors.Wrap(internalErrors.ErrValidationError, strings.Join(messages, ", "))
+		return result
+	}
+
+	for _, fieldError := range fieldErrors {
+		graphql.AddError(ctx, &gqlerror.Error{
+			Message: fieldError.Message,
+			Path:    append(graphql.GetFieldContext(ctx).Path(), ast.PathName(fieldError.Field)),
+			Extensions: map[string]interface{}{
+				"code":  internalErrors.CodeValidation,
+				"field": fieldError.Field,
+			},
+		})
+	}
+
+	return result
+}
+
 // Validator represents a validator
 type Validator interface {
-	ValidateCreateUserInput(input model.CreateUserInput) ValidationResult
-	ValidateLoginInput(input model.LoginInput) ValidationResult
+	ValidateCreateUserInput(ctx context.Context, input model.CreateUserInput) ValidationResult
+	ValidateLoginInput(ctx context.Context, input model.LoginInput) ValidationResult
+	ValidateImportCardInput(ctx context.Context, input model.ImportCardInput) ValidationResult
+	ValidateRequestPasswordResetInput(ctx context.Context, input model.RequestPasswordResetInput) ValidationResult
+	ValidateResetPasswordInput(ctx context.Context, input model.ResetPasswordInput) ValidationResult
+	ValidateUpdateProfileInput(ctx context.Context, user entities.User, input model.UpdateProfileInput) ValidationResult
+	ValidateChangePasswordInput(ctx context.Context, input model.ChangePasswordInput) ValidationResult
 }
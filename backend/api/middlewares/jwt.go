@@ -3,8 +3,10 @@ package middlewares
 import (
 	"context"
 	"net/http"
+	"strings"
 
 	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/services/jwt"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/tracing"
 )
 
 // ContextKey is the key for the context value
@@ -13,6 +15,9 @@ type ContextKey string
 const (
 	// KeyUserID is the id for the context key
 	KeyUserID = ContextKey("user-id")
+
+	// bearerPrefix is the authentication scheme of the access token in the Authorization header
+	bearerPrefix = "Bearer "
 )
 
 // EnrichUserID adds the user id to the context
@@ -22,21 +27,24 @@ func EnrichUserID(jwtService jwt.Service) func(http.Handler) http.Handler {
 			header := r.Header.Get("Authorization")
 			//
 			// Allow unauthenticated users in
-			if header == "" {
+			if len(header) <= len(bearerPrefix) || !strings.EqualFold(header[:len(bearerPrefix)], bearerPrefix) {
 				next.ServeHTTP(w, r)
 				return
 			}
 
 			//validate jwt token
-			tokenString := header
+			tokenString := strings.TrimSpace(header[len(bearerPrefix):])
 			userID, err := jwtService.GetUserIDFromToken(tokenString)
+			//
+			// Expired access tokens are let in as unauthenticated so the refreshToken and login mutations still work
 			if err != nil {
-				http.Error(w, "Invalid token", http.StatusForbidden)
+				next.ServeHTTP(w, r)
 				return
 			}
 
 			// put it in context
 			ctx := context.WithValue(r.Context(), KeyUserID, userID)
+			tracing.SetUserID(ctx, userID.String())
 
 			// and call the next with our new context
 			r = r.WithContext(ctx)
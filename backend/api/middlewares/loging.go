@@ -8,6 +8,7 @@ import (
 	internalTime "github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/time"
 
 	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/logger"
+	"github.com/gorilla/mux"
 )
 
 // responseWriter is a minimal wrapper for http.ResponseWriter that allows the
@@ -40,6 +41,17 @@ func (rw *responseWriter) WriteHeader(code int) {
 	return
 }
 
+// routePath returns the template of the route which matched the request e.g "/exports/{token}" so that the secrets in
+// the path of a request are never logged or traced. The path of a request which did not match a route is returned as is.
+func routePath(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if template, err := route.GetPathTemplate(); err == nil {
+			return template
+		}
+	}
+	return r.URL.EscapedPath()
+}
+
 // LoggingMiddleware logs the incoming HTTP request & its duration.
 func LoggingMiddleware(logger logger.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -47,7 +59,8 @@ func LoggingMiddleware(logger logger.Logger) func(http.Handler) http.Handler {
 			defer func() {
 				if err := recover(); err != nil {
 					w.WriteHeader(http.StatusInternalServerError)
-					_ = logger.Log(
+					_ = logger.LogContext(
+						r.Context(),
 						"err", err,
 						"trace", debug.Stack(),
 					)
@@ -57,11 +70,12 @@ func LoggingMiddleware(logger logger.Logger) func(http.Handler) http.Handler {
 			start := time.Now()
 			wrapped := wrapResponseWriter(w)
 			next.ServeHTTP(wrapped, r)
-			_ = logger.Log(
+			_ = logger.LogContext(
+				r.Context(),
 				"at", time.Now().Format(internalTime.DefaultFormat),
 				"status", wrapped.status,
 				"method", r.Method,
-				"path", r.URL.EscapedPath(),
+				"path", routePath(r),
 				"duration", time.Since(start),
 			)
 		}
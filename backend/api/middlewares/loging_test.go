@@ -0,0 +1,60 @@
+package middlewares
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+// recordingLogger keeps the logged key values as text
+type recordingLogger struct {
+	lines []string
+}
+
+func (logger *recordingLogger) Log(keyVals ...interface{}) error {
+	logger.lines = append(logger.lines, fmt.Sprint(keyVals...))
+	return nil
+}
+
+func (logger *recordingLogger) LogContext(ctx context.Context, keyVals ...interface{}) error {
+	return logger.Log(keyVals...)
+}
+
+func TestLoggingMiddlewareDoesNotLogPathParameters(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		path   string
+		secret string
+	}{
+		{name: "data export token", target: "/exports/secret-token", path: "/exports/{token}", secret: "secret-token"},
+		{name: "route without parameters", target: "/query", path: "/query"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			logger := &recordingLogger{}
+			router := mux.NewRouter()
+			router.Use(LoggingMiddleware(logger))
+			router.HandleFunc("/exports/{token}", func(w http.ResponseWriter, r *http.Request) {})
+			router.HandleFunc("/query", func(w http.ResponseWriter, r *http.Request) {})
+
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, test.target, nil))
+
+			if len(logger.lines) != 1 {
+				t.Fatalf("logged %d lines, want 1", len(logger.lines))
+			}
+			if !strings.Contains(logger.lines[0], "path"+test.path) {
+				t.Errorf("log = %q, want the path %s", logger.lines[0], test.path)
+			}
+			if test.secret != "" && strings.Contains(logger.lines[0], test.secret) {
+				t.Errorf("log = %q contains %s", logger.lines[0], test.secret)
+			}
+		})
+	}
+}
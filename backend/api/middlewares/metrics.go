@@ -0,0 +1,52 @@
+package middlewares
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/metrics"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// GraphQLMetrics records the duration and the error codes of the GraphQL operations
+func GraphQLMetrics(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	start := time.Now()
+	response := next(ctx)
+
+	var errorCodes []string
+	if response != nil {
+		for _, err := range response.Errors {
+			code, ok := err.Extensions["code"].(string)
+			if !ok {
+				code = "UNKNOWN"
+			}
+			errorCodes = append(errorCodes, code)
+		}
+	}
+
+	metrics.ObserveGraphQLOperation(metricsOperationName(ctx), time.Since(start), errorCodes)
+	return response
+}
+
+// metricsOperationName names an operation after its root fields e.g "mutation login". The operation names which are
+// chosen by the clients are not used because every new name would create a new time series.
+func metricsOperationName(ctx context.Context) string {
+	if !graphql.HasOperationContext(ctx) || graphql.GetOperationContext(ctx).Operation == nil {
+		return "unknown"
+	}
+
+	operation := graphql.GetOperationContext(ctx).Operation
+
+	var fields []string
+	for _, selection := range operation.SelectionSet {
+		if field, ok := selection.(*ast.Field); ok {
+			fields = append(fields, field.Name)
+		}
+	}
+	sort.Strings(fields)
+
+	return string(operation.Operation) + " " + strings.Join(fields, ",")
+}
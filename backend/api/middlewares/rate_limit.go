@@ -0,0 +1,92 @@
+package middlewares
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	internalErrors "github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/errors"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/services/ratelimit"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/logger"
+)
+
+const (
+	// KeyClientIP is the context key of the IP address of the client
+	KeyClientIP = ContextKey("client-ip")
+)
+
+// RateLimitOptions are the settings of the rate limit middleware
+type RateLimitOptions struct {
+	// Limit is the number of requests a client can send in a window
+	Limit  int
+	Window time.Duration
+	// TrustProxy uses the last address in the X-Forwarded-For header as the IP address of the client.
+	// It must only be enabled when the API is behind a proxy which appends the address of the client to the header.
+	TrustProxy bool
+}
+
+// RateLimitMiddleware limits the number of requests per IP address and adds the IP address to the context
+func RateLimitMiddleware(limiter ratelimit.Limiter, logger logger.Logger, options RateLimitOptions) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			clientIP := clientIP(r, options.TrustProxy)
+			r = r.WithContext(context.WithValue(r.Context(), KeyClientIP, clientIP))
+
+			result, err := limiter.Allow("ip:"+clientIP, options.Limit, options.Window)
+
+			// Requests are allowed when the cache is down so the API stays available
+			if err != nil {
+				_ = logger.LogContext(r.Context(), "err", err.Error(), "client_ip", clientIP)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !result.Allowed {
+				writeRateLimitError(w, internalErrors.NewRateLimitError(result.RetryAfter))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// writeRateLimitError responds with a GraphQL error so clients can handle it like the other errors
+func writeRateLimitError(w http.ResponseWriter, err internalErrors.RateLimitError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", strconv.Itoa(err.RetryAfterSeconds()))
+	w.WriteHeader(http.StatusTooManyRequests)
+
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"errors": []map[string]interface{}{{
+			"message":    err.Error(),
+			"extensions": err.Extensions(),
+		}},
+		"data": nil,
+	})
+}
+
+// clientIP returns the IP address of the client. Behind a proxy it is the last address in X-Forwarded-For because
+// the proxy appends it, the addresses before it are sent by the client and can be spoofed.
+func clientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if values := r.Header.Values("X-Forwarded-For"); len(values) > 0 {
+			addresses := strings.Split(values[len(values)-1], ",")
+			if address := strings.TrimSpace(addresses[len(addresses)-1]); address != "" {
+				return address
+			}
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,35 @@
+package middlewares
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name         string
+		forwardedFor []string
+		trustProxy   bool
+		ip           string
+	}{
+		{name: "without a proxy", forwardedFor: []string{"10.0.0.1"}, ip: "192.0.2.1"},
+		{name: "behind a proxy", forwardedFor: []string{"203.0.113.7"}, trustProxy: true, ip: "203.0.113.7"},
+		{name: "spoofed addresses", forwardedFor: []string{"10.0.0.1, 10.0.0.2, 203.0.113.7"}, trustProxy: true, ip: "203.0.113.7"},
+		{name: "spoofed header", forwardedFor: []string{"10.0.0.1", "203.0.113.7"}, trustProxy: true, ip: "203.0.113.7"},
+		{name: "empty header", forwardedFor: []string{""}, trustProxy: true, ip: "192.0.2.1"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest("POST", "/query", nil)
+			request.RemoteAddr = "192.0.2.1:1234"
+			for _, value := range test.forwardedFor {
+				request.Header.Add("X-Forwarded-For", value)
+			}
+
+			if ip := clientIP(request, test.trustProxy); ip != test.ip {
+				t.Errorf("clientIP() = %s, want %s", ip, test.ip)
+			}
+		})
+	}
+}
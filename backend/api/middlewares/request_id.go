@@ -0,0 +1,34 @@
+package middlewares
+
+import (
+	"net/http"
+	"regexp"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/tracing"
+)
+
+// HeaderRequestID is the header with the ID of a request. A proxy in front of the API can set the ID so its logs can be
+// correlated with the logs of the API.
+const HeaderRequestID = "X-Request-ID"
+
+// validRequestID prevents clients from injecting arbitrary values into the logs
+var validRequestID = regexp.MustCompile(`^[a-zA-Z0-9\-_]{1,64}$`)
+
+// RequestIDMiddleware adds the ID of the request to the context and to the response.
+// It must be the first middleware so the other middlewares can use the ID.
+func RequestIDMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			requestID := r.Header.Get(HeaderRequestID)
+			if !validRequestID.MatchString(requestID) {
+				requestID = id.New().String()
+			}
+
+			w.Header().Set(HeaderRequestID, requestID)
+			next.ServeHTTP(w, r.WithContext(tracing.WithRequest(r.Context(), requestID)))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,65 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/tracing"
+	"go.opentelemetry.io/otel/api/kv"
+)
+
+// TracingMiddleware starts a span for every HTTP request, the spans of the resolvers and the database are its children
+func TracingMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			path := routePath(r)
+			ctx, span := tracing.StartSpan(
+				r.Context(),
+				"HTTP "+r.Method+" "+path,
+				kv.String("http.method", r.Method),
+				kv.String("http.target", path),
+			)
+
+			wrapped := wrapResponseWriter(w)
+			next.ServeHTTP(wrapped, r.WithContext(ctx))
+
+			span.SetAttributes(kv.Int("http.status_code", wrapped.Status()))
+			tracing.EndSpan(ctx, span, nil)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// GraphQLOperationName adds the name of the GraphQL operation to the request so it is attached to the logs and the errors
+func GraphQLOperationName(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	operationContext := graphql.GetOperationContext(ctx)
+
+	operationName := operationContext.OperationName
+	if operationName == "" && operationContext.Operation != nil {
+		operationName = operationContext.Operation.Name
+	}
+	tracing.SetOperationName(ctx, operationName)
+
+	return next(ctx)
+}
+
+// GraphQLResolverSpans starts a span for every resolver. Fields which are read from a struct are not traced.
+func GraphQLResolverSpans(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	fieldContext := graphql.GetFieldContext(ctx)
+	if !fieldContext.IsMethod {
+		return next(ctx)
+	}
+
+	ctx, span := tracing.StartSpan(
+		ctx,
+		"graphql.resolve "+fieldContext.Object+"."+fieldContext.Field.Name,
+		kv.String("graphql.path", fieldContext.Path().String()),
+	)
+
+	result, err := next(ctx)
+	tracing.EndSpan(ctx, span, err)
+
+	return result, err
+}
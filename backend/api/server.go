@@ -2,9 +2,11 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/middlewares"
@@ -14,32 +16,55 @@ import (
 
 	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/graph/validator"
 	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/graph/validator/govalidator"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/services/dataexport"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/services/health"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/services/mailer"
 	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/services/password"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/services/recaptcha"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/services/verification"
 	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/errorhandler"
-	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/logger"
-	"github.com/getsentry/sentry-go"
-
-	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/cache"
-	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/cache/redis"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/metrics"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/tracing"
 
 	"github.com/99designs/gqlgen/graphql/handler"
-	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/database"
-	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/database/mongodb"
+	internalErrors "github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/errors"
 	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/graph/generated"
 	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/graph/resolver"
 	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/services/jwt"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/services/ratelimit"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/services/twofactor"
 	"github.com/joho/godotenv"
 	"github.com/pkg/errors"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-const defaultPort = "8080"
+const (
+	defaultPort                       = "8080"
+	defaultRateLimitRequestsPerMinute = 300
+	defaultLoginMaxFailures           = 5
+
+	// the first connection to mongodb is made by the startup check so it gets more time than the readiness check
+	startupCheckTimeout   = 10 * time.Second
+	readinessCheckTimeout = 2 * time.Second
+)
 
 func main() {
-	err := godotenv.Load()
+	if err := run(); err != nil {
+		log.Printf("the API stopped with an error: %s", err.Error())
+		os.Exit(1)
+	}
+}
+
+// run serves the API until the process receives SIGINT or SIGTERM and closes the connections before it returns. It
+// returns an error when the API cannot start e.g when a dependency is not reachable.
+func run() (err error) {
+	err = godotenv.Load()
 	if err != nil {
-		log.Fatal("error loading .env file")
+		return errors.Wrap(err, "cannot load the .env file")
+	}
+
+	err = tracing.Install(os.Getenv("TRACING_EXPORTER"))
+	if err != nil {
+		return err
 	}
 
 	port := os.Getenv("PORT")
@@ -47,94 +72,262 @@ func main() {
 		port = defaultPort
 	}
 
+	app, err := newApplication()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := app.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+
+	report := health.NewChecker(startupCheckTimeout, healthDependencies(app)...).Check(context.Background())
+	if !report.IsOK() {
+		return errors.Errorf("dependencies are not available: %s", report.Failures())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), startupCheckTimeout)
+	err = app.db.CreateIndexes(ctx)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	router, err := initializeRouter(app)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("connect to http://localhost:%s/ for GraphQL playground", port)
+	return app.serve(&http.Server{Addr: ":" + port, Handler: router})
+}
+
+func initializeRouter(app *application) (*mux.Router, error) {
+	rateLimitOptions, err := initializeRateLimitOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	jwtService, err := initializeJWTService(app)
+	if err != nil {
+		return nil, err
+	}
+
+	graphQLServer, err := initializeGraphQLServer(app, jwtService)
+	if err != nil {
+		return nil, err
+	}
+
 	router := mux.NewRouter()
 
-	router.Use(middlewares.LoggingMiddleware(initializeLogger()))
-	router.Use(middlewares.EnrichUserID(initializeJWTService()))
+	router.Use(middlewares.RequestIDMiddleware())
+	router.Use(middlewares.TracingMiddleware())
+	router.Use(middlewares.LoggingMiddleware(app.logger))
+	router.Use(middlewares.RateLimitMiddleware(ratelimit.NewLimiter(app.cache), app.logger, rateLimitOptions))
+	router.Use(middlewares.EnrichUserID(jwtService))
 
 	router.HandleFunc("/", playground.Handler("GraphQL playground", "/query"))
-	router.Handle("/query", initializeGraphQLServer())
+	router.HandleFunc("/healthz", health.LivenessHandler()).Methods(http.MethodGet)
+	router.HandleFunc("/readyz", health.ReadinessHandler(health.NewChecker(readinessCheckTimeout, healthDependencies(app)...))).Methods(http.MethodGet)
+	router.Handle("/metrics", metrics.Handler()).Methods(http.MethodGet)
+	router.HandleFunc("/.well-known/jwks.json", jwksHandler(jwtService))
+	router.HandleFunc(resolver.DataExportPath+"{token}", dataExportHandler(initializeDataExportService(app), app.errorHandler)).Methods(http.MethodGet)
+	router.Handle("/query", graphQLServer)
 
-	log.Printf("connect to http://localhost:%s/ for GraphQL playground", port)
-	log.Fatal(http.ListenAndServe(":"+port, router))
+	return router, nil
+}
+
+// healthDependencies are the services which are pinged by the readiness check
+func healthDependencies(app *application) []health.Dependency {
+	return []health.Dependency{
+		{Name: "mongodb", Ping: app.db.Ping},
+		{Name: "redis", Ping: app.cache.Ping},
+	}
 }
 
-func initializeGraphQLServer() *handler.Server {
-	return handler.NewDefaultServer(
+// jwksHandler publishes the public keys which verify the access tokens
+func jwksHandler(jwtService jwt.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		_ = json.NewEncoder(w).Encode(jwtService.JWKS())
+	}
+}
+
+func initializeGraphQLServer(app *application, jwtService jwt.Service) (*handler.Server, error) {
+	resolvers, err := initializeResolver(app, jwtService)
+	if err != nil {
+		return nil, err
+	}
+
+	server := handler.NewDefaultServer(
 		generated.NewExecutableSchema(
 			generated.Config{
-				Resolvers: initializeResolver(),
+				Resolvers: resolvers,
+				Directives: generated.DirectiveRoot{
+					Authenticated: resolvers.Authenticated,
+				},
 			},
 		),
 	)
+
+	server.AroundOperations(middlewares.GraphQLOperationName)
+	server.AroundFields(middlewares.GraphQLResolverSpans)
+	server.AroundResponses(middlewares.GraphQLMetrics)
+
+	server.SetErrorPresenter(internalErrors.NewPresenter(app.errorHandler))
+	server.SetRecoverFunc(internalErrors.NewRecoverFunc(app.errorHandler))
+
+	return server, nil
 }
-func initializeResolver() *resolver.Resolver {
+
+func initializeResolver(app *application, jwtService jwt.Service) (*resolver.Resolver, error) {
+	emailMailer, err := initializeMailer(app)
+	if err != nil {
+		return nil, err
+	}
+
+	lockout, err := initializeLockout(app)
+	if err != nil {
+		return nil, err
+	}
+
 	return resolver.NewResolver(
-		initializeDB(),
-		initializeValidator(),
+		app.db,
+		initializeValidator(app),
 		initializePasswordService(),
-		initializeErrorHandler(),
-		initializeLogger(),
-		initializeJWTService(),
-	)
+		app.errorHandler,
+		app.logger,
+		jwtService,
+		app.cache,
+		emailMailer,
+		verification.NewService(app.cache),
+		initializeDataExportService(app),
+		ratelimit.NewLimiter(app.cache),
+		lockout,
+		twofactor.NewService(app.cache),
+		os.Getenv("FRONTEND_URL"),
+	), nil
 }
 
-func initializeValidator() validator.Validator {
-	return govalidator.New(initializeDB())
-}
+func initializeRateLimitOptions() (middlewares.RateLimitOptions, error) {
+	options := middlewares.RateLimitOptions{
+		Limit:      defaultRateLimitRequestsPerMinute,
+		Window:     time.Minute,
+		TrustProxy: os.Getenv("RATE_LIMIT_TRUST_PROXY") == "true",
+	}
 
-func initializePasswordService() password.Service {
-	return password.NewBcryptService()
+	if value := os.Getenv("RATE_LIMIT_REQUESTS_PER_MINUTE"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			return options, errors.New("RATE_LIMIT_REQUESTS_PER_MINUTE must be a number >= 1")
+		}
+		options.Limit = limit
+	}
+
+	return options, nil
 }
 
-func initializeJWTService() jwt.Service {
-	sessionDays, err := strconv.Atoi(os.Getenv("AUTH_SESSION_DAYS"))
-	if err != nil {
-		log.Fatal(err.Error())
+func initializeLockout(app *application) (ratelimit.Lockout, error) {
+	options := ratelimit.LockoutOptions{
+		MaxFailures: defaultLoginMaxFailures,
+		BaseDelay:   time.Minute,
+		MaxDelay:    time.Hour,
 	}
 
-	if sessionDays < 1 {
-		log.Fatal("AUTH_SESSION_DAYS cannot be < 1")
+	if value := os.Getenv("LOGIN_MAX_FAILURES"); value != "" {
+		maxFailures, err := strconv.Atoi(value)
+		if err != nil || maxFailures < 1 {
+			return ratelimit.Lockout{}, errors.New("LOGIN_MAX_FAILURES must be a number >= 1")
+		}
+		options.MaxFailures = maxFailures
 	}
 
-	return jwt.NewService(os.Getenv("JWT_SECRET"), initializeCache(), sessionDays)
+	return ratelimit.NewLockout(app.cache, options), nil
 }
 
-func initializeDB() database.DB {
-	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(os.Getenv("MONGODB_URI")))
-	if err != nil {
-		log.Fatal(errors.Wrapf(err, "cannot connect to mongoDB"))
+func initializeDataExportService(app *application) dataexport.Service {
+	return dataexport.NewService(app.db, app.cache)
+}
+
+// dataExportHandler sends the archive which was created by the exportMyData mutation
+func dataExportHandler(service dataexport.Service, errorHandler errorhandler.ErrorHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		archive, err := service.Download(mux.Vars(r)["token"])
+		if err == dataexport.ErrExportNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			errorHandler.CaptureError(r.Context(), err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", `attachment; filename="ov-chipkaart-dashboard-export.zip"`)
+		w.Header().Set("Cache-Control", "no-store")
+		_, _ = w.Write(archive)
 	}
+}
 
-	db := client.Database(os.Getenv("MONGODB_DB_NAME"))
+func initializeMailer(app *application) (mailer.Mailer, error) {
+	switch os.Getenv("MAILER") {
+	case "smtp":
+		port, err := strconv.Atoi(os.Getenv("SMTP_PORT"))
+		if err != nil {
+			return nil, errors.Wrap(err, "SMTP_PORT must be a number")
+		}
 
-	return mongodb.NewMongoDB(db)
+		return mailer.NewSMTPMailer(mailer.SMTPOptions{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     port,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("MAIL_FROM"),
+		}), nil
+	case "file":
+		return mailer.NewFileMailer(os.Getenv("MAIL_DIRECTORY"), os.Getenv("MAIL_FROM"), app.logger), nil
+	case "log":
+		// the links in the emails are written to the logs so the log mailer must only be chosen explicitly
+		return mailer.NewLogMailer(app.logger), nil
+	default:
+		return nil, errors.Errorf("MAILER must be smtp, file or log, got '%s'", os.Getenv("MAILER"))
+	}
 }
 
-func initializeCache() cache.Cache {
-	return redis.NewClient(redis.Options{
-		Address:  os.Getenv("REDIS_ADDRESS"),
-		Password: os.Getenv("REDIS_PASSWORD"),
-		DB:       0,
-	})
+func initializeValidator(app *application) validator.Validator {
+	return govalidator.New(app.db, recaptcha.NewDefaultGoogleVerifier(os.Getenv("RECAPTCHA_SECRET")))
 }
 
-func initializeLogger() logger.Logger {
-	return logger.NewGoKitLogger(os.Stdout)
+func initializePasswordService() password.Service {
+	return password.NewBcryptService()
 }
 
-func initializeErrorHandler() errorhandler.ErrorHandler {
-	errHandler, err := errorhandler.NewSentryErrorHandler(sentry.ClientOptions{
-		// Either set your DSN here or set the SENTRY_DSN environment variable.
-		Dsn: os.Getenv("SENTRY_DSN"),
-		// Enable printing of SDK debug messages.
-		// Useful when getting started or trying to figure something out.
-		Debug: true,
-	})
+func initializeJWTService(app *application) (jwt.Service, error) {
+	sessionDays, err := strconv.Atoi(os.Getenv("AUTH_SESSION_DAYS"))
+	if err != nil {
+		return jwt.Service{}, errors.Wrap(err, "AUTH_SESSION_DAYS must be a number")
+	}
+
+	if sessionDays < 1 {
+		return jwt.Service{}, errors.New("AUTH_SESSION_DAYS cannot be < 1")
+	}
+
+	accessTokenMinutes, err := strconv.Atoi(os.Getenv("AUTH_ACCESS_TOKEN_MINUTES"))
+	if err != nil {
+		return jwt.Service{}, errors.Wrap(err, "AUTH_ACCESS_TOKEN_MINUTES must be a number")
+	}
+
+	if accessTokenMinutes < 1 {
+		return jwt.Service{}, errors.New("AUTH_ACCESS_TOKEN_MINUTES cannot be < 1")
+	}
 
+	keySet, err := jwt.LoadKeySet(os.Getenv("JWT_KEYS_DIRECTORY"), os.Getenv("JWT_SIGNING_KEY_ID"))
 	if err != nil {
-		log.Fatal(err.Error())
+		return jwt.Service{}, err
 	}
 
-	return errHandler
+	return jwt.NewService(keySet, app.cache, accessTokenMinutes, sessionDays), nil
 }
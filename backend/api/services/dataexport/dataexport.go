@@ -0,0 +1,131 @@
+package dataexport
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/cache"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/database"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/pkg/errors"
+)
+
+const (
+	cacheKeyPrefix = "data-export:"
+	tokenBytes     = 32
+
+	// Expiration is how long an archive can be downloaded
+	Expiration = time.Hour
+)
+
+var (
+	// ErrExportNotFound is thrown when an archive does not exist or has expired
+	ErrExportNotFound = errors.New("the export does not exist or has expired")
+)
+
+// Export is an archive which is ready to be downloaded
+type Export struct {
+	Token     string
+	ExpiresAt time.Time
+}
+
+// Service creates archives with everything which is stored about a user.
+// The archives are kept in the cache until they expire and can be downloaded by anyone with the token.
+type Service struct {
+	db    database.DB
+	cache cache.Cache
+}
+
+// NewService creates a new instance of the data export service
+func NewService(db database.DB, cache cache.Cache) Service {
+	return Service{db, cache}
+}
+
+// Create builds the archive of a user. The archive contains a JSON file for each collection with data of the user.
+func (service Service) Create(userID id.ID) (export Export, err error) {
+	data, err := service.db.UserDataRepository().Export(userID)
+	if err != nil {
+		return export, err
+	}
+
+	archive, err := createArchive(data)
+	if err != nil {
+		return export, errors.Wrapf(err, "cannot create the archive of user with ID: %s", userID.String())
+	}
+
+	randomBytes := make([]byte, tokenBytes)
+	_, err = rand.Read(randomBytes)
+	if err != nil {
+		return export, errors.Wrap(err, "cannot generate export token")
+	}
+
+	export.Token = base64.RawURLEncoding.EncodeToString(randomBytes)
+	export.ExpiresAt = time.Now().Add(Expiration)
+
+	err = service.cache.Set(cacheKey(export.Token), string(archive), Expiration)
+	if err != nil {
+		return export, errors.Wrapf(err, "cannot store the archive of user with ID: %s", userID.String())
+	}
+
+	return export, nil
+}
+
+// Download returns the zip archive of a token
+func (service Service) Download(token string) (archive []byte, err error) {
+	value, err := service.cache.Get(cacheKey(token))
+	if err == cache.ErrCacheMiss {
+		return archive, ErrExportNotFound
+	}
+	if err != nil {
+		return archive, errors.Wrap(err, "cannot fetch export")
+	}
+
+	return []byte(value), nil
+}
+
+func createArchive(data map[string][]json.RawMessage) ([]byte, error) {
+	var collections []string
+	for collection := range data {
+		collections = append(collections, collection)
+	}
+	sort.Strings(collections)
+
+	buffer := new(bytes.Buffer)
+	writer := zip.NewWriter(buffer)
+	for _, collection := range collections {
+		content, err := json.MarshalIndent(data[collection], "", "  ")
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot encode %s", collection)
+		}
+
+		file, err := writer.Create(collection + ".json")
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot add %s to the archive", collection)
+		}
+
+		_, err = file.Write(content)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot write %s to the archive", collection)
+		}
+	}
+
+	err := writer.Close()
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot close the archive")
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// cacheKey hashes the token so the archives in the cache cannot be downloaded if the cache is leaked
+func cacheKey(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return cacheKeyPrefix + hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,122 @@
+package dataexport
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/cache/memory"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/database"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+)
+
+// exportDB is a database where every user has the same data
+type exportDB struct {
+	database.DB
+	data map[string][]json.RawMessage
+}
+
+func (db exportDB) UserDataRepository() database.UserDataRepository {
+	return exportRepository{data: db.data}
+}
+
+type exportRepository struct {
+	database.UserDataRepository
+	data map[string][]json.RawMessage
+}
+
+func (repository exportRepository) Export(_ id.ID) (map[string][]json.RawMessage, error) {
+	return repository.data, nil
+}
+
+// readArchive returns the names of the files in a zip archive and the decoded JSON documents in the files
+func readArchive(t *testing.T, archive []byte) (names []string, files map[string][]map[string]interface{}) {
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files = map[string][]map[string]interface{}{}
+	for _, file := range reader.File {
+		content, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, err := ioutil.ReadAll(content)
+		_ = content.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var documents []map[string]interface{}
+		if err = json.Unmarshal(body, &documents); err != nil {
+			t.Fatalf("%s is not valid JSON: %v", file.Name, err)
+		}
+
+		names = append(names, file.Name)
+		files[file.Name] = documents
+	}
+
+	return names, files
+}
+
+func TestCreateArchive(t *testing.T) {
+	archive, err := createArchive(map[string][]json.RawMessage{
+		"users":   {json.RawMessage(`{"email": "anne@example.com"}`)},
+		"imports": {json.RawMessage(`{"card_number": "3528"}`), json.RawMessage(`{"card_number": "3529"}`)},
+		"jobs":    {},
+	})
+	if err != nil {
+		t.Fatalf("createArchive() error = %v", err)
+	}
+
+	// the collections are sorted by name
+	names, files := readArchive(t, archive)
+	want := []string{"imports.json", "jobs.json", "users.json"}
+	documents := map[string]int{"imports.json": 2, "jobs.json": 0, "users.json": 1}
+	if len(names) != len(want) {
+		t.Fatalf("files = %v, want %v", names, want)
+	}
+
+	for index, name := range names {
+		if name != want[index] {
+			t.Errorf("file %d = %s, want %s", index, name, want[index])
+		}
+		if len(files[name]) != documents[name] {
+			t.Errorf("%s contains %d documents, want %d", name, len(files[name]), documents[name])
+		}
+	}
+}
+
+func TestCreateArchiveInvalidDocument(t *testing.T) {
+	_, err := createArchive(map[string][]json.RawMessage{"users": {json.RawMessage(`{"email":`)}})
+	if err == nil {
+		t.Error("createArchive() error = nil, want an error for the invalid document")
+	}
+}
+
+func TestServiceDownload(t *testing.T) {
+	service := NewService(exportDB{data: map[string][]json.RawMessage{
+		"users": {json.RawMessage(`{"email": "anne@example.com"}`)},
+	}}, memory.NewClient())
+
+	export, err := service.Create(id.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := service.Download(export.Token)
+	if err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if names, _ := readArchive(t, archive); len(names) != 1 || names[0] != "users.json" {
+		t.Errorf("files = %v, want [users.json]", names)
+	}
+
+	if _, err = service.Download(export.Token + "x"); err != ErrExportNotFound {
+		t.Errorf("Download() with an unknown token error = %v, want %v", err, ErrExportNotFound)
+	}
+}
@@ -0,0 +1,140 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// StatusOK is the status of a dependency which answered the ping
+	StatusOK = "ok"
+
+	// StatusUnavailable is the status of a dependency which failed the ping or did not answer before the timeout
+	StatusUnavailable = "unavailable"
+)
+
+// Dependency is a service which must be reachable for the API to serve requests
+type Dependency struct {
+	Name string
+	Ping func(ctx context.Context) error
+}
+
+// CheckResult is the outcome of the ping of a dependency
+type CheckResult struct {
+	Status     string  `json:"status"`
+	DurationMs float64 `json:"durationMs"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// Report is the outcome of the pings of all the dependencies
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// IsOK is true when every dependency answered the ping
+func (report Report) IsOK() bool {
+	return report.Status == StatusOK
+}
+
+// Failures describes the dependencies which failed the ping e.g "mongodb: connection refused"
+func (report Report) Failures() string {
+	var failures []string
+	for name, result := range report.Checks {
+		if result.Status != StatusOK {
+			failures = append(failures, name+": "+result.Error)
+		}
+	}
+
+	sort.Strings(failures)
+	return strings.Join(failures, ", ")
+}
+
+// Checker pings the dependencies of the API
+type Checker struct {
+	dependencies []Dependency
+	timeout      time.Duration
+}
+
+// NewChecker creates a Checker which gives every dependency timeout to answer the ping
+func NewChecker(timeout time.Duration, dependencies ...Dependency) Checker {
+	return Checker{dependencies: dependencies, timeout: timeout}
+}
+
+// Check pings the dependencies concurrently
+func (checker Checker) Check(ctx context.Context) Report {
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(checker.dependencies))}
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	for _, dependency := range checker.dependencies {
+		wg.Add(1)
+		go func(dependency Dependency) {
+			defer wg.Done()
+			result := checker.ping(ctx, dependency)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			report.Checks[dependency.Name] = result
+			if result.Status != StatusOK {
+				report.Status = StatusUnavailable
+			}
+		}(dependency)
+	}
+	wg.Wait()
+
+	return report
+}
+
+func (checker Checker) ping(ctx context.Context, dependency Dependency) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, checker.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := dependency.Ping(ctx)
+	result := CheckResult{
+		Status:     StatusOK,
+		DurationMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+
+	if err != nil {
+		result.Status = StatusUnavailable
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// LivenessHandler answers 200 as long as the process is able to serve HTTP requests, it does not ping the dependencies
+// so an orchestrator does not restart the API when the database is down.
+func LivenessHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": StatusOK})
+	}
+}
+
+// ReadinessHandler answers 200 when every dependency answered the ping and 503 otherwise
+func ReadinessHandler(checker Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := checker.Check(r.Context())
+
+		status := http.StatusOK
+		if !report.IsOK() {
+			status = http.StatusServiceUnavailable
+		}
+
+		writeJSON(w, status, report)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
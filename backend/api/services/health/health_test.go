@@ -0,0 +1,112 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func succeeding(ctx context.Context) error {
+	return nil
+}
+
+func failing(ctx context.Context) error {
+	return errors.New("connection refused")
+}
+
+// hanging answers the ping when the context is done
+func hanging(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestReadinessHandler(t *testing.T) {
+	tests := []struct {
+		name         string
+		dependencies []Dependency
+		status       int
+		report       Report
+		failures     string
+	}{
+		{
+			name:   "no dependencies",
+			status: http.StatusOK,
+			report: Report{Status: StatusOK, Checks: map[string]CheckResult{}},
+		},
+		{
+			name:         "every dependency answers",
+			dependencies: []Dependency{{"mongodb", succeeding}, {"redis", succeeding}},
+			status:       http.StatusOK,
+			report: Report{Status: StatusOK, Checks: map[string]CheckResult{
+				"mongodb": {Status: StatusOK},
+				"redis":   {Status: StatusOK},
+			}},
+		},
+		{
+			name:         "a dependency fails",
+			dependencies: []Dependency{{"mongodb", succeeding}, {"redis", failing}},
+			status:       http.StatusServiceUnavailable,
+			report: Report{Status: StatusUnavailable, Checks: map[string]CheckResult{
+				"mongodb": {Status: StatusOK},
+				"redis":   {Status: StatusUnavailable, Error: "connection refused"},
+			}},
+			failures: "redis: connection refused",
+		},
+		{
+			name:         "a dependency does not answer before the timeout",
+			dependencies: []Dependency{{"mongodb", hanging}, {"redis", failing}},
+			status:       http.StatusServiceUnavailable,
+			report: Report{Status: StatusUnavailable, Checks: map[string]CheckResult{
+				"mongodb": {Status: StatusUnavailable, Error: context.DeadlineExceeded.Error()},
+				"redis":   {Status: StatusUnavailable, Error: "connection refused"},
+			}},
+			failures: "mongodb: context deadline exceeded, redis: connection refused",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checker := NewChecker(10*time.Millisecond, test.dependencies...)
+			if failures := checker.Check(context.Background()).Failures(); failures != test.failures {
+				t.Errorf("Failures() = %s, want %s", failures, test.failures)
+			}
+
+			recorder := httptest.NewRecorder()
+			ReadinessHandler(checker)(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if recorder.Code != test.status {
+				t.Errorf("status = %d, want %d", recorder.Code, test.status)
+			}
+			if recorder.Header().Get("Cache-Control") != "no-store" {
+				t.Errorf("Cache-Control = %s, want no-store", recorder.Header().Get("Cache-Control"))
+			}
+
+			var report Report
+			if err := json.NewDecoder(recorder.Body).Decode(&report); err != nil {
+				t.Fatal(err)
+			}
+
+			if report.Status != test.report.Status || len(report.Checks) != len(test.report.Checks) {
+				t.Fatalf("report = %+v, want %+v", report, test.report)
+			}
+			for name, want := range test.report.Checks {
+				if got := report.Checks[name]; got.Status != want.Status || got.Error != want.Error {
+					t.Errorf("check %s = %+v, want %+v", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLivenessHandler(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	LivenessHandler()(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+}
@@ -1,6 +1,13 @@
 package jwt
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
@@ -12,89 +19,348 @@ import (
 )
 
 const (
-	keyUserID = "user_id"
-	keyExp    = "exp"
+	// tokenIssuer is the iss claim of the access tokens
+	tokenIssuer = "ov-chipkaart-dashboard"
+
+	cacheKeyPrefixSession      = "session:"
+	cacheKeyPrefixRefreshToken = "refresh-token:"
+	cacheKeyPrefixUsedToken    = "used-refresh-token:"
+	cacheKeyPrefixBlacklist    = "blacklisted-token:"
+	cacheKeyPrefixUserRevoked  = "sessions-revoked-at:"
+
+	refreshTokenBytes = 32
 )
 
 var (
 	// ErrTokenBlacklisted is thrown when a jwt token is blacklisted
 	ErrTokenBlacklisted = errors.New("token has been blacklisted")
+
+	// ErrInvalidToken is thrown when a jwt token cannot be used to authenticate a user
+	ErrInvalidToken = errors.New("token is invalid")
+
+	// ErrInvalidRefreshToken is thrown when a refresh token does not exist or has expired
+	ErrInvalidRefreshToken = errors.New("refresh token is invalid or has expired")
+
+	// ErrRefreshTokenReused is thrown when a refresh token which has already been used is used again.
+	// The session is revoked because the token has probably been stolen.
+	ErrRefreshTokenReused = errors.New("refresh token has already been used")
+
+	// ErrSessionRevoked is thrown when a token belongs to a session which has ended
+	ErrSessionRevoked = errors.New("session has been revoked")
 )
 
+// Session contains the tokens of a logged in user.
+// The access token is short lived and the refresh token is used once to get a new pair of tokens.
+type Session struct {
+	AccessToken          string
+	AccessTokenExpiresAt time.Time
+	RefreshToken         string
+}
+
+// claims are the claims of an access token. The user ID is the subject.
+type claims struct {
+	jwt.StandardClaims
+	SessionID string `json:"sid"`
+}
+
+// Valid checks the registered claims. Tokens without an expiry, subject or session are never valid.
+func (claims claims) Valid() error {
+	err := claims.StandardClaims.Valid()
+	if err != nil {
+		return err
+	}
+
+	if claims.ExpiresAt == 0 || claims.Subject == "" || claims.SessionID == "" || !claims.VerifyIssuer(tokenIssuer, true) {
+		return ErrInvalidToken
+	}
+
+	return nil
+}
+
+// refreshTokenRecord is stored in the cache for every refresh token which is issued in a session. A used token is
+// marked with a separate key so two requests cannot both use the same token.
+type refreshTokenRecord struct {
+	UserID    string    `json:"user_id"`
+	SessionID string    `json:"session_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
 // Service is a new instance of the JWT service
 type Service struct {
-	secret      []byte
-	cache       cache.Cache
-	sessionDays int
+	keySet             KeySet
+	cache              cache.Cache
+	accessTokenMinutes int
+	sessionDays        int
 }
 
 // NewService creates a new instance of the JWT service
-func NewService(secret string, cache cache.Cache, sessionDays int) Service {
+func NewService(keySet KeySet, cache cache.Cache, accessTokenMinutes int, sessionDays int) Service {
 	return Service{
-		secret:      []byte(secret),
-		cache:       cache,
-		sessionDays: sessionDays,
+		keySet:             keySet,
+		cache:              cache,
+		accessTokenMinutes: accessTokenMinutes,
+		sessionDays:        sessionDays,
 	}
 }
 
-//GenerateTokenForUserID generates a jwt token and assign a email to it's claims and return it
-func (service Service) GenerateTokenForUserID(UserID id.ID) (result string, err error) {
-	token := jwt.New(jwt.SigningMethodHS256)
+// CreateSession starts a new session for a user and returns the first pair of tokens
+func (service Service) CreateSession(userID id.ID) (session Session, err error) {
+	sessionID := id.New().String()
+	expiresAt := time.Now().AddDate(0, 0, service.sessionDays)
 
-	/* Create a map to store our claims */
-	claims := token.Claims.(jwt.MapClaims)
+	// the session stores when it was created so all the sessions of a user which started before a point in time can be revoked
+	err = service.cache.Set(cacheKeyPrefixSession+sessionID, strconv.FormatInt(time.Now().UnixNano(), 10), time.Until(expiresAt))
+	if err != nil {
+		return session, errors.Wrapf(err, "cannot store session for user with ID: %s", userID.String())
+	}
 
-	/* Set token claims */
-	claims[keyExp] = time.Now().AddDate(0, 0, service.sessionDays)
-	claims["nbf"] = time.Now().Unix()
-	claims["iat"] = time.Now().Unix()
-	claims[keyUserID] = UserID.String()
+	return service.issueTokens(userID, sessionID, expiresAt)
+}
 
-	result, err = token.SignedString(service.secret)
+// RefreshSession exchanges a refresh token for a new pair of tokens in the same session.
+// A refresh token can only be used once, using it again revokes the whole session.
+func (service Service) RefreshSession(refreshToken string) (session Session, err error) {
+	record, err := service.getRefreshTokenRecord(refreshToken)
 	if err != nil {
-		return result, err
+		return session, err
 	}
 
-	return result, nil
+	isActive, err := service.sessionIsActive(record.SessionID, record.UserID)
+	if err != nil {
+		return session, err
+	}
+
+	if !isActive {
+		return session, ErrSessionRevoked
+	}
+
+	isFirstUse, err := service.cache.SetIfNotExists(usedRefreshTokenCacheKey(refreshToken), "", time.Until(record.ExpiresAt))
+	if err != nil {
+		return session, errors.Wrap(err, "cannot mark refresh token as used")
+	}
+
+	// the token was used before or another request is using it right now
+	if !isFirstUse {
+		err = service.revokeSession(record.SessionID)
+		if err != nil {
+			return session, err
+		}
+		return session, errors.Wrapf(ErrRefreshTokenReused, "session %s of user %s has been revoked", record.SessionID, record.UserID)
+	}
+
+	userID, err := id.FromString(record.UserID)
+	if err != nil {
+		return session, errors.Wrapf(err, "cannot parse user ID of refresh token: %s", record.UserID)
+	}
+
+	return service.issueTokens(userID, record.SessionID, record.ExpiresAt)
 }
 
-// InvalidateToken invalidates a jwt token
-func (service Service) InvalidateToken(tokenString string) (err error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return service.secret, nil
-	})
+// CancelToken ends the session of an access token or a refresh token
+func (service Service) CancelToken(token string) (err error) {
+	// access tokens are JWTs with a header, payload and signature separated by dots
+	if strings.Count(token, ".") == 2 {
+		return service.InvalidateToken(token)
+	}
+
+	record, err := service.getRefreshTokenRecord(token)
+	if err != nil {
+		return err
+	}
+
+	return service.revokeSession(record.SessionID)
+}
 
+// InvalidateToken invalidates a jwt token and ends the session it belongs to
+func (service Service) InvalidateToken(tokenString string) (err error) {
+	claims, err := service.parseToken(tokenString)
 	if err != nil {
 		return err
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok || !token.Valid {
-		return nil
+	err = service.cache.Set(cacheKeyPrefixBlacklist+tokenString, "", time.Until(time.Unix(claims.ExpiresAt, 0)))
+	if err != nil {
+		return errors.Wrap(err, "cannot blacklist token")
 	}
 
-	return service.cache.Set(tokenString, "", claims[keyExp].(time.Time).Sub(time.Now()))
+	return service.revokeSession(claims.SessionID)
 }
 
-//GetUserIDFromToken parses a jwt token and returns the email it it's claims
+// GetUserIDFromToken parses an access token and returns the user ID in its claims
 func (service Service) GetUserIDFromToken(tokenString string) (userID id.ID, err error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return service.secret, nil
-	})
-
+	claims, err := service.parseToken(tokenString)
 	if err != nil {
 		return userID, err
 	}
 
-	_, err = service.cache.Get(tokenString)
+	_, err = service.cache.Get(cacheKeyPrefixBlacklist + tokenString)
 	if err == nil {
 		return userID, ErrTokenBlacklisted
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok || !token.Valid {
+	isActive, err := service.sessionIsActive(claims.SessionID, claims.Subject)
+	if err != nil {
 		return userID, err
 	}
 
-	return id.FromString(claims[keyUserID].(string))
+	if !isActive {
+		return userID, ErrSessionRevoked
+	}
+
+	return id.FromString(claims.Subject)
+}
+
+// JWKS returns the public keys which can verify the access tokens
+func (service Service) JWKS() JSONWebKeySet {
+	return service.keySet.JWKS()
+}
+
+// parseToken verifies the signature and the registered claims of an access token.
+// Only tokens signed with RS256 are accepted so a token cannot choose a weaker algorithm e.g. "none".
+func (service Service) parseToken(tokenString string) (result claims, err error) {
+	parser := jwt.Parser{ValidMethods: []string{signingMethod.Alg()}}
+	token, err := parser.ParseWithClaims(tokenString, &result, service.keySet.verificationKey)
+	if err != nil {
+		return result, errors.Wrap(ErrInvalidToken, err.Error())
+	}
+
+	if !token.Valid {
+		return result, ErrInvalidToken
+	}
+
+	return result, nil
+}
+
+// issueTokens generates a new access token and a new refresh token in a session
+func (service Service) issueTokens(userID id.ID, sessionID string, sessionExpiresAt time.Time) (session Session, err error) {
+	session.AccessTokenExpiresAt = time.Now().Add(time.Duration(service.accessTokenMinutes) * time.Minute)
+	if session.AccessTokenExpiresAt.After(sessionExpiresAt) {
+		session.AccessTokenExpiresAt = sessionExpiresAt
+	}
+
+	token := jwt.NewWithClaims(signingMethod, claims{
+		StandardClaims: jwt.StandardClaims{
+			Id:        id.New().String(),
+			Issuer:    tokenIssuer,
+			Subject:   userID.String(),
+			ExpiresAt: session.AccessTokenExpiresAt.Unix(),
+			NotBefore: time.Now().Unix(),
+			IssuedAt:  time.Now().Unix(),
+		},
+		SessionID: sessionID,
+	})
+	token.Header["kid"] = service.keySet.signingKey.ID
+
+	session.AccessToken, err = token.SignedString(service.keySet.signingKey.PrivateKey)
+	if err != nil {
+		return session, errors.Wrap(err, "cannot sign access token")
+	}
+
+	randomBytes := make([]byte, refreshTokenBytes)
+	_, err = rand.Read(randomBytes)
+	if err != nil {
+		return session, errors.Wrap(err, "cannot generate refresh token")
+	}
+
+	session.RefreshToken = base64.RawURLEncoding.EncodeToString(randomBytes)
+	err = service.storeRefreshTokenRecord(session.RefreshToken, refreshTokenRecord{
+		UserID:    userID.String(),
+		SessionID: sessionID,
+		ExpiresAt: sessionExpiresAt,
+	})
+	if err != nil {
+		return session, err
+	}
+
+	return session, nil
+}
+
+func (service Service) getRefreshTokenRecord(refreshToken string) (record refreshTokenRecord, err error) {
+	value, err := service.cache.Get(refreshTokenCacheKey(refreshToken))
+	if err == cache.ErrCacheMiss {
+		return record, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return record, errors.Wrap(err, "cannot fetch refresh token")
+	}
+
+	err = json.Unmarshal([]byte(value), &record)
+	if err != nil {
+		return record, errors.Wrap(err, "cannot decode refresh token")
+	}
+
+	return record, nil
+}
+
+func (service Service) storeRefreshTokenRecord(refreshToken string, record refreshTokenRecord) (err error) {
+	value, err := json.Marshal(record)
+	if err != nil {
+		return errors.Wrap(err, "cannot encode refresh token")
+	}
+
+	err = service.cache.Set(refreshTokenCacheKey(refreshToken), string(value), time.Until(record.ExpiresAt))
+	if err != nil {
+		return errors.Wrap(err, "cannot store refresh token")
+	}
+
+	return nil
+}
+
+// RevokeUserSessions ends all the sessions of a user e.g when the password is changed
+func (service Service) RevokeUserSessions(userID id.ID) (err error) {
+	err = service.cache.Set(
+		cacheKeyPrefixUserRevoked+userID.String(),
+		strconv.FormatInt(time.Now().UnixNano(), 10),
+		time.Duration(service.sessionDays)*24*time.Hour,
+	)
+	return errors.Wrapf(err, "cannot revoke the sessions of user with ID: %s", userID.String())
+}
+
+// sessionIsActive checks that a session was not revoked
+func (service Service) sessionIsActive(sessionID string, userID string) (isActive bool, err error) {
+	createdAt, err := service.getTimestamp(cacheKeyPrefixSession + sessionID)
+	if err == cache.ErrCacheMiss {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "cannot fetch session %s", sessionID)
+	}
+
+	revokedAt, err := service.getTimestamp(cacheKeyPrefixUserRevoked + userID)
+	if err == cache.ErrCacheMiss {
+		return true, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "cannot fetch when the sessions of user %s were revoked", userID)
+	}
+
+	return createdAt > revokedAt, nil
+}
+
+// getTimestamp fetches a timestamp in nanoseconds from the cache
+func (service Service) getTimestamp(key string) (timestamp int64, err error) {
+	value, err := service.cache.Get(key)
+	if err != nil {
+		return timestamp, err
+	}
+
+	timestamp, err = strconv.ParseInt(value, 10, 64)
+	return timestamp, errors.Wrapf(err, "cannot parse the timestamp of %s", key)
+}
+
+// revokeSession ends a session. The access tokens and the refresh tokens of the session can no longer be used.
+func (service Service) revokeSession(sessionID string) (err error) {
+	return errors.Wrapf(service.cache.Delete(cacheKeyPrefixSession+sessionID), "cannot revoke session %s", sessionID)
+}
+
+// usedRefreshTokenCacheKey is the key which marks a refresh token as used
+func usedRefreshTokenCacheKey(refreshToken string) string {
+	hash := sha256.Sum256([]byte(refreshToken))
+	return cacheKeyPrefixUsedToken + hex.EncodeToString(hash[:])
+}
+
+// refreshTokenCacheKey hashes the refresh token so the tokens in the cache cannot be used if the cache is leaked
+func refreshTokenCacheKey(refreshToken string) string {
+	hash := sha256.Sum256([]byte(refreshToken))
+	return cacheKeyPrefixRefreshToken + hex.EncodeToString(hash[:])
 }
@@ -0,0 +1,197 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"sync"
+	"testing"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/cache/memory"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/pkg/errors"
+)
+
+func newTestService(t *testing.T) (Service, *memory.Client) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keySet, err := NewKeySet("test", []Key{{ID: "test", PrivateKey: privateKey}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache := memory.NewClient()
+	return NewService(keySet, cache, 15, 30), cache
+}
+
+func TestServiceRefreshSession(t *testing.T) {
+	service, _ := newTestService(t)
+	userID := id.New()
+
+	session, err := service.CreateSession(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refreshed, err := service.RefreshSession(session.RefreshToken)
+	if err != nil {
+		t.Fatalf("RefreshSession() error = %v", err)
+	}
+
+	if refreshed.RefreshToken == session.RefreshToken {
+		t.Error("RefreshSession() returned the same refresh token")
+	}
+
+	tokenUserID, err := service.GetUserIDFromToken(refreshed.AccessToken)
+	if err != nil || tokenUserID != userID {
+		t.Errorf("GetUserIDFromToken() = %s, %v, want %s", tokenUserID, err, userID)
+	}
+
+	// the new refresh token can be used once as well
+	_, err = service.RefreshSession(refreshed.RefreshToken)
+	if err != nil {
+		t.Errorf("RefreshSession() with the rotated token error = %v", err)
+	}
+}
+
+func TestServiceRefreshSessionReuseRevokesSession(t *testing.T) {
+	service, _ := newTestService(t)
+
+	session, err := service.CreateSession(id.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refreshed, err := service.RefreshSession(session.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = service.RefreshSession(session.RefreshToken)
+	if errors.Cause(err) != ErrRefreshTokenReused {
+		t.Fatalf("RefreshSession() with a used token error = %v, want %v", err, ErrRefreshTokenReused)
+	}
+
+	_, err = service.GetUserIDFromToken(refreshed.AccessToken)
+	if err != ErrSessionRevoked {
+		t.Errorf("GetUserIDFromToken() after reuse error = %v, want %v", err, ErrSessionRevoked)
+	}
+
+	_, err = service.RefreshSession(refreshed.RefreshToken)
+	if err != ErrSessionRevoked {
+		t.Errorf("RefreshSession() after reuse error = %v, want %v", err, ErrSessionRevoked)
+	}
+}
+
+func TestServiceRefreshSessionConcurrentReuse(t *testing.T) {
+	service, _ := newTestService(t)
+
+	session, err := service.CreateSession(id.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const requests = 10
+	var wg sync.WaitGroup
+	results := make(chan error, requests)
+	for index := 0; index < requests; index++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := service.RefreshSession(session.RefreshToken)
+			results <- err
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	succeeded := 0
+	for err := range results {
+		if err == nil {
+			succeeded++
+		}
+	}
+
+	if succeeded > 1 {
+		t.Errorf("%d concurrent refreshes with the same token succeeded, want at most 1", succeeded)
+	}
+}
+
+func TestServiceCancelToken(t *testing.T) {
+	tests := []struct {
+		name  string
+		token func(session Session) string
+	}{
+		{name: "access token", token: func(session Session) string { return session.AccessToken }},
+		{name: "refresh token", token: func(session Session) string { return session.RefreshToken }},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service, _ := newTestService(t)
+
+			session, err := service.CreateSession(id.New())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = service.CancelToken(test.token(session))
+			if err != nil {
+				t.Fatalf("CancelToken() error = %v", err)
+			}
+
+			_, err = service.RefreshSession(session.RefreshToken)
+			if err != ErrSessionRevoked {
+				t.Errorf("RefreshSession() after cancel error = %v, want %v", err, ErrSessionRevoked)
+			}
+		})
+	}
+}
+
+func TestServiceRevokeUserSessions(t *testing.T) {
+	service, _ := newTestService(t)
+	userID := id.New()
+
+	session, err := service.CreateSession(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = service.RevokeUserSessions(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = service.GetUserIDFromToken(session.AccessToken)
+	if err != ErrSessionRevoked {
+		t.Errorf("GetUserIDFromToken() error = %v, want %v", err, ErrSessionRevoked)
+	}
+
+	// a session which starts after the revocation is active
+	session, err = service.CreateSession(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = service.GetUserIDFromToken(session.AccessToken)
+	if err != nil {
+		t.Errorf("GetUserIDFromToken() of a new session error = %v", err)
+	}
+}
+
+func TestServiceGetUserIDFromTokenRejectsOtherKeys(t *testing.T) {
+	service, _ := newTestService(t)
+	otherService, _ := newTestService(t)
+
+	session, err := otherService.CreateSession(id.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = service.GetUserIDFromToken(session.AccessToken)
+	if errors.Cause(err) != ErrInvalidToken {
+		t.Errorf("GetUserIDFromToken() error = %v, want %v", err, ErrInvalidToken)
+	}
+}
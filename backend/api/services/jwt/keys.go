@@ -0,0 +1,142 @@
+package jwt
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/pkg/errors"
+)
+
+// signingMethod is the only algorithm which is accepted for access tokens
+var signingMethod = jwt.SigningMethodRS256
+
+var (
+	// ErrUnknownKeyID is thrown when a token is signed with a key which is not in the key set
+	ErrUnknownKeyID = errors.New("token is signed with an unknown key")
+)
+
+// Key is an RSA key which is identified by the kid header of a token.
+// PrivateKey is only set for keys which can sign tokens, retired keys only need the public key to verify tokens.
+type Key struct {
+	ID         string
+	PrivateKey *rsa.PrivateKey
+	PublicKey  *rsa.PublicKey
+}
+
+// KeySet contains the key which signs new tokens and all the keys which can verify tokens.
+// Keys are rotated by adding a new signing key and keeping the old public key until its tokens have expired.
+type KeySet struct {
+	signingKey Key
+	keys       map[string]Key
+}
+
+// NewKeySet creates a new instance of the KeySet
+func NewKeySet(signingKeyID string, keys []Key) (keySet KeySet, err error) {
+	keySet.keys = map[string]Key{}
+	for _, key := range keys {
+		if key.PublicKey == nil && key.PrivateKey != nil {
+			key.PublicKey = &key.PrivateKey.PublicKey
+		}
+		keySet.keys[key.ID] = key
+	}
+
+	signingKey, ok := keySet.keys[signingKeyID]
+	if !ok || signingKey.PrivateKey == nil {
+		return keySet, errors.Errorf("cannot find the private key of the signing key '%s'", signingKeyID)
+	}
+	keySet.signingKey = signingKey
+
+	return keySet, nil
+}
+
+// LoadKeySet loads the PEM encoded RSA keys in a directory. The ID of a key is the name of the file without the extension.
+func LoadKeySet(directory string, signingKeyID string) (keySet KeySet, err error) {
+	files, err := filepath.Glob(filepath.Join(directory, "*.pem"))
+	if err != nil {
+		return keySet, errors.Wrapf(err, "cannot list the keys in '%s'", directory)
+	}
+
+	var keys []Key
+	for _, file := range files {
+		key, err := loadKey(file)
+		if err != nil {
+			return keySet, err
+		}
+		keys = append(keys, key)
+	}
+
+	return NewKeySet(signingKeyID, keys)
+}
+
+func loadKey(file string) (key Key, err error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return key, errors.Wrapf(err, "cannot read the key '%s'", file)
+	}
+
+	key.ID = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	if strings.Contains(string(content), "PRIVATE KEY") {
+		key.PrivateKey, err = jwt.ParseRSAPrivateKeyFromPEM(content)
+		return key, errors.Wrapf(err, "cannot parse the private key '%s'", file)
+	}
+
+	key.PublicKey, err = jwt.ParseRSAPublicKeyFromPEM(content)
+	return key, errors.Wrapf(err, "cannot parse the public key '%s'", file)
+}
+
+// verificationKey returns the public key used to verify a token based on its kid header
+func (keySet KeySet) verificationKey(token *jwt.Token) (interface{}, error) {
+	keyID, ok := token.Header["kid"].(string)
+	if !ok {
+		return nil, ErrUnknownKeyID
+	}
+
+	key, ok := keySet.keys[keyID]
+	if !ok {
+		return nil, ErrUnknownKeyID
+	}
+
+	return key.PublicKey, nil
+}
+
+// JSONWebKey is the public part of a key in the JWKS format
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	Modulus   string `json:"n"`
+	Exponent  string `json:"e"`
+}
+
+// JSONWebKeySet is the list of public keys which other services use to verify access tokens
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JWKS returns the public keys in the key set
+func (keySet KeySet) JWKS() (result JSONWebKeySet) {
+	result.Keys = []JSONWebKey{}
+	for _, key := range keySet.keys {
+		result.Keys = append(result.Keys, JSONWebKey{
+			KeyType:   "RSA",
+			Use:       "sig",
+			Algorithm: signingMethod.Alg(),
+			KeyID:     key.ID,
+			Modulus:   base64.RawURLEncoding.EncodeToString(key.PublicKey.N.Bytes()),
+			Exponent:  base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.PublicKey.E)).Bytes()),
+		})
+	}
+
+	sort.Slice(result.Keys, func(i, j int) bool {
+		return result.Keys[i].KeyID < result.Keys[j].KeyID
+	})
+
+	return result
+}
@@ -0,0 +1,56 @@
+package mailer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/logger"
+	"github.com/pkg/errors"
+)
+
+// FileMailer writes emails to a directory instead of sending them. It is used during development.
+type FileMailer struct {
+	directory string
+	from      string
+	logger    logger.Logger
+}
+
+// NewFileMailer creates a new instance of the FileMailer
+func NewFileMailer(directory string, from string, logger logger.Logger) Mailer {
+	return &FileMailer{directory, from, logger}
+}
+
+// Send writes the email to a .eml file which can be opened with an email client
+func (mailer FileMailer) Send(message Message) error {
+	err := os.MkdirAll(mailer.directory, 0755)
+	if err != nil {
+		return errors.Wrapf(err, "cannot create the directory '%s'", mailer.directory)
+	}
+
+	fileName := filepath.Join(mailer.directory, time.Now().Format("20060102-150405")+"-"+id.New().String()+".eml")
+	err = ioutil.WriteFile(fileName, encode(mailer.from, message), 0644)
+	if err != nil {
+		return errors.Wrapf(err, "cannot write email to '%s'", fileName)
+	}
+
+	_ = mailer.logger.Log("mailer", "file", "to", message.To, "subject", message.Subject, "file", fileName)
+	return nil
+}
+
+// LogMailer logs emails instead of sending them
+type LogMailer struct {
+	logger logger.Logger
+}
+
+// NewLogMailer creates a new instance of the LogMailer
+func NewLogMailer(logger logger.Logger) Mailer {
+	return &LogMailer{logger}
+}
+
+// Send logs the email
+func (mailer LogMailer) Send(message Message) error {
+	return mailer.logger.Log("mailer", "log", "to", message.To, "subject", message.Subject, "body", message.Body)
+}
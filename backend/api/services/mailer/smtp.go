@@ -0,0 +1,59 @@
+package mailer
+
+import (
+	"fmt"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// SMTPOptions are the settings of the SMTP server
+type SMTPOptions struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// SMTPMailer sends emails using an SMTP server
+type SMTPMailer struct {
+	options SMTPOptions
+}
+
+// NewSMTPMailer creates a new instance of the SMTPMailer
+func NewSMTPMailer(options SMTPOptions) Mailer {
+	return &SMTPMailer{options}
+}
+
+// Send sends an email through the SMTP server
+func (mailer SMTPMailer) Send(message Message) error {
+	var auth smtp.Auth
+	if mailer.options.Username != "" {
+		auth = smtp.PlainAuth("", mailer.options.Username, mailer.options.Password, mailer.options.Host)
+	}
+
+	address := fmt.Sprintf("%s:%d", mailer.options.Host, mailer.options.Port)
+	err := smtp.SendMail(address, auth, mailer.options.From, []string{message.To}, encode(mailer.options.From, message))
+	if err != nil {
+		return errors.Wrapf(err, "cannot send email with subject '%s' using %s", message.Subject, address)
+	}
+
+	return nil
+}
+
+// encode formats a message as a plain text email
+func encode(from string, message Message) []byte {
+	headers := []string{
+		"From: " + from,
+		"To: " + message.To,
+		"Subject: " + message.Subject,
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+	}
+
+	return []byte(strings.Join(headers, "\r\n") + "\r\n\r\n" + strings.ReplaceAll(message.Body, "\n", "\r\n"))
+}
@@ -0,0 +1,45 @@
+package ratelimit
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/cache"
+	"github.com/pkg/errors"
+)
+
+const cacheKeyPrefixLimit = "rate-limit:"
+
+// Result is the outcome of a rate limited action
+type Result struct {
+	Allowed bool
+	// RetryAfter is the time until the next request is allowed when the request is not allowed
+	RetryAfter time.Duration
+}
+
+// Limiter counts the requests of a key in fixed windows e.g 10 requests per minute
+type Limiter struct {
+	cache cache.Cache
+}
+
+// NewLimiter creates a new instance of the Limiter
+func NewLimiter(cache cache.Cache) Limiter {
+	return Limiter{cache}
+}
+
+// Allow counts a request for key and checks if the limit of the current window has been reached
+func (limiter Limiter) Allow(key string, limit int, window time.Duration) (result Result, err error) {
+	now := time.Now()
+	windowStart := now.Truncate(window)
+
+	count, err := limiter.cache.Increment(cacheKeyPrefixLimit+key+":"+strconv.FormatInt(windowStart.Unix(), 10), window)
+	if err != nil {
+		return result, errors.Wrapf(err, "cannot count request for %s", key)
+	}
+
+	if count > int64(limit) {
+		return Result{Allowed: false, RetryAfter: windowStart.Add(window).Sub(now)}, nil
+	}
+
+	return Result{Allowed: true}, nil
+}
@@ -0,0 +1,37 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/cache/memory"
+)
+
+func TestLimiterAllow(t *testing.T) {
+	limiter := NewLimiter(memory.NewClient())
+
+	tests := []struct {
+		key     string
+		allowed bool
+	}{
+		{key: "login:10.0.0.1", allowed: true},
+		{key: "login:10.0.0.1", allowed: true},
+		{key: "login:10.0.0.1", allowed: false},
+		{key: "login:10.0.0.2", allowed: true},
+	}
+
+	for index, test := range tests {
+		result, err := limiter.Allow(test.key, 2, time.Hour)
+		if err != nil {
+			t.Fatalf("Allow() %d error = %v", index, err)
+		}
+
+		if result.Allowed != test.allowed {
+			t.Errorf("Allow(%s) %d = %t, want %t", test.key, index, result.Allowed, test.allowed)
+		}
+
+		if !result.Allowed && (result.RetryAfter <= 0 || result.RetryAfter > time.Hour) {
+			t.Errorf("RetryAfter = %s, want until the end of the window", result.RetryAfter)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package ratelimit
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/cache"
+	"github.com/pkg/errors"
+)
+
+const (
+	cacheKeyPrefixFailures    = "login-failures:"
+	cacheKeyPrefixLockedUntil = "login-locked-until:"
+
+	// failuresExpiration is how long failed logins are remembered after the last failure
+	failuresExpiration = 24 * time.Hour
+)
+
+// LockoutOptions are the settings of the account lockout
+type LockoutOptions struct {
+	// MaxFailures is the number of failed logins before the account is locked
+	MaxFailures int
+	// BaseDelay is how long the account is locked the first time. The delay doubles with every next failure.
+	BaseDelay time.Duration
+	// MaxDelay is the longest time an account is locked
+	MaxDelay time.Duration
+}
+
+// Lockout locks accounts with exponential backoff after too many failed logins. The failures are counted per account
+// whatever the IP address of the client is, the requests per IP address are limited by the rate limit middleware.
+type Lockout struct {
+	cache   cache.Cache
+	options LockoutOptions
+}
+
+// NewLockout creates a new instance of the Lockout
+func NewLockout(cache cache.Cache, options LockoutOptions) Lockout {
+	return Lockout{cache, options}
+}
+
+// LockedFor returns how long nobody can log in to an account. It is 0 when the account is not locked.
+func (lockout Lockout) LockedFor(account string) (duration time.Duration, err error) {
+	value, err := lockout.cache.Get(cacheKeyPrefixLockedUntil + account)
+	if err == cache.ErrCacheMiss {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, errors.Wrapf(err, "cannot fetch the lockout of account: %s", account)
+	}
+
+	lockedUntil, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "cannot parse the lockout of account: %s", account)
+	}
+
+	duration = time.Until(time.Unix(0, lockedUntil))
+	if duration < 0 {
+		return 0, nil
+	}
+
+	return duration, nil
+}
+
+// RecordFailure counts a failed login and locks the account when there are too many failures.
+// It returns how long the account is locked.
+func (lockout Lockout) RecordFailure(account string) (duration time.Duration, err error) {
+	failures, err := lockout.cache.Increment(cacheKeyPrefixFailures+account, failuresExpiration)
+	if err != nil {
+		return 0, errors.Wrapf(err, "cannot count failed login of account: %s", account)
+	}
+
+	if failures < int64(lockout.options.MaxFailures) {
+		return 0, nil
+	}
+
+	duration = lockout.delay(failures - int64(lockout.options.MaxFailures))
+	lockedUntil := time.Now().Add(duration).UnixNano()
+
+	err = lockout.cache.Set(cacheKeyPrefixLockedUntil+account, strconv.FormatInt(lockedUntil, 10), duration)
+	if err != nil {
+		return 0, errors.Wrapf(err, "cannot lock account: %s", account)
+	}
+
+	return duration, nil
+}
+
+// Reset forgets the failed logins of an account after a successful login
+func (lockout Lockout) Reset(account string) (err error) {
+	err = lockout.cache.Delete(cacheKeyPrefixFailures + account)
+	if err != nil {
+		return errors.Wrapf(err, "cannot reset failed logins of account: %s", account)
+	}
+
+	return errors.Wrapf(lockout.cache.Delete(cacheKeyPrefixLockedUntil+account), "cannot unlock account: %s", account)
+}
+
+// delay doubles the base delay for every failure after the account was locked the first time
+func (lockout Lockout) delay(extraFailures int64) time.Duration {
+	delay := float64(lockout.options.BaseDelay) * math.Pow(2, float64(extraFailures))
+	if delay > float64(lockout.options.MaxDelay) {
+		return lockout.options.MaxDelay
+	}
+	return time.Duration(delay)
+}
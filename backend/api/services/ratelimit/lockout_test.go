@@ -0,0 +1,67 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/cache/memory"
+)
+
+var testLockoutOptions = LockoutOptions{MaxFailures: 3, BaseDelay: time.Minute, MaxDelay: 10 * time.Minute}
+
+func TestLockoutDelay(t *testing.T) {
+	tests := []struct {
+		extraFailures int64
+		delay         time.Duration
+	}{
+		{extraFailures: 0, delay: time.Minute},
+		{extraFailures: 1, delay: 2 * time.Minute},
+		{extraFailures: 3, delay: 8 * time.Minute},
+		{extraFailures: 4, delay: 10 * time.Minute},
+		{extraFailures: 100, delay: 10 * time.Minute},
+	}
+
+	lockout := NewLockout(memory.NewClient(), testLockoutOptions)
+	for _, test := range tests {
+		if delay := lockout.delay(test.extraFailures); delay != test.delay {
+			t.Errorf("delay(%d) = %s, want %s", test.extraFailures, delay, test.delay)
+		}
+	}
+}
+
+func TestLockoutRecordFailure(t *testing.T) {
+	lockout := NewLockout(memory.NewClient(), testLockoutOptions)
+
+	tests := []time.Duration{0, 0, time.Minute, 2 * time.Minute, 4 * time.Minute}
+	for failure, want := range tests {
+		lockedFor, err := lockout.RecordFailure("user:1")
+		if err != nil || lockedFor != want {
+			t.Errorf("RecordFailure() %d = %s, %v, want %s", failure+1, lockedFor, err, want)
+		}
+	}
+
+	if lockedFor, _ := lockout.LockedFor("user:1"); lockedFor <= 3*time.Minute {
+		t.Errorf("LockedFor() = %s, want about %s", lockedFor, 4*time.Minute)
+	}
+}
+
+func TestLockoutIsPerAccount(t *testing.T) {
+	lockout := NewLockout(memory.NewClient(), testLockoutOptions)
+	for failure := 0; failure < testLockoutOptions.MaxFailures; failure++ {
+		_, _ = lockout.RecordFailure("user:1")
+	}
+
+	if lockedFor, err := lockout.LockedFor("user:1"); err != nil || lockedFor == 0 {
+		t.Errorf("LockedFor(user:1) = %s, %v, want a lockout", lockedFor, err)
+	}
+	if lockedFor, err := lockout.LockedFor("user:2"); err != nil || lockedFor != 0 {
+		t.Errorf("LockedFor(user:2) = %s, %v, want 0", lockedFor, err)
+	}
+
+	if err := lockout.Reset("user:1"); err != nil {
+		t.Fatal(err)
+	}
+	if lockedFor, _ := lockout.LockedFor("user:1"); lockedFor != 0 {
+		t.Errorf("LockedFor() after Reset() = %s, want 0", lockedFor)
+	}
+}
@@ -0,0 +1,78 @@
+package recaptcha
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const verifyURL = "https://www.google.com/recaptcha/api/siteverify"
+
+// HTTPClient sends the verification requests
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// GoogleVerifier verifies reCAPTCHA tokens using the Google siteverify API
+type GoogleVerifier struct {
+	httpClient HTTPClient
+	secret     string
+}
+
+type verifyResponse struct {
+	Success    bool     `json:"success"`
+	ErrorCodes []string `json:"error-codes"`
+}
+
+// NewGoogleVerifier creates a new instance of the GoogleVerifier
+func NewGoogleVerifier(httpClient HTTPClient, secret string) Verifier {
+	return &GoogleVerifier{httpClient, secret}
+}
+
+// NewDefaultGoogleVerifier creates a GoogleVerifier with an HTTP client which times out
+func NewDefaultGoogleVerifier(secret string) Verifier {
+	return NewGoogleVerifier(&http.Client{Timeout: 10 * time.Second}, secret)
+}
+
+// Verify checks that a token was solved for this site and has not been used before
+func (verifier GoogleVerifier) Verify(token string) (bool, error) {
+	if strings.TrimSpace(token) == "" {
+		return false, nil
+	}
+
+	form := url.Values{"secret": {verifier.secret}, "response": {token}}
+	request, err := http.NewRequest(http.MethodPost, verifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, errors.Wrap(err, "cannot create reCAPTCHA request")
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	response, err := verifier.httpClient.Do(request)
+	if err != nil {
+		return false, errors.Wrap(err, "cannot verify reCAPTCHA token")
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	if response.StatusCode != http.StatusOK {
+		return false, errors.Errorf("the reCAPTCHA API responded with status %d", response.StatusCode)
+	}
+
+	var result verifyResponse
+	err = json.NewDecoder(response.Body).Decode(&result)
+	if err != nil {
+		return false, errors.Wrap(err, "cannot decode reCAPTCHA response")
+	}
+
+	// an invalid secret is a configuration error and not a wrong answer by the user
+	for _, code := range result.ErrorCodes {
+		if code == "missing-input-secret" || code == "invalid-input-secret" {
+			return false, errors.Errorf("the reCAPTCHA secret is invalid: %s", code)
+		}
+	}
+
+	return result.Success, nil
+}
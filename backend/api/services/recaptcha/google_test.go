@@ -0,0 +1,65 @@
+package recaptcha
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// responseClient responds to every request with a status and a body and keeps the last request
+type responseClient struct {
+	status  int
+	body    string
+	request *http.Request
+}
+
+func (client *responseClient) Do(request *http.Request) (*http.Response, error) {
+	client.request = request
+	return &http.Response{StatusCode: client.status, Body: ioutil.NopCloser(strings.NewReader(client.body))}, nil
+}
+
+func TestGoogleVerifierVerify(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   string
+		status  int
+		body    string
+		isValid bool
+		hasErr  bool
+	}{
+		{name: "solved token", token: "token", status: http.StatusOK, body: `{"success": true}`, isValid: true},
+		{name: "wrong token", token: "token", status: http.StatusOK, body: `{"success": false, "error-codes": ["invalid-input-response"]}`},
+		{name: "empty token", token: " ", status: http.StatusOK, body: `{"success": true}`},
+		{name: "invalid secret", token: "token", status: http.StatusOK, body: `{"success": false, "error-codes": ["invalid-input-secret"]}`, hasErr: true},
+		{name: "server error", token: "token", status: http.StatusInternalServerError, hasErr: true},
+		{name: "invalid response", token: "token", status: http.StatusOK, body: `<html>`, hasErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &responseClient{status: test.status, body: test.body}
+			isValid, err := NewGoogleVerifier(client, "secret").Verify(test.token)
+			if (err != nil) != test.hasErr {
+				t.Fatalf("Verify() error = %v, want an error %t", err, test.hasErr)
+			}
+			if isValid != test.isValid {
+				t.Errorf("Verify() = %t, want %t", isValid, test.isValid)
+			}
+
+			if strings.TrimSpace(test.token) == "" {
+				if client.request != nil {
+					t.Errorf("an empty token was sent to %s", client.request.URL)
+				}
+				return
+			}
+
+			if err := client.request.ParseForm(); err != nil {
+				t.Fatal(err)
+			}
+			if client.request.PostForm.Get("secret") != "secret" || client.request.PostForm.Get("response") != test.token {
+				t.Errorf("the form %v does not contain the secret and the token", client.request.PostForm)
+			}
+		})
+	}
+}
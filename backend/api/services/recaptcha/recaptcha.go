@@ -0,0 +1,6 @@
+package recaptcha
+
+// Verifier verifies the reCAPTCHA token which is sent by the client
+type Verifier interface {
+	Verify(token string) (bool, error)
+}
@@ -0,0 +1,58 @@
+package twofactor
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	recoveryCodeCount = 10
+	recoveryCodeBytes = 10
+)
+
+// GenerateRecoveryCodes creates the codes which can be used once when the authenticator app is lost.
+// Only the hashes are stored, the codes are shown to the user once.
+func GenerateRecoveryCodes() (codes []string, hashes []string, err error) {
+	for i := 0; i < recoveryCodeCount; i++ {
+		randomBytes := make([]byte, recoveryCodeBytes)
+		_, err = rand.Read(randomBytes)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "cannot generate recovery code")
+		}
+
+		// e.g "abcd-efgh-ijkl-mnop" is easier to copy than a single long code
+		code := strings.ToLower(encoding.EncodeToString(randomBytes))
+		code = code[0:4] + "-" + code[4:8] + "-" + code[8:12] + "-" + code[12:16]
+
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	return codes, hashes, nil
+}
+
+// MatchRecoveryCode returns the stored hash of the code when the code is valid.
+// The hash must be removed from the user so the code cannot be used again.
+func MatchRecoveryCode(hashes []string, code string) (hash string, isValid bool) {
+	codeHash := hashRecoveryCode(code)
+	for _, storedHash := range hashes {
+		if subtle.ConstantTimeCompare([]byte(storedHash), []byte(codeHash)) == 1 {
+			return storedHash, true
+		}
+	}
+
+	return "", false
+}
+
+// hashRecoveryCode hashes a code with SHA-256. The codes are random so a slow password hash is not needed.
+// The dashes and spaces are ignored so the code can also be typed without them.
+func hashRecoveryCode(code string) string {
+	code = strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(code))
+	hash := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,64 @@
+package twofactor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, hashes, err := GenerateRecoveryCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(codes) != recoveryCodeCount || len(hashes) != recoveryCodeCount {
+		t.Fatalf("GenerateRecoveryCodes() returned %d codes and %d hashes, want %d", len(codes), len(hashes), recoveryCodeCount)
+	}
+
+	seen := map[string]bool{}
+	for index, code := range codes {
+		if len(code) != 19 || strings.Count(code, "-") != 3 {
+			t.Errorf("code %q is not formatted as xxxx-xxxx-xxxx-xxxx", code)
+		}
+		if seen[code] {
+			t.Errorf("code %q was generated twice", code)
+		}
+		seen[code] = true
+
+		if hashes[index] == code || hashes[index] != hashRecoveryCode(code) {
+			t.Errorf("hash of code %q = %q, want %q", code, hashes[index], hashRecoveryCode(code))
+		}
+	}
+}
+
+func TestMatchRecoveryCode(t *testing.T) {
+	codes, hashes, err := GenerateRecoveryCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		code    string
+		hash    string
+		isValid bool
+	}{
+		{name: "first code", code: codes[0], hash: hashes[0], isValid: true},
+		{name: "last code", code: codes[len(codes)-1], hash: hashes[len(hashes)-1], isValid: true},
+		{name: "upper case", code: strings.ToUpper(codes[1]), hash: hashes[1], isValid: true},
+		{name: "without dashes", code: strings.ReplaceAll(codes[2], "-", ""), hash: hashes[2], isValid: true},
+		{name: "with spaces", code: strings.ReplaceAll(codes[3], "-", " "), hash: hashes[3], isValid: true},
+		{name: "wrong code", code: "aaaa-bbbb-cccc-dddd"},
+		{name: "empty code", code: ""},
+		{name: "hash instead of the code", code: hashes[0]},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hash, isValid := MatchRecoveryCode(hashes, test.code)
+			if isValid != test.isValid || hash != test.hash {
+				t.Errorf("MatchRecoveryCode() = %q, %t, want %q, %t", hash, isValid, test.hash, test.isValid)
+			}
+		})
+	}
+}
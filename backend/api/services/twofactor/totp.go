@@ -0,0 +1,148 @@
+package twofactor
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/cache"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/pkg/errors"
+)
+
+const (
+	// issuer is the name of the account in the authenticator app
+	issuer = "OV-Chipkaart Dashboard"
+
+	secretBytes = 20
+	codeDigits  = 6
+	period      = 30 * time.Second
+	// skew is the number of periods before and after the current period which are accepted for clocks which drift
+	skew = 1
+
+	enrolmentExpiration = 15 * time.Minute
+
+	cacheKeyPrefixEnrolment = "two-factor-enrolment:"
+	cacheKeyPrefixUsedCode  = "two-factor-used-code:"
+)
+
+var (
+	// ErrNoEnrolment is thrown when a code is verified without starting the enrolment or when the enrolment has expired
+	ErrNoEnrolment = errors.New("the two-factor authentication setup has expired, please start again")
+
+	encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+)
+
+// Enrolment is the secret which the user adds to an authenticator app
+type Enrolment struct {
+	Secret string
+	// URL is the otpauth:// URL which is shown as a QR code
+	URL string
+}
+
+// Service implements time-based one-time passwords (RFC 6238) which are compatible with authenticator apps
+type Service struct {
+	cache cache.Cache
+}
+
+// NewService creates a new instance of the two-factor authentication service
+func NewService(cache cache.Cache) Service {
+	return Service{cache}
+}
+
+// StartEnrolment generates a new secret for a user. The secret is kept in the cache until the user verifies a code.
+func (service Service) StartEnrolment(userID id.ID, accountName string) (enrolment Enrolment, err error) {
+	randomBytes := make([]byte, secretBytes)
+	_, err = rand.Read(randomBytes)
+	if err != nil {
+		return enrolment, errors.Wrap(err, "cannot generate two-factor secret")
+	}
+
+	enrolment.Secret = encoding.EncodeToString(randomBytes)
+	err = service.cache.Set(cacheKeyPrefixEnrolment+userID.String(), enrolment.Secret, enrolmentExpiration)
+	if err != nil {
+		return enrolment, errors.Wrapf(err, "cannot store two-factor enrolment for user with ID: %s", userID.String())
+	}
+
+	enrolment.URL = (&url.URL{
+		Scheme: "otpauth",
+		Host:   "totp",
+		Path:   "/" + issuer + ":" + accountName,
+		RawQuery: url.Values{
+			"secret":    {enrolment.Secret},
+			"issuer":    {issuer},
+			"algorithm": {"SHA1"},
+			"digits":    {strconv.Itoa(codeDigits)},
+			"period":    {strconv.Itoa(int(period.Seconds()))},
+		}.Encode(),
+	}).String()
+
+	return enrolment, nil
+}
+
+// PendingSecret returns the secret of an enrolment which has not been verified yet
+func (service Service) PendingSecret(userID id.ID) (secret string, err error) {
+	secret, err = service.cache.Get(cacheKeyPrefixEnrolment + userID.String())
+	if err == cache.ErrCacheMiss {
+		return secret, ErrNoEnrolment
+	}
+
+	return secret, errors.Wrapf(err, "cannot fetch two-factor enrolment for user with ID: %s", userID.String())
+}
+
+// FinishEnrolment deletes the pending secret after it has been saved on the user
+func (service Service) FinishEnrolment(userID id.ID) (err error) {
+	err = service.cache.Delete(cacheKeyPrefixEnrolment + userID.String())
+	return errors.Wrapf(err, "cannot delete two-factor enrolment for user with ID: %s", userID.String())
+}
+
+// VerifyCode checks the code of the authenticator app. A code can only be used once so an intercepted code cannot be replayed.
+func (service Service) VerifyCode(userID id.ID, secret string, code string) (isValid bool, err error) {
+	key, err := encoding.DecodeString(secret)
+	if err != nil {
+		return false, errors.Wrapf(err, "cannot decode two-factor secret of user with ID: %s", userID.String())
+	}
+
+	code = strings.ReplaceAll(code, " ", "")
+	counter := time.Now().Unix() / int64(period.Seconds())
+
+	for step := counter - skew; step <= counter+skew; step++ {
+		if subtle.ConstantTimeCompare([]byte(generateCode(key, step)), []byte(code)) != 1 {
+			continue
+		}
+
+		usedKey := cacheKeyPrefixUsedCode + userID.String() + ":" + strconv.FormatInt(step, 10)
+		// the code is marked as used in one operation so concurrent requests with the same code cannot both succeed
+		isFirstUse, err := service.cache.SetIfNotExists(usedKey, "", period*(2*skew+1))
+		if err != nil {
+			return false, errors.Wrapf(err, "cannot store the two-factor code of user with ID %s as used", userID.String())
+		}
+
+		return isFirstUse, nil
+	}
+
+	return false, nil
+}
+
+// generateCode computes the HOTP code (RFC 4226) of a counter
+func generateCode(key []byte, counter int64) string {
+	message := make([]byte, 8)
+	binary.BigEndian.PutUint64(message, uint64(counter))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(message)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", codeDigits, value%1000000)
+}
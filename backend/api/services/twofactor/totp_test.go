@@ -0,0 +1,108 @@
+package twofactor
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/cache/memory"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+)
+
+// rfc6238Secret is the SHA-1 secret of the test vectors in appendix B of RFC 6238
+var rfc6238Secret = []byte("12345678901234567890")
+
+func TestGenerateCode(t *testing.T) {
+	// the test vectors have 8 digits, the authenticator apps use the last 6 digits
+	tests := []struct {
+		unixTime int64
+		code     string
+	}{
+		{unixTime: 59, code: "287082"},
+		{unixTime: 1111111109, code: "081804"},
+		{unixTime: 1111111111, code: "050471"},
+		{unixTime: 1234567890, code: "005924"},
+		{unixTime: 2000000000, code: "279037"},
+		{unixTime: 20000000000, code: "353130"},
+	}
+
+	for _, test := range tests {
+		code := generateCode(rfc6238Secret, test.unixTime/int64(period.Seconds()))
+		if code != test.code {
+			t.Errorf("generateCode() at %d = %s, want %s", test.unixTime, code, test.code)
+		}
+	}
+}
+
+func TestServiceVerifyCode(t *testing.T) {
+	secret := encoding.EncodeToString(rfc6238Secret)
+	counter := time.Now().Unix() / int64(period.Seconds())
+
+	tests := []struct {
+		name    string
+		code    string
+		isValid bool
+	}{
+		{name: "current code", code: generateCode(rfc6238Secret, counter), isValid: true},
+		{name: "code with spaces", code: generateCode(rfc6238Secret, counter)[:3] + " " + generateCode(rfc6238Secret, counter)[3:], isValid: true},
+		{name: "previous code", code: generateCode(rfc6238Secret, counter-skew), isValid: true},
+		{name: "next code", code: generateCode(rfc6238Secret, counter+skew), isValid: true},
+		{name: "expired code", code: generateCode(rfc6238Secret, counter-skew-2)},
+		{name: "wrong code", code: "000000x"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := NewService(memory.NewClient())
+
+			isValid, err := service.VerifyCode(id.New(), secret, test.code)
+			if err != nil {
+				t.Fatalf("VerifyCode() error = %v", err)
+			}
+			if isValid != test.isValid {
+				t.Errorf("VerifyCode() = %t, want %t", isValid, test.isValid)
+			}
+		})
+	}
+}
+
+func TestServiceVerifyCodeCannotBeReplayed(t *testing.T) {
+	service := NewService(memory.NewClient())
+	secret := encoding.EncodeToString(rfc6238Secret)
+	code := generateCode(rfc6238Secret, time.Now().Unix()/int64(period.Seconds()))
+	userID := id.New()
+
+	const requests = 10
+	var wg sync.WaitGroup
+	results := make(chan bool, requests)
+	for index := 0; index < requests; index++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			isValid, err := service.VerifyCode(userID, secret, code)
+			if err != nil {
+				t.Error(err)
+			}
+			results <- isValid
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	accepted := 0
+	for isValid := range results {
+		if isValid {
+			accepted++
+		}
+	}
+
+	if accepted != 1 {
+		t.Errorf("the same code was accepted %d times, want 1", accepted)
+	}
+
+	// another user with the same secret is not affected
+	isValid, err := service.VerifyCode(id.New(), secret, code)
+	if err != nil || !isValid {
+		t.Errorf("VerifyCode() for another user = %t, %v, want true", isValid, err)
+	}
+}
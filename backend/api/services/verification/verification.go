@@ -0,0 +1,139 @@
+package verification
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/cache"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/pkg/errors"
+)
+
+// Purpose is the action which a token allows. A token can only be used for the purpose it was created for.
+type Purpose string
+
+const (
+	// PurposeEmailVerification is the purpose of the token in the link of the verification email
+	PurposeEmailVerification = Purpose("email-verification")
+
+	// PurposePasswordReset is the purpose of the token in the link of the password reset email
+	PurposePasswordReset = Purpose("password-reset")
+
+	// PurposeTwoFactorLogin is the purpose of the challenge token which is returned by the login mutation when
+	// two-factor authentication is enabled
+	PurposeTwoFactorLogin = Purpose("two-factor-login")
+
+	cacheKeyPrefix = "verification-token:"
+	emailSeparator = " "
+	tokenBytes     = 32
+)
+
+var (
+	// ErrInvalidToken is thrown when a token does not exist, has expired or has already been used
+	ErrInvalidToken = errors.New("the link is invalid or has expired")
+)
+
+// Service creates single use tokens which expire
+type Service struct {
+	cache cache.Cache
+}
+
+// NewService creates a new instance of the verification service
+func NewService(cache cache.Cache) Service {
+	return Service{cache}
+}
+
+// Create generates a token for a user which is valid until it is used or until it expires
+func (service Service) Create(purpose Purpose, userID id.ID, expiration time.Duration) (token string, err error) {
+	token, err = service.create(purpose, userID.String(), expiration)
+	if err != nil {
+		return token, errors.Wrapf(err, "cannot store %s token for user with ID: %s", purpose, userID.String())
+	}
+
+	return token, nil
+}
+
+// CreateForEmail generates a token like Create which is bound to the email address it is sent to so the token can be
+// rejected after the user changed the email address.
+func (service Service) CreateForEmail(purpose Purpose, userID id.ID, email string, expiration time.Duration) (token string, err error) {
+	token, err = service.create(purpose, userID.String()+emailSeparator+email, expiration)
+	if err != nil {
+		return token, errors.Wrapf(err, "cannot store %s token for user with ID: %s", purpose, userID.String())
+	}
+
+	return token, nil
+}
+
+// Find returns the user ID of a token without using the token e.g when a token can be retried until it expires
+func (service Service) Find(purpose Purpose, token string) (userID id.ID, err error) {
+	value, err := service.cache.Get(cacheKey(purpose, token))
+	if err == cache.ErrCacheMiss {
+		return userID, ErrInvalidToken
+	}
+	if err != nil {
+		return userID, errors.Wrapf(err, "cannot fetch %s token", purpose)
+	}
+
+	return id.FromString(value)
+}
+
+// Consume returns the user ID of a token and deletes the token so it cannot be used again. The token is fetched and
+// deleted in one operation so concurrent requests with the same token cannot both use it.
+func (service Service) Consume(purpose Purpose, token string) (userID id.ID, err error) {
+	value, err := service.consume(purpose, token)
+	if err != nil {
+		return userID, err
+	}
+
+	return id.FromString(value)
+}
+
+// ConsumeForEmail consumes a token which was created with CreateForEmail and returns the email address which the token
+// was sent to. The caller rejects the token when it is not the current email address of the user.
+func (service Service) ConsumeForEmail(purpose Purpose, token string) (userID id.ID, email string, err error) {
+	value, err := service.consume(purpose, token)
+	if err != nil {
+		return userID, email, err
+	}
+
+	parts := strings.SplitN(value, emailSeparator, 2)
+	if len(parts) != 2 {
+		return userID, email, ErrInvalidToken
+	}
+
+	userID, err = id.FromString(parts[0])
+	return userID, parts[1], err
+}
+
+func (service Service) create(purpose Purpose, value string, expiration time.Duration) (token string, err error) {
+	randomBytes := make([]byte, tokenBytes)
+	_, err = rand.Read(randomBytes)
+	if err != nil {
+		return token, errors.Wrap(err, "cannot generate verification token")
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(randomBytes)
+	return token, service.cache.Set(cacheKey(purpose, token), value, expiration)
+}
+
+func (service Service) consume(purpose Purpose, token string) (value string, err error) {
+	value, err = service.cache.GetAndDelete(cacheKey(purpose, token))
+	if err == cache.ErrCacheMiss {
+		return value, ErrInvalidToken
+	}
+	if err != nil {
+		return value, errors.Wrapf(err, "cannot consume %s token", purpose)
+	}
+
+	return value, nil
+}
+
+// cacheKey hashes the token so the tokens in the cache cannot be used if the cache is leaked
+func cacheKey(purpose Purpose, token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return cacheKeyPrefix + string(purpose) + ":" + hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,126 @@
+package verification
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/api/cache/memory"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/id"
+)
+
+func TestServiceConsume(t *testing.T) {
+	tests := []struct {
+		name    string
+		purpose Purpose
+		token   func(token string) string
+		wait    time.Duration
+		err     error
+	}{
+		{name: "valid token", purpose: PurposeEmailVerification, token: func(token string) string { return token }},
+		{name: "other purpose", purpose: PurposePasswordReset, token: func(token string) string { return token }, err: ErrInvalidToken},
+		{name: "unknown token", purpose: PurposeEmailVerification, token: func(token string) string { return token + "x" }, err: ErrInvalidToken},
+		{name: "expired token", purpose: PurposeEmailVerification, token: func(token string) string { return token }, wait: 2 * time.Hour, err: ErrInvalidToken},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			now := time.Now()
+			cache := memory.NewClient()
+			cache.SetClock(func() time.Time { return now })
+			service := NewService(cache)
+			userID := id.New()
+
+			token, err := service.Create(PurposeEmailVerification, userID, time.Hour)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			now = now.Add(test.wait)
+			consumedUserID, err := service.Consume(test.purpose, test.token(token))
+			if err != test.err {
+				t.Fatalf("Consume() error = %v, want %v", err, test.err)
+			}
+
+			if err == nil && consumedUserID != userID {
+				t.Errorf("Consume() = %s, want %s", consumedUserID, userID)
+			}
+		})
+	}
+}
+
+func TestServiceConsumeOnce(t *testing.T) {
+	service := NewService(memory.NewClient())
+
+	token, err := service.Create(PurposePasswordReset, id.New(), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const requests = 10
+	var wg sync.WaitGroup
+	results := make(chan error, requests)
+	for index := 0; index < requests; index++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := service.Consume(PurposePasswordReset, token)
+			results <- err
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	consumed := 0
+	for err := range results {
+		if err == nil {
+			consumed++
+		} else if err != ErrInvalidToken {
+			t.Errorf("Consume() error = %v", err)
+		}
+	}
+
+	if consumed != 1 {
+		t.Errorf("the token was consumed %d times, want 1", consumed)
+	}
+}
+
+func TestServiceFindDoesNotConsume(t *testing.T) {
+	service := NewService(memory.NewClient())
+	userID := id.New()
+
+	token, err := service.Create(PurposeTwoFactorLogin, userID, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		foundUserID, err := service.Find(PurposeTwoFactorLogin, token)
+		if err != nil || foundUserID != userID {
+			t.Errorf("Find() attempt %d = %s, %v, want %s", attempt, foundUserID, err, userID)
+		}
+	}
+}
+
+func TestServiceConsumeForEmail(t *testing.T) {
+	service := NewService(memory.NewClient())
+	userID := id.New()
+
+	token, err := service.CreateForEmail(PurposeEmailVerification, userID, "jane@example.com", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = service.Consume(PurposePasswordReset, token); err != ErrInvalidToken {
+		t.Errorf("Consume() with another purpose error = %v, want %v", err, ErrInvalidToken)
+	}
+
+	consumedUserID, email, err := service.ConsumeForEmail(PurposeEmailVerification, token)
+	if err != nil || consumedUserID != userID || email != "jane@example.com" {
+		t.Errorf("ConsumeForEmail() = %s, %q, %v, want %s, %q", consumedUserID, email, err, userID, "jane@example.com")
+	}
+
+	if _, _, err = service.ConsumeForEmail(PurposeEmailVerification, token); err != ErrInvalidToken {
+		t.Errorf("ConsumeForEmail() of a used token error = %v, want %v", err, ErrInvalidToken)
+	}
+}
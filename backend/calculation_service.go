@@ -0,0 +1,101 @@
+package main
+
+import (
+	"time"
+)
+
+// CalculationService calculates the price of every discount product for an import and stores the results together
+// with the versions of the rules and the prices which were used.
+type CalculationService struct {
+	operators     *OperatorRegistry
+	repository    CalculationResultsRepository
+	referenceData ReferenceDataService
+}
+
+// NewCalculationService creates a new instance of the CalculationService
+func NewCalculationService(operators *OperatorRegistry, repository CalculationResultsRepository, referenceData ReferenceDataService) CalculationService {
+	return CalculationService{operators, repository, referenceData}
+}
+
+// ProductsCount returns the number of discount products which are calculated in a run
+func (service CalculationService) ProductsCount() int {
+	return len(service.operators.DiscountProducts())
+}
+
+// Calculate runs all the calculators for the enriched records of an import and stores the results.
+// onProgress is called after each product.
+func (service CalculationService) Calculate(transactionID TransactionID, jobID *TransactionID, records []EnrichedRecord, onProgress func()) (results []StoredCalculationResult, err error) {
+	results, err = service.Compare(transactionID, jobID, records, onProgress)
+	if err != nil {
+		return results, err
+	}
+
+	err = service.repository.Store(results)
+	if err != nil {
+		return results, err
+	}
+
+	return results, nil
+}
+
+// Compare runs all the calculators for the enriched records of an import without storing the results
+func (service CalculationService) Compare(transactionID TransactionID, jobID *TransactionID, records []EnrichedRecord, onProgress func()) (results []StoredCalculationResult, err error) {
+	versions, err := service.referenceData.CurrentVersions()
+	if err != nil {
+		return results, err
+	}
+
+	runID := NewTransactionID()
+	calculatedAt := time.Now().UTC()
+
+	for _, operator := range service.operators.Operators() {
+		for _, product := range operator.DiscountProducts() {
+			result := product.Calculate(records)
+			results = append(results, StoredCalculationResult{
+				ID:                    NewTransactionID(),
+				RunID:                 runID,
+				TransactionID:         transactionID,
+				JobID:                 jobID,
+				Product:               product.Name(),
+				CompanyName:           operator.CompanyName(),
+				RuleSetVersion:        product.RuleSetVersion(),
+				FareTableVersion:      operator.FareTableVersion(versions),
+				ReferenceDataVersions: versions,
+				CalculatedAt:          calculatedAt,
+				Currency:              result.TotalPrice().Currency().String(),
+				JourneyCount:          result.JourneyCount,
+				JourneysPrice:         result.JourneysPrice.Value(),
+				SubscriptionPrice:     result.SubscriptionPrice.Value(),
+				TotalPrice:            result.TotalPrice().Value(),
+			})
+
+			if onProgress != nil {
+				onProgress()
+			}
+		}
+	}
+
+	return service.markCheapest(results), nil
+}
+
+// markCheapest flags the cheapest product of each company. A traveller can only use the products of a company for the
+// journeys with that company so products of different companies are not compared.
+func (service CalculationService) markCheapest(results []StoredCalculationResult) []StoredCalculationResult {
+	cheapest := map[CompanyName]int{}
+	for index, result := range results {
+		if result.JourneyCount == 0 {
+			continue
+		}
+
+		current, ok := cheapest[result.CompanyName]
+		if !ok || result.TotalPrice < results[current].TotalPrice {
+			cheapest[result.CompanyName] = index
+		}
+	}
+
+	for _, index := range cheapest {
+		results[index].IsCheapest = true
+	}
+
+	return results
+}
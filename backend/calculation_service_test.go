@@ -0,0 +1,84 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestCalculationServiceMarkCheapestNSProduct(t *testing.T) {
+	tests := []struct {
+		name     string
+		price    int
+		records  []EnrichedRecord
+		cheapest string
+	}{
+		{
+			name:     "a single short journey is cheapest without a subscription",
+			price:    300,
+			records:  nsJourneys(1, 10),
+			cheapest: productNSNoDiscount,
+		},
+		{
+			name:     "a few off-peak journeys are cheapest with Dal Voordeel",
+			price:    1000,
+			records:  nsJourneys(4, 10),
+			cheapest: productNSDalVoordeel,
+		},
+		{
+			name:     "many off-peak journeys are cheapest with Dal Vrij",
+			price:    1000,
+			records:  nsJourneys(60, 10),
+			cheapest: productNSDalVrij,
+		},
+		{
+			name:     "many peak journeys are cheapest with Altijd Voordeel",
+			price:    1000,
+			records:  nsJourneys(60, 8),
+			cheapest: productNSAltijdVoordeel,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var results []StoredCalculationResult
+			for _, product := range newTestNSOperator(test.price).DiscountProducts() {
+				result := product.Calculate(test.records)
+				results = append(results, StoredCalculationResult{
+					Product:           product.Name(),
+					CompanyName:       result.CompanyName,
+					JourneyCount:      result.JourneyCount,
+					JourneysPrice:     result.JourneysPrice.Value(),
+					SubscriptionPrice: result.SubscriptionPrice.Value(),
+					TotalPrice:        result.TotalPrice().Value(),
+				})
+			}
+
+			var cheapest []string
+			for _, result := range (CalculationService{}).markCheapest(results) {
+				if result.IsCheapest {
+					cheapest = append(cheapest, result.Product)
+				}
+			}
+
+			if len(cheapest) != 1 || cheapest[0] != test.cheapest {
+				t.Errorf("cheapest products = %v, want [%s], results: %+v", cheapest, test.cheapest, results)
+			}
+		})
+	}
+}
+
+func TestCalculationServiceMarkCheapestPerCompany(t *testing.T) {
+	results := CalculationService{}.markCheapest([]StoredCalculationResult{
+		{Product: productNSNoDiscount, CompanyName: companyNameNS, JourneyCount: 2, TotalPrice: 2000},
+		{Product: productNSDalVoordeel, CompanyName: companyNameNS, JourneyCount: 2, TotalPrice: 1760},
+		{Product: productRETDalVoordeel, CompanyName: companyNameRET, JourneyCount: 3, TotalPrice: 900},
+		{Product: productRETMaand, CompanyName: companyNameRET, JourneyCount: 3, TotalPrice: 9380},
+		{Product: "GVB", CompanyName: companyNameGVB, JourneyCount: 0, TotalPrice: 0},
+	})
+
+	want := []bool{false, true, true, false, false}
+	for index, result := range results {
+		if result.IsCheapest != want[index] {
+			t.Errorf("%s: IsCheapest = %t, want %t", result.Product, result.IsCheapest, want[index])
+		}
+	}
+}
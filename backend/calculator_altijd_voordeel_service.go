@@ -4,6 +4,9 @@ import (
 	"github.com/pkg/errors"
 )
 
+// NS Altijd Voordeel subscription for 2020. It gives a discount on all journeys.
+const nsAltijdVoordeelMonthlyPrice = 2690
+
 // NSAltijdVoordeelCalculator calculates the price of journeys with the AltijdVoordeel discount
 type NSAltijdVoordeelCalculator struct {
 	priceFetcher   NSPriceFetcherService
@@ -30,6 +33,8 @@ type NSAltijdVoordeelCalculatorResult struct {
 	PeakSupplementCount     int
 	OffPeakSupplementPrice  Money
 	OffPeakSupplementCount  int
+	SubscriptionPrice       Money
+	SubscriptionMonths      int
 	Error                   EnrichedRecordsError
 }
 
@@ -40,6 +45,7 @@ func (result *NSAltijdVoordeelCalculatorResult) init() {
 	result.PeakSecondClassPrice = NewEUR(0)
 	result.PeakSupplementPrice = NewEUR(0)
 	result.OffPeakSupplementPrice = NewEUR(0)
+	result.SubscriptionPrice = NewEUR(0)
 }
 
 // addOffPeakJourneyPrice adds the price of an NSJourney when not in peak period
@@ -110,5 +116,8 @@ func (calculator NSAltijdVoordeelCalculator) Calculate(records []EnrichedRecord)
 		}
 	}
 
+	result.SubscriptionMonths = subscriptionMonths(records, EnrichedRecord.IsNSJourney)
+	result.SubscriptionPrice = NewEUR(result.SubscriptionMonths * nsAltijdVoordeelMonthlyPrice)
+
 	return result
 }
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestNSAltijdVoordeelCalculatorCalculate(t *testing.T) {
+	tests := []struct {
+		name                    string
+		records                 []EnrichedRecord
+		peakSecondClassPrice    int
+		offPeakSecondClassPrice int
+		subscriptionPrice       int
+	}{
+		{
+			name: "no journeys",
+		},
+		{
+			name:                 "peak journeys get 20% discount",
+			records:              nsJourneys(2, 8),
+			peakSecondClassPrice: 1600,
+			subscriptionPrice:    nsAltijdVoordeelMonthlyPrice,
+		},
+		{
+			name:                    "off-peak journeys get 40% discount",
+			records:                 nsJourneys(3, 10),
+			offPeakSecondClassPrice: 1800,
+			subscriptionPrice:       nsAltijdVoordeelMonthlyPrice,
+		},
+		{
+			name:                 "journeys in two months pay two months of subscription",
+			records:              append(nsJourneys(1, 8), nsJourney(nsJourneys(1, 8)[0].StartTime.ToTime().AddDate(0, 1, 0))),
+			peakSecondClassPrice: 1600,
+			subscriptionPrice:    2 * nsAltijdVoordeelMonthlyPrice,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			operator := newTestNSOperator(1000)
+			result := NewNSAltijdVoordeelCalculator(operator.priceFetcher, operator.offPeakService).Calculate(test.records)
+
+			if result.PeakSecondClassPrice.Value() != test.peakSecondClassPrice {
+				t.Errorf("peak price = %d, want %d", result.PeakSecondClassPrice.Value(), test.peakSecondClassPrice)
+			}
+
+			if result.OffPeakSecondClassPrice.Value() != test.offPeakSecondClassPrice {
+				t.Errorf("off-peak price = %d, want %d", result.OffPeakSecondClassPrice.Value(), test.offPeakSecondClassPrice)
+			}
+
+			if result.SubscriptionPrice.Value() != test.subscriptionPrice {
+				t.Errorf("subscription price = %d, want %d", result.SubscriptionPrice.Value(), test.subscriptionPrice)
+			}
+		})
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"github.com/pkg/errors"
 )
 
+// NS Dal Voordeel subscription for 2020. It gives a discount on all journeys made during off-peak hours.
+const nsDalVoordeelMonthlyPrice = 560
+
 const (
 	offPeakPriceDiscount = float64(0.6)
 	peakPriceDiscount    = float64(0.80)
@@ -35,6 +38,8 @@ type NSDalVoordeelCalculatorResult struct {
 	PeakSupplementCount     int
 	OffPeakSupplementPrice  Money
 	OffPeakSupplementCount  int
+	SubscriptionPrice       Money
+	SubscriptionMonths      int
 	Error                   EnrichedRecordsError
 }
 
@@ -45,6 +50,7 @@ func (result *NSDalVoordeelCalculatorResult) init() {
 	result.PeakSecondClassPrice = NewEUR(0)
 	result.PeakSupplementPrice = NewEUR(0)
 	result.OffPeakSupplementPrice = NewEUR(0)
+	result.SubscriptionPrice = NewEUR(0)
 }
 
 // addOffPeakJourneyPrice adds the price of an NSJourney when not in peak period
@@ -115,5 +121,8 @@ func (calculator NSDalVoordeelCalculator) Calculate(records []EnrichedRecord) (r
 		}
 	}
 
+	result.SubscriptionMonths = subscriptionMonths(records, EnrichedRecord.IsNSJourney)
+	result.SubscriptionPrice = NewEUR(result.SubscriptionMonths * nsDalVoordeelMonthlyPrice)
+
 	return result
 }
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestNSDalVoordeelCalculatorCalculate(t *testing.T) {
+	tests := []struct {
+		name                    string
+		records                 []EnrichedRecord
+		peakSecondClassPrice    int
+		offPeakSecondClassPrice int
+		subscriptionPrice       int
+	}{
+		{
+			name: "no journeys",
+		},
+		{
+			name:                 "peak journeys are charged the full price",
+			records:              nsJourneys(2, 8),
+			peakSecondClassPrice: 2000,
+			subscriptionPrice:    nsDalVoordeelMonthlyPrice,
+		},
+		{
+			name:                    "off-peak journeys get 40% discount",
+			records:                 nsJourneys(3, 10),
+			offPeakSecondClassPrice: 1800,
+			subscriptionPrice:       nsDalVoordeelMonthlyPrice,
+		},
+		{
+			name:                    "peak and off-peak journeys",
+			records:                 append(nsJourneys(1, 8), nsJourneys(2, 20)...),
+			peakSecondClassPrice:    1000,
+			offPeakSecondClassPrice: 1200,
+			subscriptionPrice:       nsDalVoordeelMonthlyPrice,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			operator := newTestNSOperator(1000)
+			result := NewNSDalVoordeelCalculator(operator.priceFetcher, operator.offPeakService).Calculate(test.records)
+
+			if result.PeakSecondClassPrice.Value() != test.peakSecondClassPrice {
+				t.Errorf("peak price = %d, want %d", result.PeakSecondClassPrice.Value(), test.peakSecondClassPrice)
+			}
+
+			if result.OffPeakSecondClassPrice.Value() != test.offPeakSecondClassPrice {
+				t.Errorf("off-peak price = %d, want %d", result.OffPeakSecondClassPrice.Value(), test.offPeakSecondClassPrice)
+			}
+
+			if result.SubscriptionPrice.Value() != test.subscriptionPrice {
+				t.Errorf("subscription price = %d, want %d", result.SubscriptionPrice.Value(), test.subscriptionPrice)
+			}
+		})
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"github.com/pkg/errors"
 )
 
+// NS Dal Vrij subscription for 2020. It gives free travel during off-peak hours.
+const nsDalVrijMonthlyPrice = 11450
+
 // NSDalVrijCalculator calculates the price of journeys with the DalVrij discount
 type NSDalVrijCalculator struct {
 	priceFetcher   NSPriceFetcherService
@@ -30,6 +33,8 @@ type NSDalVrijCalculatorResult struct {
 	PeakSupplementCount     int
 	OffPeakSupplementPrice  Money
 	OffPeakSupplementCount  int
+	SubscriptionPrice       Money
+	SubscriptionMonths      int
 	Error                   EnrichedRecordsError
 }
 
@@ -40,6 +45,7 @@ func (result *NSDalVrijCalculatorResult) init() {
 	result.PeakSecondClassPrice = NewEUR(0)
 	result.PeakSupplementPrice = NewEUR(0)
 	result.OffPeakSupplementPrice = NewEUR(0)
+	result.SubscriptionPrice = NewEUR(0)
 }
 
 // addOffPeakJourneyPrice adds the price of an NSJourney when not in peak period
@@ -108,5 +114,8 @@ func (calculator NSDalVrijCalculator) Calculate(records []EnrichedRecord) (resul
 		}
 	}
 
+	result.SubscriptionMonths = subscriptionMonths(records, EnrichedRecord.IsNSJourney)
+	result.SubscriptionPrice = NewEUR(result.SubscriptionMonths * nsDalVrijMonthlyPrice)
+
 	return result
 }
@@ -0,0 +1,64 @@
+package main
+
+// DistanceFareNoDiscountCalculator calculates the price of journeys priced by distance when there are no discounts.
+type DistanceFareNoDiscountCalculator struct {
+	fareService    DistanceFareService
+	offPeakService NSOffPeakService
+}
+
+// NewDistanceFareNoDiscountCalculator creates a new instance of a DistanceFareNoDiscountCalculator
+func NewDistanceFareNoDiscountCalculator(fareService DistanceFareService, offPeakService NSOffPeakService) *DistanceFareNoDiscountCalculator {
+	return &DistanceFareNoDiscountCalculator{
+		fareService:    fareService,
+		offPeakService: offPeakService,
+	}
+}
+
+// DistanceFareNoDiscountCalculatorResult represents the calculation result of journeys priced by distance
+type DistanceFareNoDiscountCalculatorResult struct {
+	OffPeakPrice        Money
+	OffPeakJourneyCount int
+	PeakPrice           Money
+	PeakJourneyCount    int
+}
+
+func (result *DistanceFareNoDiscountCalculatorResult) init() {
+	result.OffPeakPrice = NewEUR(0)
+	result.PeakPrice = NewEUR(0)
+}
+
+// addOffPeakJourneyPrice adds the price of a journey when not in peak period
+func (result *DistanceFareNoDiscountCalculatorResult) addOffPeakJourneyPrice(price Money) {
+	result.OffPeakPrice = result.OffPeakPrice.AddAmount(price.Value())
+	result.OffPeakJourneyCount++
+}
+
+// addPeakJourneyPrice adds the price of a journey during the peak period
+func (result *DistanceFareNoDiscountCalculatorResult) addPeakJourneyPrice(price Money) {
+	result.PeakPrice = result.PeakPrice.AddAmount(price.Value())
+	result.PeakJourneyCount++
+}
+
+// TotalPrice returns the price of all the journeys
+func (result DistanceFareNoDiscountCalculatorResult) TotalPrice() Money {
+	return result.OffPeakPrice.AddAmount(result.PeakPrice.Value())
+}
+
+// Calculate calculates the total price
+func (calculator DistanceFareNoDiscountCalculator) Calculate(records []EnrichedRecord) (result DistanceFareNoDiscountCalculatorResult) {
+	result.init()
+	for _, record := range records {
+		if !calculator.fareService.IsJourney(record) {
+			continue
+		}
+
+		price := calculator.fareService.Price(record.Distance)
+		if calculator.offPeakService.IsOffPeak(record.StartTime.ToTime()) {
+			result.addOffPeakJourneyPrice(price)
+		} else {
+			result.addPeakJourneyPrice(price)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestDistanceFareNoDiscountCalculatorCalculate(t *testing.T) {
+	records := append(retJourneys(1, 8, 10000), append(retJourneys(3, 20, 0), nsJourneys(2, 8)...)...)
+	offPeakService := NewNSOffPeakService(noHolidaysRepository{}, missCache{}, discardErrorHandler{})
+	result := NewDistanceFareNoDiscountCalculator(newTestRETFareService(), offPeakService).Calculate(records)
+
+	// peak and off-peak journeys have the same price and the journeys of other operators are skipped
+	if result.PeakPrice.Value() != 261 || result.PeakJourneyCount != 1 {
+		t.Errorf("peak = %d for %d journeys, want %d for %d journeys", result.PeakPrice.Value(), result.PeakJourneyCount, 261, 1)
+	}
+
+	if result.OffPeakPrice.Value() != 3*retBoardingTariff || result.OffPeakJourneyCount != 3 {
+		t.Errorf("off-peak = %d for %d journeys, want %d for %d journeys", result.OffPeakPrice.Value(), result.OffPeakJourneyCount, 3*retBoardingTariff, 3)
+	}
+
+	if result.TotalPrice().Value() != 261+3*retBoardingTariff {
+		t.Errorf("total price = %d, want %d", result.TotalPrice().Value(), 261+3*retBoardingTariff)
+	}
+}
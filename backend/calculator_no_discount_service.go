@@ -49,6 +49,8 @@ func (result *NSNoDiscountCalculatorResult) init() {
 
 // addOffPeakJourneyPrice adds the price of an NSJourney when not in peak period
 func (result *NSNoDiscountCalculatorResult) addOffPeakJourneyPrice(journey NSJourneyPrice) {
+	result.OffPeakFirstClassPrice = result.OffPeakFirstClassPrice.AddAmount(NewEUR(journey.FirstClassSingleFarePrice).Value())
+	result.OffPeakSecondClassPrice = result.OffPeakSecondClassPrice.AddAmount(NewEUR(journey.SecondClassSingleFarePrice).Value())
 	result.OffPeakJourneyCount++
 }
 
@@ -87,6 +89,7 @@ func (calculator NSNoDiscountCalculator) Calculate(records []EnrichedRecord) (re
 		errorRecords []ErrorEnrichedRecord
 	)
 
+	result.init()
 	for _, record := range records {
 		isOffPeak := calculator.offPeakService.IsOffPeak(record.StartTime.ToTime())
 		if record.IsNSJourney() {
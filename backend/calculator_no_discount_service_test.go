@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+
+	"golang.org/x/text/currency"
+)
+
+func TestNSNoDiscountCalculatorCalculate(t *testing.T) {
+	tests := []struct {
+		name                    string
+		records                 []EnrichedRecord
+		peakSecondClassPrice    int
+		peakJourneyCount        int
+		offPeakSecondClassPrice int
+		offPeakJourneyCount     int
+	}{
+		{
+			name: "no journeys",
+		},
+		{
+			name:                 "peak journeys",
+			records:              nsJourneys(2, 8),
+			peakSecondClassPrice: 2000,
+			peakJourneyCount:     2,
+		},
+		{
+			name:                    "off-peak journeys are charged the full price",
+			records:                 nsJourneys(3, 10),
+			offPeakSecondClassPrice: 3000,
+			offPeakJourneyCount:     3,
+		},
+		{
+			name:                    "peak and off-peak journeys",
+			records:                 append(nsJourneys(1, 8), nsJourneys(2, 20)...),
+			peakSecondClassPrice:    1000,
+			peakJourneyCount:        1,
+			offPeakSecondClassPrice: 2000,
+			offPeakJourneyCount:     2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			operator := newTestNSOperator(1000)
+			result := NewNSNoDiscountCalculator(operator.priceFetcher, operator.offPeakService).Calculate(test.records)
+
+			if result.PeakSecondClassPrice.Value() != test.peakSecondClassPrice || result.PeakJourneyCount != test.peakJourneyCount {
+				t.Errorf("peak = %d for %d journeys, want %d for %d journeys", result.PeakSecondClassPrice.Value(), result.PeakJourneyCount, test.peakSecondClassPrice, test.peakJourneyCount)
+			}
+
+			if result.OffPeakSecondClassPrice.Value() != test.offPeakSecondClassPrice || result.OffPeakJourneyCount != test.offPeakJourneyCount {
+				t.Errorf("off-peak = %d for %d journeys, want %d for %d journeys", result.OffPeakSecondClassPrice.Value(), result.OffPeakJourneyCount, test.offPeakSecondClassPrice, test.offPeakJourneyCount)
+			}
+
+			if result.OffPeakSecondClassPrice.Currency() != currency.EUR || result.PeakSecondClassPrice.Currency() != currency.EUR {
+				t.Errorf("currencies = %s and %s, want EUR", result.PeakSecondClassPrice.Currency(), result.OffPeakSecondClassPrice.Currency())
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+// RET Dal Voordeel subscription for 2020. It gives a discount on all journeys made during off-peak hours.
+const (
+	retDalVoordeelMonthlyPrice = 250
+	retDalVoordeelDiscount     = float64(0.66)
+)
+
+// RETDalVoordeelCalculator calculates the price of RET journeys with the Dal Voordeel subscription
+type RETDalVoordeelCalculator struct {
+	fareService    DistanceFareService
+	offPeakService NSOffPeakService
+}
+
+// NewRETDalVoordeelCalculator creates a new instance of an RETDalVoordeelCalculator
+func NewRETDalVoordeelCalculator(fareService DistanceFareService, offPeakService NSOffPeakService) *RETDalVoordeelCalculator {
+	return &RETDalVoordeelCalculator{
+		fareService:    fareService,
+		offPeakService: offPeakService,
+	}
+}
+
+// RETDalVoordeelCalculatorResult represents the calculation result of RET journeys
+type RETDalVoordeelCalculatorResult struct {
+	OffPeakPrice        Money
+	OffPeakJourneyCount int
+	PeakPrice           Money
+	PeakJourneyCount    int
+	SubscriptionPrice   Money
+	SubscriptionMonths  int
+}
+
+func (result *RETDalVoordeelCalculatorResult) init() {
+	result.OffPeakPrice = NewEUR(0)
+	result.PeakPrice = NewEUR(0)
+	result.SubscriptionPrice = NewEUR(0)
+}
+
+// addOffPeakJourneyPrice adds the discounted price of a RET journey when not in peak period
+func (result *RETDalVoordeelCalculatorResult) addOffPeakJourneyPrice(price Money) {
+	result.OffPeakPrice = result.OffPeakPrice.AddAmount(price.Multiply(retDalVoordeelDiscount).Value())
+	result.OffPeakJourneyCount++
+}
+
+// addPeakJourneyPrice adds the price of a RET journey during the peak period
+func (result *RETDalVoordeelCalculatorResult) addPeakJourneyPrice(price Money) {
+	result.PeakPrice = result.PeakPrice.AddAmount(price.Value())
+	result.PeakJourneyCount++
+}
+
+// TotalPrice returns the price of all the journeys including the subscription
+func (result RETDalVoordeelCalculatorResult) TotalPrice() Money {
+	return result.OffPeakPrice.AddAmount(result.PeakPrice.Value()).AddAmount(result.SubscriptionPrice.Value())
+}
+
+// Calculate calculates the total price
+func (calculator RETDalVoordeelCalculator) Calculate(records []EnrichedRecord) (result RETDalVoordeelCalculatorResult) {
+	result.init()
+	for _, record := range records {
+		if !calculator.fareService.IsJourney(record) {
+			continue
+		}
+
+		price := calculator.fareService.Price(record.Distance)
+		if calculator.offPeakService.IsOffPeak(record.StartTime.ToTime()) {
+			result.addOffPeakJourneyPrice(price)
+		} else {
+			result.addPeakJourneyPrice(price)
+		}
+	}
+
+	result.SubscriptionMonths = calculator.fareService.SubscriptionMonths(records)
+	result.SubscriptionPrice = NewEUR(result.SubscriptionMonths * retDalVoordeelMonthlyPrice)
+
+	return result
+}
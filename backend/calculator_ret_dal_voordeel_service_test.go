@@ -0,0 +1,58 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestRETDalVoordeelCalculatorCalculate(t *testing.T) {
+	tests := []struct {
+		name              string
+		records           []EnrichedRecord
+		peakPrice         int
+		offPeakPrice      int
+		subscriptionPrice int
+	}{
+		{
+			name:              "peak journeys are charged the full price",
+			records:           retJourneys(2, 8, 10000),
+			peakPrice:         2 * 261,
+			subscriptionPrice: retDalVoordeelMonthlyPrice,
+		},
+		{
+			name:              "off-peak journeys get 34% discount",
+			records:           retJourneys(3, 10, 10000),
+			offPeakPrice:      3 * 172,
+			subscriptionPrice: retDalVoordeelMonthlyPrice,
+		},
+		{
+			name:              "peak and off-peak journeys",
+			records:           append(retJourneys(1, 8, 10000), retJourneys(2, 20, 0)...),
+			peakPrice:         261,
+			offPeakPrice:      2 * 65,
+			subscriptionPrice: retDalVoordeelMonthlyPrice,
+		},
+		{
+			name:    "journeys of other operators are skipped",
+			records: nsJourneys(2, 8),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			offPeakService := NewNSOffPeakService(noHolidaysRepository{}, missCache{}, discardErrorHandler{})
+			result := NewRETDalVoordeelCalculator(newTestRETFareService(), offPeakService).Calculate(test.records)
+
+			if result.PeakPrice.Value() != test.peakPrice {
+				t.Errorf("peak price = %d, want %d", result.PeakPrice.Value(), test.peakPrice)
+			}
+
+			if result.OffPeakPrice.Value() != test.offPeakPrice {
+				t.Errorf("off-peak price = %d, want %d", result.OffPeakPrice.Value(), test.offPeakPrice)
+			}
+
+			if result.SubscriptionPrice.Value() != test.subscriptionPrice {
+				t.Errorf("subscription price = %d, want %d", result.SubscriptionPrice.Value(), test.subscriptionPrice)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+// RET Maandabonnement for 2020. It gives unlimited travel with the RET.
+const retMaandMonthlyPrice = 9380
+
+// RETMaandCalculator calculates the price of RET journeys with the Maandabonnement subscription
+type RETMaandCalculator struct {
+	fareService DistanceFareService
+}
+
+// NewRETMaandCalculator creates a new instance of an RETMaandCalculator
+func NewRETMaandCalculator(fareService DistanceFareService) *RETMaandCalculator {
+	return &RETMaandCalculator{
+		fareService: fareService,
+	}
+}
+
+// RETMaandCalculatorResult represents the calculation result of RET journeys
+type RETMaandCalculatorResult struct {
+	JourneyCount       int
+	SubscriptionPrice  Money
+	SubscriptionMonths int
+}
+
+// TotalPrice returns the price of all the journeys including the subscription
+func (result RETMaandCalculatorResult) TotalPrice() Money {
+	return result.SubscriptionPrice
+}
+
+// Calculate calculates the total price
+func (calculator RETMaandCalculator) Calculate(records []EnrichedRecord) (result RETMaandCalculatorResult) {
+	for _, record := range records {
+		if calculator.fareService.IsJourney(record) {
+			result.JourneyCount++
+		}
+	}
+
+	result.SubscriptionMonths = calculator.fareService.SubscriptionMonths(records)
+	result.SubscriptionPrice = NewEUR(result.SubscriptionMonths * retMaandMonthlyPrice)
+
+	return result
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestRETMaandCalculatorCalculate(t *testing.T) {
+	records := append(retJourneys(2, 8, 10000), append(retJourneys(20, 20, 30000), nsJourneys(2, 8)...)...)
+	result := NewRETMaandCalculator(newTestRETFareService()).Calculate(records)
+
+	if result.JourneyCount != 22 {
+		t.Errorf("journey count = %d, want %d", result.JourneyCount, 22)
+	}
+
+	// the subscription price does not depend on the journeys
+	if result.TotalPrice().Value() != retMaandMonthlyPrice {
+		t.Errorf("total price = %d, want %d", result.TotalPrice().Value(), retMaandMonthlyPrice)
+	}
+}
@@ -0,0 +1,533 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/metrics"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/ratelimit"
+)
+
+// Collections which can be dropped with the db reset command
+var resettableCollections = []string{
+	collectionRawRecords,
+	collectionNSStations,
+	collectionNSPrices,
+	collectionNSEnrichedRecords,
+	collectionNationalHolidays,
+	collectionGTFSStops,
+	collectionReviewQueue,
+	collectionJobs,
+	collectionReferenceData,
+	collectionCalculations,
+	collectionImports,
+}
+
+// commands returns the subcommands of the CLI
+func commands() []cli.Command {
+	return []cli.Command{
+		{
+			Name:  "stations",
+			Usage: "manage the NS stations",
+			Subcommands: []cli.Command{
+				{
+					Name:   "sync",
+					Usage:  "store the stations from the NS API or from a GTFS feed",
+					Action: syncStations,
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "gtfs-file", Usage: "import the stations and stops from a GTFS zip file or a GTFS stops.txt file instead of the NS API", EnvVar: "GTFS_FILE"},
+					},
+				},
+			},
+		},
+		{
+			Name:  "holidays",
+			Usage: "manage the national holidays",
+			Subcommands: []cli.Command{
+				{
+					Name:   "sync",
+					Usage:  "store the national holidays from the calendarific API",
+					Action: syncHolidays,
+					Flags: []cli.Flag{
+						cli.IntSliceFlag{Name: "years", Usage: "years to sync, the default is the previous, the current and the next year", EnvVar: "HOLIDAY_YEARS"},
+					},
+				},
+			},
+		},
+		{
+			Name:  "prices",
+			Usage: "manage the NS prices",
+			Subcommands: []cli.Command{
+				{
+					Name:   "reload",
+					Usage:  "remove the cached NS prices so that the new fares are fetched when the imports are enriched again",
+					Action: reloadPrices,
+				},
+			},
+		},
+		{
+			Name:  "import",
+			Usage: "import the transactions of an ov-chipkaart",
+			Subcommands: []cli.Command{
+				{
+					Name:   "api",
+					Usage:  "fetch the transactions from the ov-chipkaart API",
+					Action: importFromAPI,
+					Flags: append(importFlags(),
+						cli.StringFlag{Name: "username", Usage: "username of the ov-chipkaart account", EnvVar: "OV_CHIPKAAT_USERNAME"},
+						cli.StringFlag{Name: "password", Usage: "password of the ov-chipkaart account", EnvVar: "OV_CHIPKAAT_PASSWORD"},
+					),
+				},
+				{
+					Name:   "csv",
+					Usage:  "read the transactions from a CSV file which was downloaded from ov-chipkaart.nl",
+					Action: importFromCSV,
+					Flags: append(importFlags(),
+						cli.StringFlag{Name: "file", Usage: "path of the CSV file"},
+					),
+				},
+			},
+		},
+		{
+			Name:   "enrich",
+			Usage:  "enrich the raw records of an import",
+			Action: enrichImport,
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "import", Usage: "ID of the import"},
+			},
+		},
+		{
+			Name:   "calculate",
+			Usage:  "calculate the price of every discount product for an import",
+			Action: calculateImport,
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "import", Usage: "ID of the import"},
+				cli.StringSliceFlag{Name: "product", Usage: "only show the results for these products. All products are always calculated and stored"},
+			},
+		},
+		{
+			Name:  "jobs",
+			Usage: "manage the background jobs",
+			Subcommands: []cli.Command{
+				{
+					Name:   "run",
+					Usage:  "run the pending jobs until the queue is empty",
+					Action: runPendingJobs,
+				},
+			},
+		},
+		{
+			Name:   "worker",
+			Usage:  "run the background jobs until the process is stopped",
+			Action: runWorker,
+			Flags: []cli.Flag{
+				cli.IntFlag{Name: "workers", Value: 1, Usage: "number of jobs which run at the same time", EnvVar: "JOB_WORKERS"},
+				cli.StringFlag{Name: "metrics-address", Usage: "address of the HTTP server for the Prometheus metrics e.g :9100, the metrics are not served when it is empty", EnvVar: "METRICS_ADDRESS"},
+			},
+		},
+		{
+			Name:  "db",
+			Usage: "manage the database",
+			Subcommands: []cli.Command{
+				{
+					Name:   "reset",
+					Usage:  "drop collections from the database. Valid collections are " + strings.Join(resettableCollections, ", "),
+					Action: resetCollections,
+					Flags: []cli.Flag{
+						cli.StringSliceFlag{Name: "collection", Usage: "collection to drop"},
+						cli.BoolFlag{Name: "force", Usage: "confirm that the data in the collections can be deleted"},
+					},
+				},
+			},
+		},
+		offlineCommand(),
+	}
+}
+
+// importFlags are the flags which are shared by the import commands
+func importFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{Name: "card", Usage: "number of the ov-chipkaart", EnvVar: "OV_CHIPKAAT_CARD_NUMBER"},
+		cli.StringFlag{Name: "start-date", Usage: "import the transactions from this date (YYYY-MM-DD)"},
+		cli.StringFlag{Name: "end-date", Usage: "import the transactions until this date (YYYY-MM-DD), the default is today"},
+		cli.StringFlag{Name: "user-id", Usage: "ID of the dashboard user who owns the import, imports without an owner are only visible to admins"},
+	}
+}
+
+func syncStations(c *cli.Context) error {
+	mongodb, err := connectMongoDB(c)
+	if err != nil {
+		return err
+	}
+
+	bsonService := NewBsonService()
+	stationsRepository := NewMongoNSStationsRepository(mongodb, collectionNSStations, bsonService)
+	referenceData := initializeReferenceDataService(mongodb)
+
+	if fileName := c.String("gtfs-file"); fileName != "" {
+		log.Printf("Importing GTFS feed")
+		result, err := NewGTFSImportService(NewMongoGTFSStopsRepository(mongodb, collectionGTFSStops, bsonService), stationsRepository).Import(fileName)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range []ReferenceDataName{referenceDataGTFSStops, referenceDataNSStations} {
+			if err = referenceData.Changed(name); err != nil {
+				return err
+			}
+		}
+
+		return printOutput(c, StationsSyncOutput{Source: "gtfs", StationsCount: result.NSStationsCount, StopsCount: result.StopsCount})
+	}
+
+	log.Printf("Fetching Stations")
+	stations, err := NewNSAPIClient(&http.Client{}, c.GlobalString(flagNSAPIKey)).GetAllStations()
+	if err != nil {
+		return err
+	}
+
+	err = stationsRepository.Store(stations)
+	if err != nil {
+		return err
+	}
+
+	err = referenceData.Changed(referenceDataNSStations)
+	if err != nil {
+		return err
+	}
+
+	return printOutput(c, StationsSyncOutput{Source: "ns", StationsCount: len(stations)})
+}
+
+func syncHolidays(c *cli.Context) error {
+	mongodb, err := connectMongoDB(c)
+	if err != nil {
+		return err
+	}
+
+	output, err := storeNationalHolidays(c, InitializeNationalHolidaysRepository(collectionNationalHolidays, mongodb))
+	if err != nil {
+		return err
+	}
+
+	err = initializeReferenceDataService(mongodb).Changed(referenceDataNationalHolidays)
+	if err != nil {
+		return err
+	}
+
+	return printOutput(c, output)
+}
+
+// storeNationalHolidays fetches the national holidays of the years in the --years flag from the calendarific API
+func storeNationalHolidays(c *cli.Context, holidaysRepository NationalHolidaysRepository) (output HolidaysSyncOutput, err error) {
+	years := c.IntSlice("years")
+	if len(years) == 0 {
+		currentYear := time.Now().Year()
+		years = []int{currentYear - 1, currentYear, currentYear + 1}
+	}
+
+	holidaysClient := NewCalendarificAPIClient(c.GlobalString(flagCalendarificAPIKey), &http.Client{})
+
+	rateLimiter := ratelimit.New(1)
+	for _, year := range years {
+		rateLimiter.Take()
+
+		holidays, err := holidaysClient.FetchNationalHolidays(time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC))
+		if err != nil {
+			return output, errors.Wrapf(err, "cannot fetch the holidays for %d", year)
+		}
+
+		err = holidaysRepository.Store(holidays)
+		if err != nil {
+			return output, errors.Wrapf(err, "cannot store the holidays for %d", year)
+		}
+
+		output.Years = append(output.Years, HolidaysSyncYear{Year: year, HolidaysCount: len(holidays)})
+	}
+
+	return output, nil
+}
+
+func reloadPrices(c *cli.Context) error {
+	mongodb, err := connectMongoDB(c)
+	if err != nil {
+		return err
+	}
+
+	err = mongodb.Collection(collectionNSPrices).Drop(context.Background())
+	if err != nil {
+		return errors.Wrapf(err, "cannot drop the '%s' collection", collectionNSPrices)
+	}
+
+	err = initializeReferenceDataService(mongodb).Changed(referenceDataNSPrices)
+	if err != nil {
+		return err
+	}
+
+	return printOutput(c, ResetOutput{Collections: []string{collectionNSPrices}})
+}
+
+func importFromAPI(c *cli.Context) error {
+	options, err := getTransactionFetchOptions(c)
+	if err != nil {
+		return err
+	}
+	options.Username = c.String("username")
+	options.Password = c.String("password")
+
+	if options.Username == "" || options.Password == "" {
+		return errors.New("the --username and --password flags are required")
+	}
+
+	mongodb, err := connectMongoDB(c)
+	if err != nil {
+		return err
+	}
+
+	log.Println("Fetching Transactions")
+	records, err := initializeTransactionFetcher(c).FetchTransactions(options)
+	if err != nil {
+		return err
+	}
+
+	return storeImport(c, mongodb, rawRecordSourceAPI, records)
+}
+
+func importFromCSV(c *cli.Context) error {
+	options, err := getTransactionFetchOptions(c)
+	if err != nil {
+		return err
+	}
+
+	if c.String("file") == "" {
+		return errors.New("the --file flag is required")
+	}
+
+	mongodb, err := connectMongoDB(c)
+	if err != nil {
+		return err
+	}
+
+	records, err := NewTransactionFetcherCSVService(NewFileSystemCSVReader("")).FetchTransactionRecords(CSVTransactionFetchOptions{
+		fileID:     c.String("file"),
+		cardNumber: options.CardNumber,
+		startDate:  options.StartDate,
+		endDate:    options.EndDate,
+	})
+	if err != nil {
+		return err
+	}
+
+	return storeImport(c, mongodb, rawRecordSourceCSV, records)
+}
+
+func getTransactionFetchOptions(c *cli.Context) (options TransactionFetchOptions, err error) {
+	options.CardNumber = c.String("card")
+	if options.CardNumber == "" {
+		return options, errors.New("the --card flag is required")
+	}
+
+	options.StartDate, err = parseDate(c.String("start-date"), time.Unix(0, 0))
+	if err != nil {
+		return options, err
+	}
+
+	options.EndDate, err = parseDate(c.String("end-date"), time.Now())
+	return options, err
+}
+
+// storeImport stores the records of a new import and the user who owns it
+func storeImport(c *cli.Context, mongodb *mongo.Database, source RawRecordSource, records []RawRecord) error {
+	importID := NewTransactionID()
+	if userID := c.String("user-id"); userID != "" {
+		ownerID, err := NewTransactionIDFromString(userID)
+		if err != nil {
+			return errors.Wrapf(err, "invalid --user-id '%s'", userID)
+		}
+
+		err = NewMongoImportOwnersRepository(mongodb, collectionImports, NewBsonService()).Store(ImportOwner{TransactionID: importID, UserID: ownerID})
+		if err != nil {
+			return err
+		}
+	}
+
+	repository := InitializeRawRecordsRepository(collectionRawRecords, mongodb)
+	for index := range records {
+		recordID := NewTransactionID()
+		records[index].ID = &recordID
+		records[index].TransactionID = &importID
+		records[index].Source = &source
+	}
+
+	if len(records) > 0 {
+		err := repository.Store(records)
+		if err != nil {
+			return err
+		}
+	}
+
+	return printOutput(c, ImportOutput{ImportID: importID.String(), Source: source.String(), RecordsCount: len(records)})
+}
+
+func enrichImport(c *cli.Context) error {
+	importID, err := getImportID(c)
+	if err != nil {
+		return err
+	}
+
+	pipeline, err := initializePipelineFromFlags(c)
+	if err != nil {
+		return err
+	}
+
+	results, err := pipeline.importEnrichment.EnrichImport(importID)
+	if err != nil {
+		return err
+	}
+
+	return printOutput(c, EnrichOutput{
+		ImportID:      importID.String(),
+		EnrichedCount: len(results.ValidRecords),
+		FailedCount:   len(results.Error.ErrorRecords),
+	})
+}
+
+func calculateImport(c *cli.Context) error {
+	importID, err := getImportID(c)
+	if err != nil {
+		return err
+	}
+
+	pipeline, err := initializePipelineFromFlags(c)
+	if err != nil {
+		return err
+	}
+
+	records, err := pipeline.enrichedRecordsRepository.FetchAllForTransactionID(importID)
+	if err != nil {
+		return errors.Wrapf(err, "cannot fetch enriched records for import '%s'", importID.String())
+	}
+
+	results, err := pipeline.calculationService.Calculate(importID, nil, records, nil)
+	if err != nil {
+		return err
+	}
+
+	return printOutput(c, NewCalculateOutput(importID, filterProducts(results, c.StringSlice("product"))))
+}
+
+// filterProducts returns the results for the products. All results are returned when no product is given.
+func filterProducts(results []StoredCalculationResult, products []string) (filtered []StoredCalculationResult) {
+	if len(products) == 0 {
+		return results
+	}
+
+	for _, result := range results {
+		for _, product := range products {
+			if strings.EqualFold(result.Product, product) {
+				filtered = append(filtered, result)
+			}
+		}
+	}
+
+	return filtered
+}
+
+func runPendingJobs(c *cli.Context) error {
+	pipeline, err := initializePipelineFromFlags(c)
+	if err != nil {
+		return err
+	}
+
+	return pipeline.jobRunner.RunPendingJobs(workerID(0))
+}
+
+func runWorker(c *cli.Context) error {
+	pipeline, err := initializePipelineFromFlags(c)
+	if err != nil {
+		return err
+	}
+
+	if c.String("metrics-address") != "" {
+		go serveMetrics(c.String("metrics-address"))
+	}
+
+	runJobWorkers(pipeline.jobRunner, c.Int("workers"))
+	return nil
+}
+
+// serveMetrics serves the Prometheus metrics of the worker on /metrics
+func serveMetrics(address string) {
+	err := metrics.ListenAndServe(address)
+	if err != nil {
+		log.Printf("cannot serve the metrics on %s: %s", address, err.Error())
+	}
+}
+
+func resetCollections(c *cli.Context) error {
+	collections := c.StringSlice("collection")
+	if len(collections) == 0 {
+		return errors.New("the --collection flag is required")
+	}
+
+	for _, collection := range collections {
+		if !isResettableCollection(collection) {
+			return errors.Errorf("'%s' is not a valid collection. Valid collections are %s", collection, strings.Join(resettableCollections, ", "))
+		}
+	}
+
+	if !c.Bool("force") {
+		return errors.Errorf("the data in %s will be deleted, use --force to confirm", strings.Join(collections, ", "))
+	}
+
+	mongodb, err := connectMongoDB(c)
+	if err != nil {
+		return err
+	}
+
+	for _, collection := range collections {
+		err = mongodb.Collection(collection).Drop(context.Background())
+		if err != nil {
+			return errors.Wrapf(err, "cannot drop the '%s' collection", collection)
+		}
+	}
+
+	return printOutput(c, ResetOutput{Collections: collections})
+}
+
+func isResettableCollection(name string) bool {
+	for _, collection := range resettableCollections {
+		if collection == name {
+			return true
+		}
+	}
+	return false
+}
+
+func getImportID(c *cli.Context) (TransactionID, error) {
+	if c.String("import") == "" {
+		return TransactionID{}, errors.New("the --import flag is required")
+	}
+
+	importID, err := NewTransactionIDFromString(c.String("import"))
+	if err != nil {
+		return importID, errors.Wrapf(err, "'%s' is not a valid import ID", c.String("import"))
+	}
+
+	return importID, nil
+}
+
+func initializePipelineFromFlags(c *cli.Context) (pipeline Pipeline, err error) {
+	mongodb, err := connectMongoDB(c)
+	if err != nil {
+		return pipeline, err
+	}
+
+	return initializePipeline(c, mongodb)
+}
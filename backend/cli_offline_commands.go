@@ -0,0 +1,282 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+
+	lfucache "github.com/NdoleStudio/lfu-cache"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+// offlineHTTPClient is used instead of the NS API when the offline calculator runs without an NS API key
+type offlineHTTPClient struct{}
+
+// Do fails every request because the data must come from the offline reference data
+func (client offlineHTTPClient) Do(request *http.Request) (*http.Response, error) {
+	return nil, errors.Errorf("cannot fetch '%s' offline, set --%s to fetch missing prices from the NS API", request.URL.Path, flagNSAPIKey)
+}
+
+// offlineCommand compares the discount products for a CSV file without a database. The reference data is exported from
+// the database with "offline export" or created without a database with "offline sync".
+func offlineCommand() cli.Command {
+	dirFlag := cli.StringFlag{Name: "dir", Value: "offline-data", Usage: "directory with the offline reference data", EnvVar: "OFFLINE_DATA_DIR"}
+
+	return cli.Command{
+		Name:  "offline",
+		Usage: "compare the discount products without an account or a database",
+		Subcommands: []cli.Command{
+			{
+				Name:   "export",
+				Usage:  "export the stations, prices, holidays and GTFS stops from the database for the offline calculator",
+				Action: exportOfflineData,
+				Flags:  []cli.Flag{dirFlag},
+			},
+			{
+				Name: "sync",
+				Usage: "store the stations and GTFS stops from a GTFS file or the NS API and the holidays from the calendarific " +
+					"API for the offline calculator without a database, the prices are fetched from the NS API when they are needed",
+				Action: syncOfflineData,
+				Flags: []cli.Flag{
+					dirFlag,
+					cli.StringFlag{Name: "gtfs-file", Usage: "import the stations and stops from a GTFS zip file or a GTFS stops.txt file instead of the NS API", EnvVar: "GTFS_FILE"},
+					cli.IntSliceFlag{Name: "years", Usage: "years of the holidays, the default is the previous, the current and the next year", EnvVar: "HOLIDAY_YEARS"},
+				},
+			},
+			{
+				Name:   "compare",
+				Usage:  "calculate the price of every discount product for the transactions in a CSV file from ov-chipkaart.nl",
+				Action: compareOffline,
+				Flags: append(importFlags(),
+					cli.StringFlag{Name: "file", Usage: "path of the CSV file"},
+					dirFlag,
+				),
+			},
+		},
+	}
+}
+
+func exportOfflineData(c *cli.Context) error {
+	mongodb, err := connectMongoDB(c)
+	if err != nil {
+		return err
+	}
+
+	bsonService := NewBsonService()
+	data := NewFileSystemReferenceData(c.String("dir"))
+
+	stations, err := NewMongoNSStationsRepository(mongodb, collectionNSStations, bsonService).FetchAll()
+	if err != nil {
+		return err
+	}
+
+	prices, err := NewMongoNSPricesRepository(mongodb, collectionNSPrices, bsonService).FetchAll()
+	if err != nil {
+		return err
+	}
+
+	holidays, err := NewMongoNationalHolidaysRepository(mongodb, collectionNationalHolidays, bsonService).FetchAll()
+	if err != nil {
+		return err
+	}
+
+	stops, err := NewMongoGTFSStopsRepository(mongodb, collectionGTFSStops, bsonService).FetchAll()
+	if err != nil {
+		return err
+	}
+
+	versions, err := NewMongoReferenceDataVersionsRepository(mongodb, collectionReferenceData, bsonService).FetchAll()
+	if err != nil {
+		return err
+	}
+
+	exports := []struct {
+		collection string
+		value      interface{}
+	}{
+		{collectionNSStations, stations},
+		{collectionNSPrices, prices},
+		{collectionNationalHolidays, holidays},
+		{collectionGTFSStops, stops},
+		{collectionReferenceData, versions},
+	}
+
+	output := ExportOutput{Directory: c.String("dir")}
+	for _, export := range exports {
+		err = data.write(export.collection, export.value)
+		if err != nil {
+			return err
+		}
+		output.Collections = append(output.Collections, export.collection)
+	}
+
+	return printOutput(c, output)
+}
+
+// syncOfflineData creates the offline reference data without a database. The holidays are skipped without a calendarific
+// API key so off-peak hours on national holidays are not detected.
+func syncOfflineData(c *cli.Context) error {
+	data := NewFileSystemReferenceData(c.String("dir"))
+	versionsRepository := NewFileSystemReferenceDataVersionsRepository(data)
+	output := ExportOutput{Directory: c.String("dir")}
+
+	stationsRepository, err := NewFileSystemNSStationsRepository(data)
+	if err != nil {
+		return err
+	}
+
+	changed := []ReferenceDataName{referenceDataNSStations}
+	if fileName := c.String("gtfs-file"); fileName != "" {
+		log.Printf("Importing GTFS feed")
+		_, err = NewGTFSImportService(NewFileSystemGTFSStopsRepository(data), stationsRepository).Import(fileName)
+		if err != nil {
+			return err
+		}
+
+		changed = append(changed, referenceDataGTFSStops)
+		output.Collections = append(output.Collections, collectionNSStations, collectionGTFSStops)
+	} else {
+		if c.GlobalString(flagNSAPIKey) == "" {
+			return errors.Errorf("the stations cannot be synced without the --gtfs-file flag or an NS API key in --%s", flagNSAPIKey)
+		}
+
+		log.Printf("Fetching Stations")
+		stations, err := NewNSAPIClient(&http.Client{}, c.GlobalString(flagNSAPIKey)).GetAllStations()
+		if err != nil {
+			return err
+		}
+
+		err = stationsRepository.Store(stations)
+		if err != nil {
+			return err
+		}
+
+		output.Collections = append(output.Collections, collectionNSStations)
+	}
+
+	if c.GlobalString(flagCalendarificAPIKey) == "" {
+		log.Printf("the holidays are not synced without a calendarific API key in --%s", flagCalendarificAPIKey)
+	} else {
+		holidaysRepository, err := NewFileSystemNationalHolidaysRepository(data)
+		if err != nil {
+			return err
+		}
+
+		_, err = storeNationalHolidays(c, holidaysRepository)
+		if err != nil {
+			return err
+		}
+
+		changed = append(changed, referenceDataNationalHolidays)
+		output.Collections = append(output.Collections, collectionNationalHolidays)
+	}
+
+	for _, name := range changed {
+		_, err = versionsRepository.Increment(name)
+		if err != nil {
+			return err
+		}
+	}
+	output.Collections = append(output.Collections, collectionReferenceData)
+
+	return printOutput(c, output)
+}
+
+func compareOffline(c *cli.Context) error {
+	options, err := getTransactionFetchOptions(c)
+	if err != nil {
+		return err
+	}
+
+	if c.String("file") == "" {
+		return errors.New("the --file flag is required")
+	}
+
+	records, err := NewTransactionFetcherCSVService(NewFileSystemCSVReader("")).FetchTransactionRecords(CSVTransactionFetchOptions{
+		fileID:     c.String("file"),
+		cardNumber: options.CardNumber,
+		startDate:  options.StartDate,
+		endDate:    options.EndDate,
+	})
+	if err != nil {
+		return err
+	}
+
+	importID := NewTransactionID()
+	source := rawRecordSourceCSV
+	for index := range records {
+		records[index].TransactionID = &importID
+		records[index].Source = &source
+	}
+
+	enrichmentService, calculationService, err := initializeOfflineServices(c, NewFileSystemReferenceData(c.String("dir")))
+	if err != nil {
+		return err
+	}
+
+	enrichment := enrichmentService.Enrich(records)
+	for _, errorRecord := range enrichment.Error.ErrorRecords {
+		log.Printf("cannot enrich the transaction at %s: %s", errorRecord.Record.TransactionDateTime.ToTime().Format(dateFormat), errorRecord.Error.Error())
+	}
+
+	results, err := calculationService.Compare(importID, nil, enrichment.ValidRecords, nil)
+	if err != nil {
+		return err
+	}
+
+	return printOutput(c, CompareOutput{
+		CalculateOutput: NewCalculateOutput(importID, results),
+		EnrichedCount:   len(enrichment.ValidRecords),
+		FailedCount:     len(enrichment.Error.ErrorRecords),
+	})
+}
+
+// initializeOfflineServices creates the enrichment and the calculation services using the reference data in a directory
+func initializeOfflineServices(c *cli.Context, data FileSystemReferenceData) (enrichmentService NSRawRecordsEnrichmentService, calculationService CalculationService, err error) {
+	lfuCache, err := lfucache.New(100)
+	if err != nil {
+		return enrichmentService, calculationService, errors.Wrap(err, "cannot create cache")
+	}
+	cache := NewMetricsLFUCache("stations_and_prices", lfuCache)
+
+	stationsRepository, err := NewFileSystemNSStationsRepository(data)
+	if err != nil {
+		return enrichmentService, calculationService, err
+	}
+
+	pricesRepository, err := NewFileSystemNSPricesRepository(data)
+	if err != nil {
+		return enrichmentService, calculationService, err
+	}
+
+	holidaysRepository, err := NewFileSystemNationalHolidaysRepository(data)
+	if err != nil {
+		return enrichmentService, calculationService, err
+	}
+
+	stops, err := loadGTFSStops(NewFileSystemGTFSStopsRepository(data))
+	if err != nil {
+		return enrichmentService, calculationService, err
+	}
+
+	var httpClient HTTPClient = offlineHTTPClient{}
+	if c.GlobalString(flagNSAPIKey) != "" {
+		httpClient = &http.Client{}
+	}
+
+	errorHandler := NewSentryErrorHandler()
+	reviewQueue := NewLogReviewQueueRepository(log.New(os.Stderr, "", log.LstdFlags).Printf)
+	priceFetcher := NewNSPriceFetcher(NewNSAPIClient(httpClient, c.GlobalString(flagNSAPIKey)), pricesRepository, errorHandler, cache)
+	stationCodeService := NewNSStationsCodeService(stationsRepository, NewNSStationNameResolver(stationsRepository, NewFileSystemReferenceDataVersionsRepository(data)), reviewQueue, errorHandler, cache)
+	offPeakService := NewNSOffPeakService(holidaysRepository, NewMetricsLFUCache("national_holidays", InitializeCache(100)), errorHandler)
+	operators := registerOperators(stops, stationCodeService, priceFetcher, offPeakService)
+	missingCheckOutService := NewMissingCheckOutService(stationCodeService, priceFetcher, errorHandler)
+	enrichmentService = NewNSRawRecordsEnrichmentService(NewOVJourneyReconstructionService(transferTimeWindow), missingCheckOutService, operators)
+
+	// The offline reference data never changes and the results are not stored so there are no jobs to queue
+	referenceData := NewReferenceDataService(NewFileSystemReferenceDataVersionsRepository(data), nil, nil, nil)
+	calculationService = NewCalculationService(operators, nil, referenceData)
+
+	return enrichmentService, calculationService, nil
+}
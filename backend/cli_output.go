@@ -0,0 +1,241 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+// Output formats of the commands
+const (
+	outputFormatTable = "table"
+	outputFormatJSON  = "json"
+)
+
+// CommandOutput is the result of a command which can be printed as a table or as JSON
+type CommandOutput interface {
+	TableHeader() []string
+	TableRows() [][]string
+}
+
+// printOutput prints the result of a command in the format which was selected with the --output flag
+func printOutput(c *cli.Context, output CommandOutput) error {
+	return writeOutput(os.Stdout, c.GlobalString(flagOutput), output)
+}
+
+func writeOutput(writer io.Writer, format string, output CommandOutput) error {
+	switch format {
+	case outputFormatJSON:
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		return errors.Wrap(encoder.Encode(output), "cannot encode the output as JSON")
+	case outputFormatTable, "":
+		table := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(table, strings.Join(output.TableHeader(), "\t"))
+		for _, row := range output.TableRows() {
+			_, _ = fmt.Fprintln(table, strings.Join(row, "\t"))
+		}
+		return errors.Wrap(table.Flush(), "cannot write the output as a table")
+	default:
+		return errors.Errorf("unknown output format '%s', use '%s' or '%s'", format, outputFormatTable, outputFormatJSON)
+	}
+}
+
+// formatCents formats an amount in cents as euros
+func formatCents(cents int) string {
+	return fmt.Sprintf("%.2f", float64(cents)/100)
+}
+
+// StationsSyncOutput is the result of the stations sync command
+type StationsSyncOutput struct {
+	Source        string `json:"source"`
+	StationsCount int    `json:"stations_count"`
+	StopsCount    int    `json:"stops_count"`
+}
+
+// TableHeader returns the column names of the table
+func (output StationsSyncOutput) TableHeader() []string {
+	return []string{"SOURCE", "STATIONS", "STOPS"}
+}
+
+// TableRows returns the rows of the table
+func (output StationsSyncOutput) TableRows() [][]string {
+	return [][]string{{output.Source, fmt.Sprint(output.StationsCount), fmt.Sprint(output.StopsCount)}}
+}
+
+// HolidaysSyncOutput is the result of the holidays sync command
+type HolidaysSyncOutput struct {
+	Years []HolidaysSyncYear `json:"years"`
+}
+
+// HolidaysSyncYear is the number of holidays which were stored for a year
+type HolidaysSyncYear struct {
+	Year          int `json:"year"`
+	HolidaysCount int `json:"holidays_count"`
+}
+
+// TableHeader returns the column names of the table
+func (output HolidaysSyncOutput) TableHeader() []string {
+	return []string{"YEAR", "HOLIDAYS"}
+}
+
+// TableRows returns the rows of the table
+func (output HolidaysSyncOutput) TableRows() (rows [][]string) {
+	for _, year := range output.Years {
+		rows = append(rows, []string{fmt.Sprint(year.Year), fmt.Sprint(year.HolidaysCount)})
+	}
+	return rows
+}
+
+// ImportOutput is the result of the import commands
+type ImportOutput struct {
+	ImportID     string `json:"import_id"`
+	Source       string `json:"source"`
+	RecordsCount int    `json:"records_count"`
+}
+
+// TableHeader returns the column names of the table
+func (output ImportOutput) TableHeader() []string {
+	return []string{"IMPORT ID", "SOURCE", "RECORDS"}
+}
+
+// TableRows returns the rows of the table
+func (output ImportOutput) TableRows() [][]string {
+	return [][]string{{output.ImportID, output.Source, fmt.Sprint(output.RecordsCount)}}
+}
+
+// EnrichOutput is the result of the enrich command
+type EnrichOutput struct {
+	ImportID      string `json:"import_id"`
+	EnrichedCount int    `json:"enriched_count"`
+	FailedCount   int    `json:"failed_count"`
+}
+
+// TableHeader returns the column names of the table
+func (output EnrichOutput) TableHeader() []string {
+	return []string{"IMPORT ID", "ENRICHED", "FAILED"}
+}
+
+// TableRows returns the rows of the table
+func (output EnrichOutput) TableRows() [][]string {
+	return [][]string{{output.ImportID, fmt.Sprint(output.EnrichedCount), fmt.Sprint(output.FailedCount)}}
+}
+
+// CalculateOutput is the result of the calculate command
+type CalculateOutput struct {
+	ImportID string                  `json:"import_id"`
+	RunID    string                  `json:"run_id"`
+	Results  []CalculateOutputResult `json:"results"`
+}
+
+// CalculateOutputResult is the price of the journeys with a discount product. Prices are in cents.
+type CalculateOutputResult struct {
+	CompanyName       string `json:"company_name"`
+	Product           string `json:"product"`
+	RuleSetVersion    string `json:"rule_set_version"`
+	FareTableVersion  string `json:"fare_table_version"`
+	Currency          string `json:"currency"`
+	JourneyCount      int    `json:"journey_count"`
+	JourneysPrice     int    `json:"journeys_price"`
+	SubscriptionPrice int    `json:"subscription_price"`
+	TotalPrice        int    `json:"total_price"`
+	IsCheapest        bool   `json:"is_cheapest"`
+}
+
+// NewCalculateOutput creates the output of the calculate command from the stored calculation results
+func NewCalculateOutput(importID TransactionID, results []StoredCalculationResult) (output CalculateOutput) {
+	output.ImportID = importID.String()
+	output.Results = []CalculateOutputResult{}
+	for _, result := range results {
+		output.RunID = result.RunID.String()
+		output.Results = append(output.Results, CalculateOutputResult{
+			CompanyName:       result.CompanyName.String(),
+			Product:           result.Product,
+			RuleSetVersion:    result.RuleSetVersion,
+			FareTableVersion:  result.FareTableVersion,
+			Currency:          result.Currency,
+			JourneyCount:      result.JourneyCount,
+			JourneysPrice:     result.JourneysPrice,
+			SubscriptionPrice: result.SubscriptionPrice,
+			TotalPrice:        result.TotalPrice,
+			IsCheapest:        result.IsCheapest,
+		})
+	}
+	return output
+}
+
+// TableHeader returns the column names of the table
+func (output CalculateOutput) TableHeader() []string {
+	return []string{"COMPANY", "PRODUCT", "JOURNEYS", "JOURNEYS PRICE", "SUBSCRIPTION", "TOTAL", "CHEAPEST"}
+}
+
+// TableRows returns the rows of the table
+func (output CalculateOutput) TableRows() (rows [][]string) {
+	for _, result := range output.Results {
+		cheapest := ""
+		if result.IsCheapest {
+			cheapest = "*"
+		}
+
+		rows = append(rows, []string{
+			result.CompanyName,
+			result.Product,
+			fmt.Sprint(result.JourneyCount),
+			formatCents(result.JourneysPrice),
+			formatCents(result.SubscriptionPrice),
+			result.Currency + " " + formatCents(result.TotalPrice),
+			cheapest,
+		})
+	}
+	return rows
+}
+
+// ResetOutput is the result of the db reset command
+type ResetOutput struct {
+	Collections []string `json:"collections"`
+}
+
+// TableHeader returns the column names of the table
+func (output ResetOutput) TableHeader() []string {
+	return []string{"DROPPED COLLECTION"}
+}
+
+// TableRows returns the rows of the table
+func (output ResetOutput) TableRows() (rows [][]string) {
+	for _, collection := range output.Collections {
+		rows = append(rows, []string{collection})
+	}
+	return rows
+}
+
+// ExportOutput is the result of the offline export command
+type ExportOutput struct {
+	Directory   string   `json:"directory"`
+	Collections []string `json:"collections"`
+}
+
+// TableHeader returns the column names of the table
+func (output ExportOutput) TableHeader() []string {
+	return []string{"EXPORTED COLLECTION", "DIRECTORY"}
+}
+
+// TableRows returns the rows of the table
+func (output ExportOutput) TableRows() (rows [][]string) {
+	for _, collection := range output.Collections {
+		rows = append(rows, []string{collection, output.Directory})
+	}
+	return rows
+}
+
+// CompareOutput is the result of the offline compare command
+type CompareOutput struct {
+	CalculateOutput
+	EnrichedCount int `json:"enriched_count"`
+	FailedCount   int `json:"failed_count"`
+}
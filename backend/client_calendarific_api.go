@@ -81,7 +81,11 @@ func (apiClient CalendarificAPIClient) FetchNationalHolidays(timestamp time.Time
 	}
 
 	if apiResponse.Meta.ErrorType != nil {
-		return holidays, errors.Wrapf(err, "%s: %s", apiResponse.Meta.ErrorType, apiResponse.Meta.ErrorDetail)
+		errorDetail := ""
+		if apiResponse.Meta.ErrorDetail != nil {
+			errorDetail = *apiResponse.Meta.ErrorDetail
+		}
+		return holidays, errors.Errorf("%s: %s", *apiResponse.Meta.ErrorType, errorDetail)
 	}
 
 	for _, holiday := range apiResponse.Response.Holidays {
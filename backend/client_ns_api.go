@@ -2,9 +2,13 @@ package main
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/AchoArnold/homework/services/json"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/metrics"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/tracing"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/api/kv"
 )
 
 // Api Endpoints
@@ -224,6 +228,29 @@ func (service NSAPIClient) GetAllStations() (stations []NSStation, error error)
 			IsDepreciated: false,
 		})
 
+		// The short and medium names are used in the ov-chipkaart transactions e.g "Den Haag HS" and "Amsterdam C."
+		names := map[string]bool{strings.ToLower(station.Namen.Lang): true}
+		for _, name := range []string{station.Namen.Middel, station.Namen.Kort} {
+			if name == "" || names[strings.ToLower(name)] {
+				continue
+			}
+			names[strings.ToLower(name)] = true
+
+			stations = append(stations, NSStation{
+				ID:            NewTransactionID(),
+				Name:          name,
+				CurrentName:   station.Namen.Lang,
+				Code:          station.Code,
+				Country:       station.Land,
+				EVACode:       station.EVACode,
+				Latitude:      station.Lat,
+				Longitude:     station.Lng,
+				StartIngDate:  station.IngangsDatum,
+				UICCode:       station.UICCode,
+				IsDepreciated: false,
+			})
+		}
+
 		for _, name := range station.Synoniemen {
 			stations = append(stations, NSStation{
 				Name:          name,
@@ -243,12 +270,23 @@ func (service NSAPIClient) GetAllStations() (stations []NSStation, error error)
 	return stations, nil
 }
 
-func (service NSAPIClient) doHTTPRequest(request *http.Request) (*http.Response, error) {
-	apiResponse, err := service.httpClient.Do(request)
+func (service NSAPIClient) doHTTPRequest(request *http.Request) (apiResponse *http.Response, err error) {
+	ctx, span := tracing.StartSpan(
+		request.Context(),
+		"ns-api "+request.Method+" "+request.URL.Path,
+		kv.String("http.method", request.Method),
+		kv.String("http.url", request.URL.String()),
+	)
+	defer func() { tracing.EndSpan(ctx, span, err) }()
+
+	apiResponse, err = service.httpClient.Do(request.WithContext(ctx))
 	if err != nil {
+		metrics.ObserveNSAPIRequest(request.URL.Path, 0, err)
 		return nil, errors.Wrapf(err, "cannot execute %s request for %s: ", request.Method, request.URL.String())
 	}
 
+	metrics.ObserveNSAPIRequest(request.URL.Path, apiResponse.StatusCode, nil)
+	span.SetAttributes(kv.Int("http.status_code", apiResponse.StatusCode))
 	return apiResponse, nil
 }
 
@@ -14,8 +14,14 @@ func (companyName CompanyName) String() string {
 }
 
 const (
-	companyNameNS  = CompanyName("NS")
-	companyNameRET = CompanyName("RET")
+	companyNameNS         = CompanyName("NS")
+	companyNameRET        = CompanyName("RET")
+	companyNameGVB        = CompanyName("GVB")
+	companyNameHTM        = CompanyName("HTM")
+	companyNameArriva     = CompanyName("Arriva")
+	companyNameConnexxion = CompanyName("Connexxion")
+	companyNameKeolis     = CompanyName("Keolis")
+	companyNameQbuzz      = CompanyName("Qbuzz")
 )
 
 // TransactionType represents the type of transaction
@@ -29,6 +35,8 @@ func (transactionType TransactionType) String() string {
 const (
 	transactionTypeTravel     = TransactionType("Travel")
 	transactionTypeSupplement = TransactionType("Supplement")
+	// A check-in without a check-out for which the maximum fare was deducted
+	transactionTypeMissingCheckOut = TransactionType("MissingCheckOut")
 )
 
 // TimeInMilliSeconds represents time in milliseconds
@@ -60,6 +68,8 @@ func (name TransactionName) IsTheSameAs(comp TransactionName) bool {
 const (
 	transactionNameCheckIn                  = TransactionName("Check-in")
 	transactionNameCheckOut                 = TransactionName("Check-uit")
+	transactionNameTransfer                 = TransactionName("Overstap")
+	transactionNameAborted                  = TransactionName("Afgebroken")
 	transactionNameIntercityDirectSurcharge = TransactionName("Toeslag Intercity Direct")
 )
 
@@ -76,6 +86,12 @@ const (
 	rawRecordSourceCSV = RawRecordSource("CSV")
 )
 
+// Maximum time between a check-out and the next check-in for both to belong to the same journey.
+const transferTimeWindow = 35 * time.Minute
+
+// The zone id of train stations in GTFS feeds from the Netherlands is the IFF station code with this prefix
+const gtfsZonePrefixIFF = "IFF:"
+
 const timeoutAPIRequest = 100 * time.Millisecond
 
 const dateFormat = "2006-01-02"
@@ -84,6 +100,28 @@ const yearFormat = "2006"
 
 const hashSeparator = "-"
 
+// The number of times a job is tried before it fails
+const jobMaxAttempts = 5
+
+// The delay before the first retry of a job. The delay doubles after every failed attempt.
+const jobRetryDelay = 30 * time.Second
+
+// How long a worker can run a job before another worker can claim it
+const jobLockDuration = 30 * time.Minute
+
+// How often a worker extends the lock of the job it is running
+const jobLockRenewInterval = jobLockDuration / 3
+
+// How long a worker waits before checking for new jobs when the queue is empty
+const jobPollInterval = 5 * time.Second
+
+// Version of the rules which are used by the calculators. Change it when a calculator is changed so that the stored
+// calculation results show which rules were used.
+const calculationRuleSetVersion = "2020.1"
+
+// Version of the distance based tariffs of the regional operators
+const distanceFareTariffsVersion = "2020"
+
 // basic fare for all transport using NS. This is the 2020 fare
 const basicFare = 98
 
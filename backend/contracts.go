@@ -3,6 +3,7 @@ package main
 import (
 	"crypto/md5"
 	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
@@ -24,6 +25,11 @@ type TransactionFetchOptions struct {
 	EndDate    time.Time
 }
 
+// TransactionFetcher fetches the transactions of an ov-chipkaart
+type TransactionFetcher interface {
+	FetchTransactions(options TransactionFetchOptions) (records []RawRecord, err error)
+}
+
 // RawRecord represents a transaction record
 type RawRecord struct {
 	DBTimestamp
@@ -46,6 +52,8 @@ type RawRecord struct {
 	TransactionExplanation string             `json:"transactionExplanation" bson:"transaction_explanation"`
 	TransactionPriority    string             `json:"transactionPriority" bson:"transaction_priority"`
 	Source                 *RawRecordSource   `bson:"source"`
+	// IsIgnored is set by an admin when the record cannot be enriched and should not be part of the journeys
+	IsIgnored bool `bson:"is_ignored"`
 }
 
 // IsCheckIn determines if a record is a check in record
@@ -58,6 +66,29 @@ func (record RawRecord) IsNSSupplement() bool {
 	return record.TransactionName.IsTheSameAs(transactionNameIntercityDirectSurcharge)
 }
 
+// IsTransfer determines if a record is a transfer ("overstap") check in record
+func (record RawRecord) IsTransfer() bool {
+	return record.TransactionName.IsTheSameAs(transactionNameTransfer)
+}
+
+// IsAborted determines if a record is a correction ("afgebroken") transaction for a journey which was not completed
+func (record RawRecord) IsAborted() bool {
+	return strings.Contains(strings.ToLower(record.TransactionName.String()), strings.ToLower(transactionNameAborted.String()))
+}
+
+// DeductedAmount returns the amount in cents which was deducted from the ov-chipkaart for this record
+func (record RawRecord) DeductedAmount() int {
+	if record.EPurseMut != nil && *record.EPurseMut < 0 {
+		return NewEURFromFloat(-*record.EPurseMut).Value()
+	}
+
+	if record.Fare != nil {
+		return NewEURFromFloat(*record.Fare).Value()
+	}
+
+	return 0
+}
+
 // IsCheckOut determines if a record is checkout transaction.
 func (record RawRecord) IsCheckOut() bool {
 	return record.TransactionName.IsTheSameAs(transactionNameCheckOut)
@@ -83,6 +114,7 @@ type RawRecordsRepository interface {
 	Store(records []RawRecord) (err error)
 	First() (rawRecord RawRecord, err error)
 	GetByTransactionID(getOptions GetRawRecordsOptions) (rawRecords []RawRecord, err error)
+	DeleteAllForTransactionID(transactionID TransactionID) (err error)
 }
 
 // NSJourneyPrice represents the price for an NS journey
@@ -109,13 +141,224 @@ func (price NSJourneyPrice) EstimatedDurationInMilliSeconds() int {
 type NSPricesRepository interface {
 	Store(price NSJourneyPrice) (err error)
 	GetByHash(hash string) (price NSJourneyPrice, err error)
+	FetchAll() (prices []NSJourneyPrice, err error)
 }
 
 // NSStationsRepository is responsible for saving and loading NSStation struct
 type NSStationsRepository interface {
+	// Store inserts the stations or updates the stations with the same code and name
 	Store(stations []NSStation) (err error)
 	GetByName(name string) (station NSStation, err error)
 	GetByCode(code string) (station NSStation, err error)
+	FetchAll() (stations []NSStation, err error)
+}
+
+// NSStationMatch is a station which matches a name in a transaction together with how confident we are about the match
+type NSStationMatch struct {
+	Station    NSStation
+	Confidence float64
+}
+
+// ReviewQueueItemType is the reason why an item needs to be reviewed by an admin
+type ReviewQueueItemType string
+
+// String returns the review queue item type as a string
+func (itemType ReviewQueueItemType) String() string {
+	return string(itemType)
+}
+
+const (
+	reviewQueueItemTypeUnresolvedStationName = ReviewQueueItemType("UnresolvedStationName")
+	reviewQueueItemTypeFailedEnrichment      = ReviewQueueItemType("FailedEnrichment")
+)
+
+// ReviewQueueItemStatus is the state of an item in the review queue
+type ReviewQueueItemStatus string
+
+const (
+	reviewQueueItemStatusOpen = ReviewQueueItemStatus("open")
+)
+
+// ReviewQueueSuggestion is a possible resolution for a review queue item
+type ReviewQueueSuggestion struct {
+	Code       string  `bson:"code"`
+	Name       string  `bson:"name"`
+	Confidence float64 `bson:"confidence"`
+}
+
+// ReviewQueueItem is something the system could not resolve on its own e.g a station name in a transaction
+type ReviewQueueItem struct {
+	DBTimestamp `bson:",inline"`
+	ID          TransactionID           `bson:"id"`
+	Type        ReviewQueueItemType     `bson:"type"`
+	Text        string                  `bson:"text"`
+	Status      ReviewQueueItemStatus   `bson:"status"`
+	Occurrences int                     `bson:"occurrences"`
+	Suggestions []ReviewQueueSuggestion `bson:"suggestions"`
+	// The fields below are only set for failed enrichments
+	Reason        string         `bson:"reason,omitempty"`
+	StationName   string         `bson:"station_name,omitempty"`
+	RawRecordID   *TransactionID `bson:"raw_record_id,omitempty"`
+	TransactionID *TransactionID `bson:"transaction_id,omitempty"`
+}
+
+// ReviewQueueRepository is responsible for saving and loading items which need to be reviewed by an admin
+type ReviewQueueRepository interface {
+	// Add stores an item or increases the occurrences if an item with the same type and text already exists
+	Add(item ReviewQueueItem) (err error)
+	// ReplaceFailedEnrichments replaces the open failed enrichments of an import with new items
+	ReplaceFailedEnrichments(transactionID TransactionID, items []ReviewQueueItem) (err error)
+}
+
+//////////
+// Jobs //
+//////////
+
+// JobType is the kind of work a job does
+type JobType string
+
+const (
+	// Fetch the transactions of an ov-chipkaart from the ov-chipkaart API
+	jobTypeImport = JobType("Import")
+	// Enrich the raw records of an import again
+	jobTypeReEnrichment = JobType("ReEnrichment")
+	// Calculate the prices of the discount products for an import again
+	jobTypeRecalculation = JobType("Recalculation")
+)
+
+// JobStatus is the state of a job
+type JobStatus string
+
+const (
+	jobStatusPending = JobStatus("pending")
+	jobStatusRunning = JobStatus("running")
+	jobStatusDone    = JobStatus("done")
+	jobStatusFailed  = JobStatus("failed")
+)
+
+// Job is work which is done in the background for an import e.g when the reference data changes
+type Job struct {
+	DBTimestamp           `bson:",inline"`
+	ID                    TransactionID         `bson:"id"`
+	Type                  JobType               `bson:"type"`
+	Status                JobStatus             `bson:"status"`
+	TransactionID         TransactionID         `bson:"transaction_id"`
+	UserID                *TransactionID        `bson:"user_id,omitempty"`
+	IdempotencyKey        string                `bson:"idempotency_key,omitempty"`
+	Reason                string                `bson:"reason"`
+	Import                *ImportJobOptions     `bson:"import,omitempty"`
+	ProcessedItems        int                   `bson:"processed_items"`
+	TotalItems            int                   `bson:"total_items"`
+	Attempts              int                   `bson:"attempts"`
+	MaxAttempts           int                   `bson:"max_attempts"`
+	RunAfter              time.Time             `bson:"run_after"`
+	LockedBy              string                `bson:"locked_by,omitempty"`
+	LockedUntil           time.Time             `bson:"locked_until"`
+	Error                 string                `bson:"error,omitempty"`
+	ReferenceDataVersions ReferenceDataVersions `bson:"reference_data_versions"`
+}
+
+// ImportJobOptions are the transactions which are fetched by an import job.
+// The credentials of the ov-chipkaart account are not stored with the job, see ImportCredentialsStore.
+type ImportJobOptions struct {
+	CardNumber string    `bson:"card_number"`
+	StartDate  time.Time `bson:"start_date"`
+	EndDate    time.Time `bson:"end_date"`
+}
+
+// NewJob creates a pending job for an import
+func NewJob(jobType JobType, transactionID TransactionID, reason string) Job {
+	return Job{
+		ID:            NewTransactionID(),
+		Type:          jobType,
+		Status:        jobStatusPending,
+		TransactionID: transactionID,
+		Reason:        reason,
+		MaxAttempts:   jobMaxAttempts,
+		RunAfter:      time.Now().UTC(),
+	}
+}
+
+// CanRetry returns true when a failed attempt of the job can be tried again
+func (job Job) CanRetry() bool {
+	return job.Attempts < job.MaxAttempts
+}
+
+// JobsRepository is responsible for saving and loading jobs
+type JobsRepository interface {
+	// Store saves a job. A job with an idempotency key is stored once per user and a job without a key is not stored
+	// when a pending job of the same type already exists for the import.
+	Store(job Job) (err error)
+	// Claim locks the oldest job which is ready to run so that no other worker runs it. ErrNotFound is returned when
+	// there is no job to run. Jobs whose lock expired because a worker crashed are claimed again.
+	Claim(workerID string, lockDuration time.Duration) (job Job, err error)
+	// ExtendLock keeps a running job locked for lockDuration from now. ErrNotFound is returned when the job is no
+	// longer locked by the worker.
+	ExtendLock(jobID TransactionID, workerID string, lockDuration time.Duration) (err error)
+	// Update saves the status and the progress of a job which is locked by job.LockedBy. The lock of a running job is
+	// only changed by Claim and ExtendLock and it is released when the job is no longer running. ErrNotFound is
+	// returned when the job is no longer locked by the worker.
+	Update(job Job) (err error)
+}
+
+// ImportOwner is the user who owns an import. Imports from the CLI without a --user-id flag don't have an owner.
+type ImportOwner struct {
+	TransactionID TransactionID `bson:"transaction_id"`
+	UserID        TransactionID `bson:"user_id"`
+}
+
+// ImportOwnersRepository keeps track of the user who owns each import so that the jobs of an import can be shown to
+// its owner and the data can be found when the owner is deleted.
+type ImportOwnersRepository interface {
+	Store(owner ImportOwner) (err error)
+	// FetchUserID returns the ID of the user who owns the import. ErrNotFound is returned when the import has no owner.
+	FetchUserID(transactionID TransactionID) (userID TransactionID, err error)
+}
+
+// ImportCredentials are the credentials of an ov-chipkaart account
+type ImportCredentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// ImportCredentialsStore holds the credentials of an import job until the job is done.
+// The credentials expire so they are never kept for longer than needed.
+type ImportCredentialsStore interface {
+	Get(jobID TransactionID) (credentials ImportCredentials, err error)
+	Delete(jobID TransactionID) (err error)
+}
+
+////////////////////
+// Reference Data //
+////////////////////
+
+// ReferenceDataName is the name of data which is used to enrich records or to calculate prices
+type ReferenceDataName string
+
+// String returns the reference data name as a string
+func (name ReferenceDataName) String() string {
+	return string(name)
+}
+
+const (
+	referenceDataNSStations       = ReferenceDataName("ns_stations")
+	referenceDataNSPrices         = ReferenceDataName("ns_prices")
+	referenceDataGTFSStops        = ReferenceDataName("gtfs_stops")
+	referenceDataNationalHolidays = ReferenceDataName("national_holidays")
+)
+
+// ReferenceDataVersions is the version of each reference data set which was used to produce a result
+type ReferenceDataVersions map[string]int
+
+// Get returns the version of a reference data set
+func (versions ReferenceDataVersions) Get(name ReferenceDataName) int {
+	return versions[name.String()]
+}
+
+// ReferenceDataVersionsRepository is responsible for keeping track of the reference data versions
+type ReferenceDataVersionsRepository interface {
+	Increment(name ReferenceDataName) (version int, err error)
+	FetchAll() (versions ReferenceDataVersions, err error)
 }
 
 // ErrorHandler is responsible for handling application errors
@@ -146,6 +389,16 @@ type NSStation struct {
 	CurrentName   string        `bson:"current_name"`
 }
 
+// ToStop converts an NS station into a Stop
+func (station NSStation) ToStop() Stop {
+	return Stop{
+		Code:      station.Code,
+		Name:      station.Name,
+		Latitude:  station.Latitude,
+		Longitude: station.Longitude,
+	}
+}
+
 // ToLower converts the NSStation struct values to lowercase
 func (station NSStation) ToLower() NSStation {
 	return NSStation{
@@ -221,6 +474,13 @@ type EnrichedRecord struct {
 	CompanyName      CompanyName        `bson:"company_name"`
 	TransactionType  TransactionType    `bson:"transaction_type"`
 	Duration         time.Duration      `bson:"duration"`
+	JourneyID        *TransactionID     `bson:"journey_id"`
+	RawRecordIDs     []TransactionID    `bson:"raw_record_ids"`
+	DeductedAmount   int                `bson:"deducted_amount"`
+	RefundAmount     int                `bson:"refund_amount"`
+	Distance         int                `bson:"distance"`
+	// The versions of the stations, stops and prices which were used to enrich the record
+	ReferenceDataVersions ReferenceDataVersions `bson:"reference_data_versions"`
 }
 
 // NSJourney returns the NSJourney for a given enriched record
@@ -233,9 +493,14 @@ func (record EnrichedRecord) IsSupplement() bool {
 	return record.TransactionType == transactionTypeSupplement
 }
 
+// IsMissingCheckOut determines if the enriched record is a journey where the traveller did not check out
+func (record EnrichedRecord) IsMissingCheckOut() bool {
+	return record.TransactionType == transactionTypeMissingCheckOut
+}
+
 // IsNSJourney determines if the enriched record is an NSJourney
 func (record EnrichedRecord) IsNSJourney() bool {
-	return record.TransactionType == transactionTypeTravel
+	return record.TransactionType == transactionTypeTravel && record.CompanyName == companyNameNS
 }
 
 // RawRecordsEnrichmentService is the interface for filtering raw records
@@ -254,6 +519,190 @@ func (results RawRecordsEnrichmentResults) HasError() bool {
 	return len(results.Error.ErrorRecords) > 0
 }
 
+//////////////////////////////////
+// Journey Reconstruction Service
+//////////////////////////////////
+
+// JourneyLeg is a single ride within a journey. It starts with a check-in and ends with a check-out.
+type JourneyLeg struct {
+	CheckIn     *RawRecord
+	CheckOut    *RawRecord
+	Correction  *RawRecord
+	Supplements []RawRecord
+	IsTransfer  bool
+}
+
+// IsIncomplete determines if the traveller forgot to check out or the leg was corrected ("afgebroken")
+func (leg JourneyLeg) IsIncomplete() bool {
+	return leg.Correction != nil || (leg.HasCheckIn() && !leg.HasCheckOut())
+}
+
+// CheckInStationName returns the name of the station where the traveller checked in
+func (leg JourneyLeg) CheckInStationName() string {
+	if !leg.HasCheckIn() {
+		if leg.HasCheckOut() {
+			return leg.CheckOut.CheckInInfo
+		}
+		return ""
+	}
+
+	// API records contain the check-in station in the `TransactionInfo` field while for CSV records it's in `CheckInInfo`
+	if leg.CheckIn.TransactionInfo != "" {
+		return leg.CheckIn.TransactionInfo
+	}
+
+	return leg.CheckIn.CheckInInfo
+}
+
+// DeductedAmount returns the amount in cents which was deducted for an incomplete leg
+func (leg JourneyLeg) DeductedAmount() int {
+	if leg.Correction != nil && leg.Correction.DeductedAmount() > 0 {
+		return leg.Correction.DeductedAmount()
+	}
+
+	if leg.HasCheckIn() {
+		return leg.CheckIn.DeductedAmount()
+	}
+
+	return 0
+}
+
+// HasCheckIn determines if the check in record of the leg is known
+func (leg JourneyLeg) HasCheckIn() bool {
+	return leg.CheckIn != nil
+}
+
+// HasCheckOut determines if the check out record of the leg is known
+func (leg JourneyLeg) HasCheckOut() bool {
+	return leg.CheckOut != nil
+}
+
+// CompanyName returns the name of the company which operated the leg
+func (leg JourneyLeg) CompanyName() CompanyName {
+	if leg.HasCheckOut() && leg.CheckOut.Pto != "" {
+		return CompanyName(leg.CheckOut.Pto)
+	}
+
+	if leg.HasCheckIn() {
+		return CompanyName(leg.CheckIn.Pto)
+	}
+
+	return ""
+}
+
+// StartTime returns the time of the first record in the leg
+func (leg JourneyLeg) StartTime() TimeInMilliSeconds {
+	if leg.HasCheckIn() {
+		return leg.CheckIn.TransactionDateTime
+	}
+
+	if leg.HasCheckOut() {
+		return leg.CheckOut.TransactionDateTime
+	}
+
+	if len(leg.Supplements) > 0 {
+		return leg.Supplements[0].TransactionDateTime
+	}
+
+	if leg.Correction != nil {
+		return leg.Correction.TransactionDateTime
+	}
+
+	return 0
+}
+
+// EndTime returns the time of the last record in the leg
+func (leg JourneyLeg) EndTime() TimeInMilliSeconds {
+	if leg.HasCheckOut() {
+		return leg.CheckOut.TransactionDateTime
+	}
+
+	if leg.Correction != nil {
+		return leg.Correction.TransactionDateTime
+	}
+
+	if len(leg.Supplements) > 0 {
+		return leg.Supplements[len(leg.Supplements)-1].TransactionDateTime
+	}
+
+	return leg.StartTime()
+}
+
+// Records returns all the raw records which belong to the leg
+func (leg JourneyLeg) Records() (records []RawRecord) {
+	if leg.HasCheckIn() {
+		records = append(records, *leg.CheckIn)
+	}
+
+	records = append(records, leg.Supplements...)
+
+	if leg.HasCheckOut() {
+		records = append(records, *leg.CheckOut)
+	}
+
+	if leg.Correction != nil {
+		records = append(records, *leg.Correction)
+	}
+
+	return records
+}
+
+// RawRecordIDs returns the ids of all the raw records which belong to the leg
+func (leg JourneyLeg) RawRecordIDs() (ids []TransactionID) {
+	for _, record := range leg.Records() {
+		if record.ID != nil {
+			ids = append(ids, *record.ID)
+		}
+	}
+	return ids
+}
+
+// Journey is a door-to-door trip made up of one or more legs
+type Journey struct {
+	ID            *TransactionID
+	TransactionID *TransactionID
+	Legs          []JourneyLeg
+}
+
+// StartTime returns the time when the journey started
+func (journey Journey) StartTime() TimeInMilliSeconds {
+	if len(journey.Legs) == 0 {
+		return 0
+	}
+	return journey.Legs[0].StartTime()
+}
+
+// EndTime returns the time when the journey ended
+func (journey Journey) EndTime() TimeInMilliSeconds {
+	if len(journey.Legs) == 0 {
+		return 0
+	}
+	return journey.Legs[len(journey.Legs)-1].EndTime()
+}
+
+// IsMultiOperator determines if the legs of the journey were operated by more than one company
+func (journey Journey) IsMultiOperator() bool {
+	for _, leg := range journey.Legs {
+		if leg.CompanyName() != journey.Legs[0].CompanyName() {
+			return true
+		}
+	}
+	return false
+}
+
+// RawRecordIDs returns the ids of all the raw records which belong to the journey
+func (journey Journey) RawRecordIDs() (ids []TransactionID) {
+	for _, leg := range journey.Legs {
+		ids = append(ids, leg.RawRecordIDs()...)
+	}
+	return ids
+}
+
+// JourneyReconstructionService groups raw records into journeys
+type JourneyReconstructionService interface {
+	Reconstruct(records []RawRecord) []Journey
+}
+
 // GetRawRecordsOptions are settings that are passed to the RawRecordsRepository
 type GetRawRecordsOptions struct {
 	TransactionID TransactionID
@@ -265,6 +714,9 @@ type GetRawRecordsOptions struct {
 type EnrichedRecordsRepository interface {
 	Store(records []EnrichedRecord) (err error)
 	FetchAllForTransactionID(transactionID TransactionID) (records []EnrichedRecord, err error)
+	DeleteAllForTransactionID(transactionID TransactionID) (err error)
+	// FetchStaleTransactionIDs returns the imports which were enriched with an older version of the reference data
+	FetchStaleTransactionIDs(name ReferenceDataName, version int) (transactionIDs []TransactionID, err error)
 }
 
 /////////////////////////
@@ -286,4 +738,166 @@ type NationalHolidaysRepository interface {
 	Store(holidays []Holiday) (err error)
 	HasHoliday(timestamp time.Time) (result bool, err error)
 	GetByTimestamp(timestamp time.Time) (holiday Holiday, err error)
+	FetchAll() (holidays []Holiday, err error)
+}
+
+/////////////////////////
+// Operators           //
+/////////////////////////
+
+// Stop is a station or a stop where a traveller can check in or check out
+type Stop struct {
+	Code      string
+	Name      string
+	Latitude  float64
+	Longitude float64
+}
+
+// DistanceTo returns the distance in meters as the crow flies between 2 stops.
+func (stop Stop) DistanceTo(destination Stop) int {
+	const earthRadius = 6371000
+
+	lat1 := stop.Latitude * math.Pi / 180
+	lat2 := destination.Latitude * math.Pi / 180
+	deltaLat := (destination.Latitude - stop.Latitude) * math.Pi / 180
+	deltaLng := (destination.Longitude - stop.Longitude) * math.Pi / 180
+
+	a := math.Sin(deltaLat/2)*math.Sin(deltaLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(deltaLng/2)*math.Sin(deltaLng/2)
+	return int(math.Round(earthRadius * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))))
+}
+
+// Operator is a public transport company whose journeys can be enriched and priced
+type Operator interface {
+	CompanyName() CompanyName
+	OwnsRecord(record RawRecord) bool
+	ResolveStop(name string) (stop Stop, err error)
+	PriceJourney(record EnrichedRecord) (price Money, err error)
+	DiscountProducts() []DiscountProduct
+	// FareTableVersion identifies the prices which were used to calculate the price of the journeys
+	FareTableVersion(versions ReferenceDataVersions) string
+}
+
+// LegEnricher is implemented by the operators which enrich their legs themselves instead of resolving the stops of the
+// check-in and the check-out
+type LegEnricher interface {
+	EnrichLeg(leg JourneyLeg) (enrichedRecord EnrichedRecord, errorRecord ErrorRawRecord)
+}
+
+// DiscountProduct is a discount or a subscription which a traveller can buy from an operator
+type DiscountProduct interface {
+	Name() string
+	// RuleSetVersion changes when the rules which are used to calculate the price of the product change
+	RuleSetVersion() string
+	Calculate(records []EnrichedRecord) CalculationResult
+}
+
+// CalculationResult is the price of the journeys of an operator when travelling with a discount product
+type CalculationResult struct {
+	Product           string
+	CompanyName       CompanyName
+	JourneyCount      int
+	JourneysPrice     Money
+	SubscriptionPrice Money
+	Details           interface{}
+}
+
+// TotalPrice returns the price of the journeys including the subscription
+func (result CalculationResult) TotalPrice() Money {
+	return result.JourneysPrice.AddAmount(result.SubscriptionPrice.Value())
+}
+
+// StoredCalculationResult is the result of a discount product for an import which is saved every time the prices are
+// calculated. All the results which are calculated at the same time have the same run ID.
+type StoredCalculationResult struct {
+	DBTimestamp           `bson:",inline"`
+	ID                    TransactionID         `bson:"id"`
+	RunID                 TransactionID         `bson:"run_id"`
+	TransactionID         TransactionID         `bson:"transaction_id"`
+	JobID                 *TransactionID        `bson:"job_id,omitempty"`
+	Product               string                `bson:"product"`
+	CompanyName           CompanyName           `bson:"company_name"`
+	RuleSetVersion        string                `bson:"rule_set_version"`
+	FareTableVersion      string                `bson:"fare_table_version"`
+	ReferenceDataVersions ReferenceDataVersions `bson:"reference_data_versions"`
+	CalculatedAt          time.Time             `bson:"calculated_at"`
+	Currency              string                `bson:"currency"`
+	JourneyCount          int                   `bson:"journey_count"`
+	JourneysPrice         int                   `bson:"journeys_price"`
+	SubscriptionPrice     int                   `bson:"subscription_price"`
+	TotalPrice            int                   `bson:"total_price"`
+	// IsCheapest is true for the cheapest product of the company in the run
+	IsCheapest bool `bson:"is_cheapest"`
+}
+
+// CalculationResultsRepository is responsible for saving and loading the calculation results of imports
+type CalculationResultsRepository interface {
+	Store(results []StoredCalculationResult) (err error)
+	// FetchLatestForTransactionID returns the results of the last run for an import
+	FetchLatestForTransactionID(transactionID TransactionID) (results []StoredCalculationResult, err error)
+	// FetchHistoryForTransactionID returns the results of all the runs for an import with the newest run first
+	FetchHistoryForTransactionID(transactionID TransactionID) (results []StoredCalculationResult, err error)
+}
+
+/////////////////////////
+// GTFS                //
+/////////////////////////
+
+// GTFSStop is a stop from a GTFS feed together with the companies whose vehicles call at the stop
+type GTFSStop struct {
+	DBTimestamp   `bson:"db_timestamp,omitempty"`
+	ID            string        `bson:"id"`
+	Code          string        `bson:"code"`
+	Name          string        `bson:"name"`
+	Latitude      float64       `bson:"latitude"`
+	Longitude     float64       `bson:"longitude"`
+	LocationType  int           `bson:"location_type"`
+	ParentStation string        `bson:"parent_station"`
+	ZoneID        string        `bson:"zone_id"`
+	CompanyNames  []CompanyName `bson:"company_names"`
+}
+
+// IsServedBy determines if the vehicles of a company call at the stop
+func (stop GTFSStop) IsServedBy(companyName CompanyName) bool {
+	for _, name := range stop.CompanyNames {
+		if strings.EqualFold(name.String(), companyName.String()) {
+			return true
+		}
+	}
+	return false
+}
+
+// NSStationCode returns the NS station code of a train station. Train stations have a zone id like "IFF:UT".
+func (stop GTFSStop) NSStationCode() string {
+	if !strings.HasPrefix(stop.ZoneID, gtfsZonePrefixIFF) {
+		return ""
+	}
+	return strings.TrimPrefix(stop.ZoneID, gtfsZonePrefixIFF)
+}
+
+// ToStop converts a GTFS stop into a Stop
+func (stop GTFSStop) ToStop() Stop {
+	return Stop{
+		Code:      stop.ID,
+		Name:      stop.Name,
+		Latitude:  stop.Latitude,
+		Longitude: stop.Longitude,
+	}
+}
+
+// GTFSAgency is a company which operates public transport in a GTFS feed
+type GTFSAgency struct {
+	ID   string
+	Name string
+}
+
+// GTFSStopsReader reads the stops from a GTFS stops file
+type GTFSStopsReader interface {
+	ReadStops(fileName string) (stops []GTFSStop, err error)
+}
+
+// GTFSStopsRepository is responsible for saving and loading the stops of a GTFS feed
+type GTFSStopsRepository interface {
+	Store(stops []GTFSStop) (err error)
+	DeleteAll() (err error)
+	FetchAll() (stops []GTFSStop, err error)
 }
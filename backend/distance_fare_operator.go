@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DistanceFareOperator is an operator which prices journeys by distance and whose stops are in the GTFS stops.
+type DistanceFareOperator struct {
+	fareService  DistanceFareService
+	stopsService GTFSStopsService
+	products     []DiscountProduct
+}
+
+// NewDistanceFareOperator creates a new instance of the DistanceFareOperator
+func NewDistanceFareOperator(fareService DistanceFareService, stopsService GTFSStopsService, products ...DiscountProduct) DistanceFareOperator {
+	return DistanceFareOperator{fareService, stopsService, products}
+}
+
+// CompanyName returns the name of the operator
+func (operator DistanceFareOperator) CompanyName() CompanyName {
+	return operator.fareService.CompanyName()
+}
+
+// OwnsRecord determines if the raw record is for a journey with this operator
+func (operator DistanceFareOperator) OwnsRecord(record RawRecord) bool {
+	return strings.EqualFold(record.Pto, operator.CompanyName().String())
+}
+
+// ResolveStop returns the GTFS stop for a stop name
+func (operator DistanceFareOperator) ResolveStop(name string) (stop Stop, err error) {
+	gtfsStop, err := operator.stopsService.GetStopForName(name)
+	if err != nil {
+		return stop, err
+	}
+	return gtfsStop.ToStop(), nil
+}
+
+// PriceJourney returns the single fare price of a journey
+func (operator DistanceFareOperator) PriceJourney(record EnrichedRecord) (price Money, err error) {
+	return operator.fareService.Price(record.Distance), nil
+}
+
+// FareTableVersion returns the version of the tariffs and of the stops which are used to calculate the distances
+func (operator DistanceFareOperator) FareTableVersion(versions ReferenceDataVersions) string {
+	return fmt.Sprintf("tariffs-%s/%s-v%d", distanceFareTariffsVersion, referenceDataGTFSStops, versions.Get(referenceDataGTFSStops))
+}
+
+// DiscountProducts returns the discount products of the operator
+func (operator DistanceFareOperator) DiscountProducts() []DiscountProduct {
+	return operator.products
+}
+
+// newDistanceFareNoDiscountProduct returns the product for travelling without a discount
+func newDistanceFareNoDiscountProduct(fareService DistanceFareService, offPeakService NSOffPeakService) DiscountProduct {
+	return calculatorProduct{fareService.CompanyName().String() + " No Discount", func(records []EnrichedRecord) CalculationResult {
+		result := NewDistanceFareNoDiscountCalculator(fareService, offPeakService).Calculate(records)
+		return CalculationResult{
+			CompanyName:       fareService.CompanyName(),
+			JourneyCount:      result.PeakJourneyCount + result.OffPeakJourneyCount,
+			JourneysPrice:     result.TotalPrice(),
+			SubscriptionPrice: NewEUR(0),
+			Details:           result,
+		}
+	}}
+}
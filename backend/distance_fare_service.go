@@ -0,0 +1,54 @@
+package main
+
+import (
+	"math"
+)
+
+// DistanceFareService calculates the fare of a journey which costs a boarding tariff plus a price per kilometre.
+// This is how most bus, tram and metro operators price their journeys.
+type DistanceFareService struct {
+	companyName       CompanyName
+	boardingTariff    int
+	pricePerKilometre float64
+}
+
+// NewDistanceFareService creates a new instance of the DistanceFareService
+func NewDistanceFareService(companyName CompanyName, boardingTariff int, pricePerKilometre float64) DistanceFareService {
+	return DistanceFareService{
+		companyName:       companyName,
+		boardingTariff:    boardingTariff,
+		pricePerKilometre: pricePerKilometre,
+	}
+}
+
+// CompanyName returns the name of the company whose journeys are priced by this service
+func (service DistanceFareService) CompanyName() CompanyName {
+	return service.companyName
+}
+
+// Price returns the single fare price for a journey of a distance in meters
+func (service DistanceFareService) Price(distance int) Money {
+	return NewEUR(service.boardingTariff + int(math.Round(float64(distance)/1000*service.pricePerKilometre)))
+}
+
+// IsJourney determines if an enriched record is a journey which is priced by this service
+func (service DistanceFareService) IsJourney(record EnrichedRecord) bool {
+	return record.TransactionType == transactionTypeTravel && record.CompanyName == service.companyName
+}
+
+// SubscriptionMonths returns the number of calendar months in which the journeys were made
+func (service DistanceFareService) SubscriptionMonths(records []EnrichedRecord) int {
+	return subscriptionMonths(records, service.IsJourney)
+}
+
+// subscriptionMonths returns the number of calendar months in which a subscription is needed for the journeys. A
+// subscription is paid per month so a month without journeys is not counted.
+func subscriptionMonths(records []EnrichedRecord, isJourney func(record EnrichedRecord) bool) int {
+	months := map[string]bool{}
+	for _, record := range records {
+		if isJourney(record) {
+			months[record.StartTime.ToTime().Format("2006-01")] = true
+		}
+	}
+	return len(months)
+}
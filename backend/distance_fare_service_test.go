@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDistanceFareServicePrice(t *testing.T) {
+	tests := []struct {
+		name     string
+		distance int
+		price    int
+	}{
+		{name: "the boarding tariff is always charged", distance: 0, price: retBoardingTariff},
+		{name: "the price per kilometre is added", distance: 10000, price: 99 + 162},
+		{name: "the distance price is rounded", distance: 1234, price: 99 + 20},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if price := newTestRETFareService().Price(test.distance); price.Value() != test.price {
+				t.Errorf("Price(%d) = %d, want %d", test.distance, price.Value(), test.price)
+			}
+		})
+	}
+}
+
+func TestDistanceFareServiceSubscriptionMonths(t *testing.T) {
+	july := retJourneys(1, 8, 1000)[0]
+	july.StartTime = TimeInMilliSeconds(time.Date(2020, time.July, 15, 8, 0, 0, 0, time.Local).UnixNano() / int64(time.Millisecond))
+
+	missingCheckOut := retJourneys(1, 8, 1000)[0]
+	missingCheckOut.StartTime = TimeInMilliSeconds(time.Date(2020, time.August, 15, 8, 0, 0, 0, time.Local).UnixNano() / int64(time.Millisecond))
+	missingCheckOut.TransactionType = transactionTypeMissingCheckOut
+
+	tests := []struct {
+		name    string
+		records []EnrichedRecord
+		months  int
+	}{
+		{name: "no journeys", months: 0},
+		{name: "journeys in the same month", records: retJourneys(5, 8, 1000), months: 1},
+		{name: "journeys in different months", records: append(retJourneys(2, 8, 1000), july), months: 2},
+		{name: "journeys of other operators are not counted", records: nsJourneys(2, 8), months: 0},
+		{name: "missing check-outs are not counted", records: []EnrichedRecord{july, missingCheckOut}, months: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if months := newTestRETFareService().SubscriptionMonths(test.records); months != test.months {
+				t.Errorf("SubscriptionMonths() = %d, want %d", months, test.months)
+			}
+		})
+	}
+}
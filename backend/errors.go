@@ -24,6 +24,8 @@ func (error RawRecordsEnrichmentError) Error() string {
 type ErrorRawRecord struct {
 	Record RawRecord
 	Error  error
+	// StationName is the name which could not be resolved if the record failed because of an unknown station
+	StationName string
 }
 
 ///////////////////////////////
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// missCache is an LFUCache which never has a value so every lookup goes to the repositories
+type missCache struct{}
+
+func (cache missCache) Get(key interface{}) (value interface{}, err error) {
+	return value, ErrNotFound
+}
+
+func (cache missCache) Set(key interface{}, value interface{}) (err error) {
+	return nil
+}
+
+// discardErrorHandler ignores all the errors
+type discardErrorHandler struct{}
+
+func (handler discardErrorHandler) HandleSoftError(err error) {}
+
+func (handler discardErrorHandler) HandleHardError(err error) {}
+
+// noHolidaysRepository is a NationalHolidaysRepository without any holiday
+type noHolidaysRepository struct{}
+
+func (repository noHolidaysRepository) Store(holidays []Holiday) (err error) {
+	return nil
+}
+
+func (repository noHolidaysRepository) HasHoliday(timestamp time.Time) (result bool, err error) {
+	return false, nil
+}
+
+func (repository noHolidaysRepository) GetByTimestamp(timestamp time.Time) (holiday Holiday, err error) {
+	return holiday, ErrNotFound
+}
+
+func (repository noHolidaysRepository) FetchAll() (holidays []Holiday, err error) {
+	return holidays, nil
+}
+
+// fixedNSPricesRepository is an NSPricesRepository where every journey costs the same
+type fixedNSPricesRepository struct {
+	secondClassPrice int
+}
+
+func (repository fixedNSPricesRepository) Store(price NSJourneyPrice) (err error) {
+	return nil
+}
+
+func (repository fixedNSPricesRepository) GetByHash(hash string) (price NSJourneyPrice, err error) {
+	return NSJourneyPrice{
+		FirstClassSingleFarePrice:  repository.secondClassPrice * 17 / 10,
+		SecondClassSingleFarePrice: repository.secondClassPrice,
+		Hash:                       hash,
+	}, nil
+}
+
+func (repository fixedNSPricesRepository) FetchAll() (prices []NSJourneyPrice, err error) {
+	return prices, nil
+}
+
+// nsJourney creates an enriched NS journey which starts at a time in the local time zone
+func nsJourney(startTime time.Time) EnrichedRecord {
+	return EnrichedRecord{
+		StartTime:       TimeInMilliSeconds(startTime.UnixNano() / int64(time.Millisecond)),
+		FromStationCode: "UT",
+		ToStationCode:   "ASD",
+		CompanyName:     companyNameNS,
+		TransactionType: transactionTypeTravel,
+	}
+}
+
+// nsJourneys creates count journeys on the weekdays of June 2020 which start at hour:00. A day gets more than one journey
+// when count is more than the number of weekdays, they start a minute after each other.
+func nsJourneys(count int, hour int) (records []EnrichedRecord) {
+	var weekdays []time.Time
+	for day := time.Date(2020, time.June, 1, hour, 0, 0, 0, time.Local); day.Month() == time.June; day = day.AddDate(0, 0, 1) {
+		if day.Weekday() != time.Saturday && day.Weekday() != time.Sunday {
+			weekdays = append(weekdays, day)
+		}
+	}
+
+	for index := 0; index < count; index++ {
+		records = append(records, nsJourney(weekdays[index%len(weekdays)].Add(time.Duration(index/len(weekdays))*time.Minute)))
+	}
+	return records
+}
+
+// retJourneys creates count RET journeys of distance meters on the weekdays of June 2020 which start at hour:00
+func retJourneys(count int, hour int, distance int) (records []EnrichedRecord) {
+	for _, record := range nsJourneys(count, hour) {
+		record.CompanyName = companyNameRET
+		record.FromStationCode = ""
+		record.ToStationCode = ""
+		record.Distance = distance
+		records = append(records, record)
+	}
+	return records
+}
+
+// newTestRETFareService creates the DistanceFareService with the RET tariffs
+func newTestRETFareService() DistanceFareService {
+	return NewDistanceFareService(companyNameRET, retBoardingTariff, retPricePerKilometre)
+}
+
+// newTestNSOperator creates an NSOperator where every journey costs secondClassPrice and only the weekdays are peak days
+func newTestNSOperator(secondClassPrice int) NSOperator {
+	return NewNSOperator(
+		NSStationsCodeService{},
+		NewNSPriceFetcher(nil, fixedNSPricesRepository{secondClassPrice}, discardErrorHandler{}, missCache{}),
+		NewNSOffPeakService(noHolidaysRepository{}, missCache{}, discardErrorHandler{}),
+	)
+}
+
+// memoryNSStationsRepository keeps the NS stations in memory and counts how often all the stations are loaded
+type memoryNSStationsRepository struct {
+	NSStationsRepository
+	stations []NSStation
+	err      error
+	loads    int
+}
+
+func (repository *memoryNSStationsRepository) FetchAll() (stations []NSStation, err error) {
+	repository.loads++
+	return repository.stations, repository.err
+}
+
+func (repository *memoryNSStationsRepository) GetByName(name string) (station NSStation, err error) {
+	for _, station := range repository.stations {
+		if strings.EqualFold(station.Name, name) {
+			return station, nil
+		}
+	}
+	return station, ErrNotFound
+}
+
+func (repository *memoryNSStationsRepository) GetByCode(code string) (station NSStation, err error) {
+	for _, station := range repository.stations {
+		if strings.EqualFold(station.Code, code) {
+			return station, nil
+		}
+	}
+	return station, ErrNotFound
+}
+
+var testNSStations = []NSStation{
+	{Code: "asd", Name: "Amsterdam Centraal", CurrentName: "Amsterdam Centraal"},
+	{Code: "asdz", Name: "Amsterdam Zuid WTC", CurrentName: "Amsterdam Zuid"},
+	{Code: "ht", Name: "'s-Hertogenbosch", CurrentName: "'s-Hertogenbosch"},
+	{Code: "ut", Name: "Utrecht Centraal", CurrentName: "Utrecht Centraal"},
+	{Code: "zdm", Name: "Zaandam", CurrentName: "Zaandam"},
+	{Code: "zdk", Name: "Zaandak", CurrentName: "Zaandak"},
+}
+
+// memoryJobsRepository keeps the stored jobs in memory. Store fails with storeErr when it is set.
+type memoryJobsRepository struct {
+	mutex    sync.Mutex
+	jobs     []Job
+	storeErr error
+}
+
+func (repository *memoryJobsRepository) Store(job Job) (err error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if repository.storeErr != nil {
+		return repository.storeErr
+	}
+	repository.jobs = append(repository.jobs, job)
+	return nil
+}
+
+func (repository *memoryJobsRepository) Claim(workerID string, lockDuration time.Duration) (job Job, err error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	now := time.Now().UTC()
+	for index, job := range repository.jobs {
+		isReady := job.Status == jobStatusPending && !job.RunAfter.After(now)
+		isAbandoned := job.Status == jobStatusRunning && job.LockedUntil.Before(now)
+		if isReady || isAbandoned {
+			job.Status = jobStatusRunning
+			job.LockedBy = workerID
+			job.LockedUntil = now.Add(lockDuration)
+			job.Attempts++
+			repository.jobs[index] = job
+			return job, nil
+		}
+	}
+	return job, ErrNotFound
+}
+
+func (repository *memoryJobsRepository) ExtendLock(jobID TransactionID, workerID string, lockDuration time.Duration) (err error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for index, job := range repository.jobs {
+		if job.ID == jobID && job.Status == jobStatusRunning && job.LockedBy == workerID {
+			repository.jobs[index].LockedUntil = time.Now().UTC().Add(lockDuration)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (repository *memoryJobsRepository) Update(job Job) (err error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for index, stored := range repository.jobs {
+		if stored.ID == job.ID && stored.LockedBy == job.LockedBy {
+			// the lock of a running job is only changed by Claim and ExtendLock
+			job.LockedBy, job.LockedUntil = stored.LockedBy, stored.LockedUntil
+			if job.Status != jobStatusRunning {
+				job.LockedBy, job.LockedUntil = "", time.Time{}
+			}
+			repository.jobs[index] = job
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// find returns a copy of a stored job
+func (repository *memoryJobsRepository) find(jobID TransactionID) (job Job, ok bool) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for _, job := range repository.jobs {
+		if job.ID == jobID {
+			return job, true
+		}
+	}
+	return job, false
+}
+
+// memoryImportOwnersRepository keeps the owners of the imports in memory
+type memoryImportOwnersRepository map[TransactionID]TransactionID
+
+func (repository memoryImportOwnersRepository) Store(owner ImportOwner) (err error) {
+	repository[owner.TransactionID] = owner.UserID
+	return nil
+}
+
+func (repository memoryImportOwnersRepository) FetchUserID(transactionID TransactionID) (userID TransactionID, err error) {
+	userID, ok := repository[transactionID]
+	if !ok {
+		return userID, ErrNotFound
+	}
+	return userID, nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// FileSystemGTFSStopsReader implements the GTFSStopsReader interface
+type FileSystemGTFSStopsReader struct {
+	basePath string
+}
+
+// NewFileSystemGTFSStopsReader initializes a new GTFS stops file reader
+func NewFileSystemGTFSStopsReader(basePath string) FileSystemGTFSStopsReader {
+	return FileSystemGTFSStopsReader{
+		basePath: basePath,
+	}
+}
+
+// ReadStops returns the stops in a GTFS stops.txt file
+func (reader FileSystemGTFSStopsReader) ReadStops(fileName string) (stops []GTFSStop, err error) {
+	file, err := os.Open(filepath.Join(reader.basePath, fileName))
+	if err != nil {
+		return stops, errors.Wrapf(err, "could not open the GTFS stops file '%s'", fileName)
+	}
+	defer func() { _ = file.Close() }()
+
+	err = readGTFSRows(file, gtfsFileStops, func(row gtfsRow) error {
+		stop, err := parseGTFSStop(row)
+		if err != nil {
+			return err
+		}
+		stops = append(stops, stop)
+		return nil
+	})
+	if err != nil {
+		return stops, errors.Wrapf(err, "could not read the GTFS stops file '%s'", fileName)
+	}
+
+	return stops, nil
+}
@@ -0,0 +1,317 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// The reference data is stored as one JSON file per collection so that prices can be compared without a database.
+
+// FileSystemReferenceData reads and writes the JSON files of the reference data in a directory
+type FileSystemReferenceData struct {
+	directory string
+	mutex     *sync.Mutex
+}
+
+// NewFileSystemReferenceData creates a new instance of the FileSystemReferenceData
+func NewFileSystemReferenceData(directory string) FileSystemReferenceData {
+	return FileSystemReferenceData{directory, &sync.Mutex{}}
+}
+
+// read decodes the file of a collection into value. value is not changed when the file does not exist.
+func (data FileSystemReferenceData) read(collection string, value interface{}) error {
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	content, err := ioutil.ReadFile(data.fileName(collection))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "cannot read the offline %s", collection)
+	}
+
+	return errors.Wrapf(json.Unmarshal(content, value), "cannot decode the offline %s", collection)
+}
+
+// write replaces the file of a collection with value
+func (data FileSystemReferenceData) write(collection string, value interface{}) error {
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	content, err := json.Marshal(value)
+	if err != nil {
+		return errors.Wrapf(err, "cannot encode the offline %s", collection)
+	}
+
+	err = os.MkdirAll(data.directory, 0755)
+	if err != nil {
+		return errors.Wrapf(err, "cannot create the directory '%s'", data.directory)
+	}
+
+	return errors.Wrapf(ioutil.WriteFile(data.fileName(collection), content, 0644), "cannot write the offline %s", collection)
+}
+
+func (data FileSystemReferenceData) fileName(collection string) string {
+	return filepath.Join(data.directory, collection+".json")
+}
+
+// FileSystemNSStationsRepository loads the NS stations from a JSON file
+type FileSystemNSStationsRepository struct {
+	data     FileSystemReferenceData
+	stations *[]NSStation
+}
+
+// NewFileSystemNSStationsRepository loads the NS stations in the directory
+func NewFileSystemNSStationsRepository(data FileSystemReferenceData) (repository FileSystemNSStationsRepository, err error) {
+	var stations []NSStation
+	err = data.read(collectionNSStations, &stations)
+	return FileSystemNSStationsRepository{data, &stations}, err
+}
+
+// Store adds the stations to the file or replaces the stations with the same code and name
+func (repository FileSystemNSStationsRepository) Store(stations []NSStation) (err error) {
+	for _, station := range stations {
+		isStored := false
+		for index, stored := range *repository.stations {
+			if strings.EqualFold(stored.Code, station.Code) && strings.EqualFold(stored.Name, station.Name) {
+				station.ID = stored.ID
+				(*repository.stations)[index] = station
+				isStored = true
+				break
+			}
+		}
+
+		if !isStored {
+			*repository.stations = append(*repository.stations, station)
+		}
+	}
+
+	return repository.data.write(collectionNSStations, *repository.stations)
+}
+
+// GetByName fetches the first NS station with a particular name
+func (repository FileSystemNSStationsRepository) GetByName(name string) (station NSStation, err error) {
+	for _, station := range *repository.stations {
+		if station.Name == name {
+			return station, nil
+		}
+	}
+	return station, ErrNotFound
+}
+
+// GetByCode fetches the first NS station with station code
+func (repository FileSystemNSStationsRepository) GetByCode(code string) (station NSStation, err error) {
+	for _, station := range *repository.stations {
+		if station.Code == code {
+			return station, nil
+		}
+	}
+	return station, ErrNotFound
+}
+
+// FetchAll returns all the NS stations including the synonyms
+func (repository FileSystemNSStationsRepository) FetchAll() (stations []NSStation, err error) {
+	return *repository.stations, nil
+}
+
+// FileSystemNSPricesRepository loads the NS prices from a JSON file.
+// Prices which are fetched from the NS API are added to the file so they are available offline the next time.
+type FileSystemNSPricesRepository struct {
+	data   FileSystemReferenceData
+	prices map[string]NSJourneyPrice
+	mutex  *sync.RWMutex
+}
+
+// NewFileSystemNSPricesRepository loads the NS prices in the directory
+func NewFileSystemNSPricesRepository(data FileSystemReferenceData) (repository FileSystemNSPricesRepository, err error) {
+	var prices []NSJourneyPrice
+	err = data.read(collectionNSPrices, &prices)
+
+	repository = FileSystemNSPricesRepository{data, map[string]NSJourneyPrice{}, &sync.RWMutex{}}
+	for _, price := range prices {
+		repository.prices[price.Hash] = price
+	}
+
+	return repository, err
+}
+
+// Store adds a price to the file
+func (repository FileSystemNSPricesRepository) Store(price NSJourneyPrice) (err error) {
+	repository.mutex.Lock()
+	repository.prices[price.Hash] = price
+	repository.mutex.Unlock()
+
+	prices, _ := repository.FetchAll()
+	return repository.data.write(collectionNSPrices, prices)
+}
+
+// GetByHash returns the price of an NS journey based on the journey hash
+func (repository FileSystemNSPricesRepository) GetByHash(hash string) (price NSJourneyPrice, err error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	price, ok := repository.prices[hash]
+	if !ok {
+		return price, ErrNotFound
+	}
+	return price, nil
+}
+
+// FetchAll returns all the NS prices in the file
+func (repository FileSystemNSPricesRepository) FetchAll() (prices []NSJourneyPrice, err error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	for _, price := range repository.prices {
+		prices = append(prices, price)
+	}
+	return prices, nil
+}
+
+// FileSystemNationalHolidaysRepository loads the national holidays from a JSON file
+type FileSystemNationalHolidaysRepository struct {
+	data     FileSystemReferenceData
+	holidays *[]Holiday
+}
+
+// NewFileSystemNationalHolidaysRepository loads the national holidays in the directory
+func NewFileSystemNationalHolidaysRepository(data FileSystemReferenceData) (repository FileSystemNationalHolidaysRepository, err error) {
+	var holidays []Holiday
+	err = data.read(collectionNationalHolidays, &holidays)
+	return FileSystemNationalHolidaysRepository{data, &holidays}, err
+}
+
+// Store adds holidays to the file or replaces the holiday with the same date
+func (repository FileSystemNationalHolidaysRepository) Store(holidays []Holiday) (err error) {
+	for _, holiday := range holidays {
+		isStored := false
+		for index, stored := range *repository.holidays {
+			if stored.Date == holiday.Date {
+				(*repository.holidays)[index] = holiday
+				isStored = true
+				break
+			}
+		}
+
+		if !isStored {
+			*repository.holidays = append(*repository.holidays, holiday)
+		}
+	}
+
+	return repository.data.write(collectionNationalHolidays, *repository.holidays)
+}
+
+// HasHoliday checks if there is a national holiday for a given timestamp
+func (repository FileSystemNationalHolidaysRepository) HasHoliday(timestamp time.Time) (result bool, err error) {
+	_, err = repository.GetByTimestamp(timestamp)
+	if err == ErrNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// GetByTimestamp fetches the national holiday for a specific timestamp.
+func (repository FileSystemNationalHolidaysRepository) GetByTimestamp(timestamp time.Time) (holiday Holiday, err error) {
+	for _, holiday := range *repository.holidays {
+		if holiday.Date == timestamp.Format(dateFormat) {
+			return holiday, nil
+		}
+	}
+	return holiday, ErrNotFound
+}
+
+// FetchAll returns all the national holidays
+func (repository FileSystemNationalHolidaysRepository) FetchAll() (holidays []Holiday, err error) {
+	return *repository.holidays, nil
+}
+
+// FileSystemGTFSStopsRepository loads the GTFS stops from a JSON file
+type FileSystemGTFSStopsRepository struct {
+	data FileSystemReferenceData
+}
+
+// NewFileSystemGTFSStopsRepository creates a new instance of the FileSystemGTFSStopsRepository
+func NewFileSystemGTFSStopsRepository(data FileSystemReferenceData) FileSystemGTFSStopsRepository {
+	return FileSystemGTFSStopsRepository{data}
+}
+
+// Store replaces the stops in the file
+func (repository FileSystemGTFSStopsRepository) Store(stops []GTFSStop) (err error) {
+	return repository.data.write(collectionGTFSStops, stops)
+}
+
+// DeleteAll removes all the stops from the file
+func (repository FileSystemGTFSStopsRepository) DeleteAll() (err error) {
+	return repository.data.write(collectionGTFSStops, []GTFSStop{})
+}
+
+// FetchAll returns all the stops in the file
+func (repository FileSystemGTFSStopsRepository) FetchAll() (stops []GTFSStop, err error) {
+	err = repository.data.read(collectionGTFSStops, &stops)
+	return stops, err
+}
+
+// FileSystemReferenceDataVersionsRepository loads the versions of the reference data which was exported to the directory
+type FileSystemReferenceDataVersionsRepository struct {
+	data FileSystemReferenceData
+}
+
+// NewFileSystemReferenceDataVersionsRepository creates a new instance of the FileSystemReferenceDataVersionsRepository
+func NewFileSystemReferenceDataVersionsRepository(data FileSystemReferenceData) FileSystemReferenceDataVersionsRepository {
+	return FileSystemReferenceDataVersionsRepository{data}
+}
+
+// Increment increases the version of the reference data in the file
+func (repository FileSystemReferenceDataVersionsRepository) Increment(name ReferenceDataName) (version int, err error) {
+	versions, err := repository.FetchAll()
+	if err != nil {
+		return version, err
+	}
+
+	versions[name.String()]++
+	return versions[name.String()], repository.data.write(collectionReferenceData, versions)
+}
+
+// FetchAll returns the versions of the reference data in the file
+func (repository FileSystemReferenceDataVersionsRepository) FetchAll() (versions ReferenceDataVersions, err error) {
+	versions = ReferenceDataVersions{}
+	err = repository.data.read(collectionReferenceData, &versions)
+	return versions, err
+}
+
+// LogReviewQueueRepository logs the items which need to be reviewed because there is no admin to review them offline
+type LogReviewQueueRepository struct {
+	logger func(format string, values ...interface{})
+}
+
+// NewLogReviewQueueRepository creates a new instance of the LogReviewQueueRepository
+func NewLogReviewQueueRepository(logger func(format string, values ...interface{})) LogReviewQueueRepository {
+	return LogReviewQueueRepository{logger}
+}
+
+// Add logs an item which needs to be reviewed
+func (repository LogReviewQueueRepository) Add(item ReviewQueueItem) (err error) {
+	var suggestions []string
+	for _, suggestion := range item.Suggestions {
+		suggestions = append(suggestions, suggestion.Name)
+	}
+
+	repository.logger("cannot resolve '%s', did you mean: %s", item.Text, strings.Join(suggestions, ", "))
+	return nil
+}
+
+// ReplaceFailedEnrichments logs the records which could not be enriched
+func (repository LogReviewQueueRepository) ReplaceFailedEnrichments(_ TransactionID, items []ReviewQueueItem) (err error) {
+	for _, item := range items {
+		repository.logger("cannot enrich '%s': %s", item.Text, item.Reason)
+	}
+	return nil
+}
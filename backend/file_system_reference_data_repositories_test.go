@@ -0,0 +1,42 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestFileSystemNationalHolidaysRepositoryStoreReplacesHolidays(t *testing.T) {
+	directory, err := ioutil.TempDir("", "holidays")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(directory) }()
+
+	data := NewFileSystemReferenceData(directory)
+	repository, err := NewFileSystemNationalHolidaysRepository(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the holidays of a year are stored again when the offline data is synced again
+	for _, name := range []string{"Koningsdag", "King's Day"} {
+		err = repository.Store([]Holiday{{Name: name, Date: "2020-04-27"}, {Name: "Kerstmis", Date: "2020-12-25"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reloaded, err := NewFileSystemNationalHolidaysRepository(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	holidays, _ := reloaded.FetchAll()
+	if len(holidays) != 2 {
+		t.Fatalf("%d holidays stored, want 2", len(holidays))
+	}
+	if holidays[0].Name != "King's Day" {
+		t.Errorf("holiday on 2020-04-27 = %s, want King's Day", holidays[0].Name)
+	}
+}
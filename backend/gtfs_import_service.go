@@ -0,0 +1,326 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"io"
+	"log"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// GTFS file names
+const (
+	gtfsFileAgencies  = "agency.txt"
+	gtfsFileRoutes    = "routes.txt"
+	gtfsFileTrips     = "trips.txt"
+	gtfsFileStopTimes = "stop_times.txt"
+	gtfsFileStops     = "stops.txt"
+)
+
+// GTFS column names
+const (
+	gtfsColumnAgencyID      = "agency_id"
+	gtfsColumnAgencyName    = "agency_name"
+	gtfsColumnRouteID       = "route_id"
+	gtfsColumnTripID        = "trip_id"
+	gtfsColumnStopID        = "stop_id"
+	gtfsColumnStopCode      = "stop_code"
+	gtfsColumnStopName      = "stop_name"
+	gtfsColumnStopLat       = "stop_lat"
+	gtfsColumnStopLon       = "stop_lon"
+	gtfsColumnLocationType  = "location_type"
+	gtfsColumnParentStation = "parent_station"
+	gtfsColumnZoneID        = "zone_id"
+)
+
+// GTFSImportService imports the stops and agencies of a GTFS feed e.g the OpenOV/NDOV feed from http://gtfs.ovapi.nl/
+// The NS stations in the feed are also stored so that the system can be bootstrapped without an NS API key.
+type GTFSImportService struct {
+	stopsRepository    GTFSStopsRepository
+	stationsRepository NSStationsRepository
+}
+
+// NewGTFSImportService creates a new instance of the GTFSImportService
+func NewGTFSImportService(stopsRepository GTFSStopsRepository, stationsRepository NSStationsRepository) GTFSImportService {
+	return GTFSImportService{stopsRepository, stationsRepository}
+}
+
+// GTFSImportResult is the summary of a GTFS import
+type GTFSImportResult struct {
+	Agencies        []GTFSAgency
+	StopsCount      int
+	NSStationsCount int
+}
+
+// gtfsRow is a line in a GTFS file
+type gtfsRow struct {
+	columns map[string]int
+	values  []string
+}
+
+// Get returns the value of a column in the row
+func (row gtfsRow) Get(column string) string {
+	index, ok := row.columns[column]
+	if !ok || index >= len(row.values) {
+		return ""
+	}
+	return strings.TrimSpace(row.values[index])
+}
+
+// Import reads a GTFS zip file or a GTFS stops.txt file and replaces the stops in the repository. The stops in a
+// stops.txt file are not linked to companies because the file does not contain the trips.
+func (service GTFSImportService) Import(fileName string) (result GTFSImportResult, err error) {
+	stops, agencies, err := service.read(fileName)
+	if err != nil {
+		return result, err
+	}
+
+	err = service.stopsRepository.DeleteAll()
+	if err != nil {
+		return result, errors.Wrap(err, "cannot delete existing GTFS stops")
+	}
+
+	err = service.stopsRepository.Store(stops)
+	if err != nil {
+		return result, errors.Wrap(err, "cannot store GTFS stops")
+	}
+
+	// the stations are upserted so that importing the same feed again does not duplicate them
+	stations := service.getNSStations(stops)
+	if len(stations) > 0 {
+		err = service.stationsRepository.Store(stations)
+		if err != nil {
+			return result, errors.Wrap(err, "cannot store NS stations from the GTFS stops")
+		}
+	}
+
+	return GTFSImportResult{
+		Agencies:        agencies,
+		StopsCount:      len(stops),
+		NSStationsCount: len(stations),
+	}, nil
+}
+
+// read reads the stops and the agencies of a GTFS zip file or the stops of a GTFS stops.txt file
+func (service GTFSImportService) read(fileName string) (stops []GTFSStop, agencies []GTFSAgency, err error) {
+	if strings.EqualFold(filepath.Ext(fileName), ".txt") {
+		stops, err = NewFileSystemGTFSStopsReader("").ReadStops(fileName)
+		return stops, agencies, err
+	}
+
+	archive, err := zip.OpenReader(fileName)
+	if err != nil {
+		return stops, agencies, errors.Wrapf(err, "could not open the GTFS file '%s'", fileName)
+	}
+	defer func() { _ = archive.Close() }()
+
+	stops, agencies, err = service.readFeed(&archive.Reader)
+	if err != nil {
+		return stops, agencies, errors.Wrapf(err, "could not read the GTFS file '%s'", fileName)
+	}
+
+	return stops, agencies, nil
+}
+
+// readFeed reads the stops of a GTFS feed and links each stop to the companies whose trips call at the stop.
+func (service GTFSImportService) readFeed(archive *zip.Reader) (stops []GTFSStop, agencies []GTFSAgency, err error) {
+	agencyNames := map[string]string{}
+	err = service.readFile(archive, gtfsFileAgencies, false, func(row gtfsRow) error {
+		agency := GTFSAgency{ID: row.Get(gtfsColumnAgencyID), Name: row.Get(gtfsColumnAgencyName)}
+		agencyNames[agency.ID] = agency.Name
+		agencies = append(agencies, agency)
+		return nil
+	})
+	if err != nil {
+		return stops, agencies, err
+	}
+
+	routeAgencies := map[string]string{}
+	err = service.readFile(archive, gtfsFileRoutes, false, func(row gtfsRow) error {
+		routeAgencies[row.Get(gtfsColumnRouteID)] = row.Get(gtfsColumnAgencyID)
+		return nil
+	})
+	if err != nil {
+		return stops, agencies, err
+	}
+
+	tripAgencies := map[string]string{}
+	err = service.readFile(archive, gtfsFileTrips, false, func(row gtfsRow) error {
+		tripAgencies[row.Get(gtfsColumnTripID)] = routeAgencies[row.Get(gtfsColumnRouteID)]
+		return nil
+	})
+	if err != nil {
+		return stops, agencies, err
+	}
+
+	log.Printf("Reading %s for %d trips", gtfsFileStopTimes, len(tripAgencies))
+	stopAgencies := map[string]map[string]bool{}
+	err = service.readFile(archive, gtfsFileStopTimes, false, func(row gtfsRow) error {
+		agencyID, ok := tripAgencies[row.Get(gtfsColumnTripID)]
+		if !ok {
+			return nil
+		}
+
+		stopID := row.Get(gtfsColumnStopID)
+		if stopAgencies[stopID] == nil {
+			stopAgencies[stopID] = map[string]bool{}
+		}
+		stopAgencies[stopID][agencyID] = true
+		return nil
+	})
+	if err != nil {
+		return stops, agencies, err
+	}
+
+	err = service.readFile(archive, gtfsFileStops, true, func(row gtfsRow) error {
+		stop, err := parseGTFSStop(row)
+		if err != nil {
+			return err
+		}
+		stops = append(stops, stop)
+		return nil
+	})
+	if err != nil {
+		return stops, agencies, err
+	}
+
+	// Vehicles call at the platforms so stations inherit the agencies of their platforms
+	for _, stop := range stops {
+		if stop.ParentStation == "" || stopAgencies[stop.ID] == nil {
+			continue
+		}
+
+		if stopAgencies[stop.ParentStation] == nil {
+			stopAgencies[stop.ParentStation] = map[string]bool{}
+		}
+		for agencyID := range stopAgencies[stop.ID] {
+			stopAgencies[stop.ParentStation][agencyID] = true
+		}
+	}
+
+	for index := range stops {
+		for agencyID := range stopAgencies[stops[index].ID] {
+			stops[index].CompanyNames = append(stops[index].CompanyNames, CompanyName(agencyNames[agencyID]))
+		}
+	}
+
+	return stops, agencies, nil
+}
+
+// parseGTFSStop converts a row of a GTFS stops.txt file into a stop
+func parseGTFSStop(row gtfsRow) (stop GTFSStop, err error) {
+	latitude, err := strconv.ParseFloat(row.Get(gtfsColumnStopLat), 64)
+	if err != nil {
+		return stop, errors.Wrapf(err, "cannot parse latitude for stop '%s'", row.Get(gtfsColumnStopID))
+	}
+
+	longitude, err := strconv.ParseFloat(row.Get(gtfsColumnStopLon), 64)
+	if err != nil {
+		return stop, errors.Wrapf(err, "cannot parse longitude for stop '%s'", row.Get(gtfsColumnStopID))
+	}
+
+	// location_type is optional and an empty value means it's a stop or a platform
+	locationType, _ := strconv.Atoi(row.Get(gtfsColumnLocationType))
+
+	return GTFSStop{
+		ID:            row.Get(gtfsColumnStopID),
+		Code:          row.Get(gtfsColumnStopCode),
+		Name:          row.Get(gtfsColumnStopName),
+		Latitude:      latitude,
+		Longitude:     longitude,
+		LocationType:  locationType,
+		ParentStation: row.Get(gtfsColumnParentStation),
+		ZoneID:        row.Get(gtfsColumnZoneID),
+	}, nil
+}
+
+// getNSStations returns a station for every train station code in the GTFS stops
+func (service GTFSImportService) getNSStations(stops []GTFSStop) (stations []NSStation) {
+	codes := map[string]bool{}
+	for _, stop := range stops {
+		code := stop.NSStationCode()
+		if code == "" || codes[strings.ToLower(code)] {
+			continue
+		}
+		codes[strings.ToLower(code)] = true
+
+		stations = append(stations, NSStation{
+			ID:          NewTransactionID(),
+			Name:        stop.Name,
+			CurrentName: stop.Name,
+			Code:        code,
+			Country:     countryNL,
+			Latitude:    stop.Latitude,
+			Longitude:   stop.Longitude,
+		})
+	}
+
+	return stations
+}
+
+// readFile calls handle for every row of a file in the GTFS zip archive
+func (service GTFSImportService) readFile(archive *zip.Reader, name string, required bool, handle func(row gtfsRow) error) (err error) {
+	var file *zip.File
+	for _, archiveFile := range archive.File {
+		if archiveFile.Name == name {
+			file = archiveFile
+		}
+	}
+
+	if file == nil && required {
+		return errors.Errorf("the GTFS feed does not contain %s", name)
+	}
+
+	if file == nil {
+		log.Printf("the GTFS feed does not contain %s", name)
+		return nil
+	}
+
+	content, err := file.Open()
+	if err != nil {
+		return errors.Wrapf(err, "cannot open %s", name)
+	}
+	defer func() { _ = content.Close() }()
+
+	return readGTFSRows(content, name, handle)
+}
+
+// readGTFSRows calls handle for every row of a GTFS file
+func readGTFSRows(input io.Reader, name string, handle func(row gtfsRow) error) (err error) {
+	csvReader := csv.NewReader(input)
+	csvReader.FieldsPerRecord = -1
+	csvReader.ReuseRecord = true
+
+	header, err := csvReader.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "cannot read the header of %s", name)
+	}
+
+	columns := map[string]int{}
+	for index, column := range header {
+		// The header can start with a UTF-8 byte order mark
+		columns[strings.TrimPrefix(strings.TrimSpace(column), "\ufeff")] = index
+	}
+
+	for line := 2; ; line++ {
+		values, err := csvReader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "cannot read line %d of %s", line, name)
+		}
+
+		err = handle(gtfsRow{columns, values})
+		if err != nil {
+			return errors.Wrapf(err, "cannot process line %d of %s", line, name)
+		}
+	}
+}
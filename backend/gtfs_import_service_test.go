@@ -0,0 +1,180 @@
+package main
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testGTFSStops = "\ufeffstop_id,stop_code,stop_name,stop_lat,stop_lon,location_type,parent_station,zone_id\n" +
+	"stoparea:1,,Amsterdam Centraal,52.378,4.900,1,,IFF:asd\n" +
+	"platform:1a,,Amsterdam Centraal,52.378,4.900,0,stoparea:1,IFF:asd\n" +
+	"stoparea:2,,Utrecht Centraal,52.089,5.110,1,,IFF:ut\n" +
+	"bus:1,,Utrecht Centraal Jaarbeursplein,52.089,5.108,0,,\n"
+
+func writeTestGTFSFeed(t *testing.T, fileName string, files map[string]string) {
+	file, err := os.Create(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = file.Close() }()
+
+	archive := zip.NewWriter(file)
+	for name, content := range files {
+		writer, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = writer.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err = archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGTFSImportServiceImport(t *testing.T) {
+	directory, err := ioutil.TempDir("", "gtfs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(directory) }()
+
+	feedFile := filepath.Join(directory, "gtfs-nl.zip")
+	writeTestGTFSFeed(t, feedFile, map[string]string{
+		gtfsFileAgencies:  "agency_id,agency_name\nIFF:NS,NS\nQBUZZ,QBUZZ\n",
+		gtfsFileRoutes:    "route_id,agency_id\n1,IFF:NS\n2,QBUZZ\n",
+		gtfsFileTrips:     "route_id,trip_id\n1,100\n2,200\n",
+		gtfsFileStopTimes: "trip_id,stop_id\n100,platform:1a\n200,bus:1\n",
+		gtfsFileStops:     testGTFSStops,
+	})
+
+	stopsFile := filepath.Join(directory, gtfsFileStops)
+	if err = ioutil.WriteFile(stopsFile, []byte(testGTFSStops), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		fileName     string
+		agencies     int
+		companyNames map[string][]CompanyName
+	}{
+		{
+			name:     "zip file",
+			fileName: feedFile,
+			agencies: 2,
+			companyNames: map[string][]CompanyName{
+				"stoparea:1":  {"NS"},
+				"platform:1a": {"NS"},
+				"stoparea:2":  nil,
+				"bus:1":       {"QBUZZ"},
+			},
+		},
+		{
+			name:     "stops.txt file",
+			fileName: stopsFile,
+			companyNames: map[string][]CompanyName{
+				"stoparea:1":  nil,
+				"platform:1a": nil,
+				"stoparea:2":  nil,
+				"bus:1":       nil,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := NewFileSystemReferenceData(filepath.Join(directory, test.name))
+			stationsRepository, err := NewFileSystemNSStationsRepository(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			stopsRepository := NewFileSystemGTFSStopsRepository(data)
+			service := NewGTFSImportService(stopsRepository, stationsRepository)
+
+			// importing the same feed again must not duplicate the stations
+			for attempt := 0; attempt < 2; attempt++ {
+				result, err := service.Import(test.fileName)
+				if err != nil {
+					t.Fatalf("Import() error = %v", err)
+				}
+				if result.StopsCount != 4 || result.NSStationsCount != 2 || len(result.Agencies) != test.agencies {
+					t.Errorf("Import() = %+v, want 4 stops, 2 stations and %d agencies", result, test.agencies)
+				}
+			}
+
+			stops, err := stopsRepository.FetchAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(stops) != len(test.companyNames) {
+				t.Fatalf("%d stops stored, want %d", len(stops), len(test.companyNames))
+			}
+			for _, stop := range stops {
+				if !equalCompanyNames(stop.CompanyNames, test.companyNames[stop.ID]) {
+					t.Errorf("stop %s is served by %v, want %v", stop.ID, stop.CompanyNames, test.companyNames[stop.ID])
+				}
+			}
+
+			if len(*stationsRepository.stations) != 2 {
+				t.Errorf("%d stations stored, want 2", len(*stationsRepository.stations))
+			}
+			if station, err := stationsRepository.GetByCode("ut"); err != nil || station.Name != "Utrecht Centraal" {
+				t.Errorf("GetByCode(ut) = %+v, %v, want Utrecht Centraal", station, err)
+			}
+		})
+	}
+}
+
+func TestFileSystemNSStationsRepositoryStoreKeepsAliases(t *testing.T) {
+	directory, err := ioutil.TempDir("", "stations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(directory) }()
+
+	repository, err := NewFileSystemNSStationsRepository(NewFileSystemReferenceData(directory))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	station := NSStation{ID: NewTransactionID(), Code: "asd", Name: "Amsterdam Centraal"}
+	alias := NSStation{ID: NewTransactionID(), Code: "asd", Name: "Amsterdam CS"}
+	if err = repository.Store([]NSStation{station, alias}); err != nil {
+		t.Fatal(err)
+	}
+
+	station.ID = NewTransactionID()
+	station.Latitude = 52.378
+	if err = repository.Store([]NSStation{station}); err != nil {
+		t.Fatal(err)
+	}
+
+	stored := *repository.stations
+	if len(stored) != 2 {
+		t.Fatalf("%d stations stored, want 2", len(stored))
+	}
+	if stored[0].Latitude != station.Latitude || stored[0].ID == station.ID {
+		t.Errorf("station = %+v, want the updated station with the ID of the first import", stored[0])
+	}
+	if stored[1].Name != alias.Name {
+		t.Errorf("alias = %+v, want %s", stored[1], alias.Name)
+	}
+}
+
+func equalCompanyNames(got []CompanyName, want []CompanyName) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for index := range got {
+		if got[index] != want[index] {
+			return false
+		}
+	}
+	return true
+}
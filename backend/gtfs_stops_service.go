@@ -0,0 +1,72 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrorInvalidStopName is the error that is returned when the stop name does not exist in the GTFS stops.
+	ErrorInvalidStopName = errors.New("invalid stop name")
+)
+
+// GTFSStopsService is used to find the location of stops by the name used in ov-chipkaart transactions
+type GTFSStopsService struct {
+	all   []GTFSStop
+	stops map[string]GTFSStop
+}
+
+// NewGTFSStopsService creates a new GTFSStopsService from the stops in a GTFS stops file
+func NewGTFSStopsService(stops []GTFSStop) GTFSStopsService {
+	service := GTFSStopsService{all: stops, stops: map[string]GTFSStop{}}
+	for _, stop := range stops {
+		service.addStop(stop.Name, stop)
+
+		// GTFS stop names are prefixed with the town e.g "Rotterdam, Beurs" while the transactions only contain "Beurs"
+		if index := strings.LastIndex(stop.Name, ","); index != -1 {
+			service.addStop(stop.Name[index+1:], stop)
+		}
+	}
+
+	return service
+}
+
+// ForCompany returns a GTFSStopsService which only contains the stops served by a company.
+// All stops are kept when the feed was imported without agency information.
+func (service GTFSStopsService) ForCompany(companyName CompanyName) GTFSStopsService {
+	var stops []GTFSStop
+	for _, stop := range service.all {
+		if stop.IsServedBy(companyName) {
+			stops = append(stops, stop)
+		}
+	}
+
+	if len(stops) == 0 {
+		return service
+	}
+
+	return NewGTFSStopsService(stops)
+}
+
+// GetStopForName returns the stop for a name in an ov-chipkaart transaction
+func (service GTFSStopsService) GetStopForName(name string) (stop GTFSStop, err error) {
+	stop, ok := service.stops[service.key(name)]
+	if !ok {
+		return stop, ErrorInvalidStopName
+	}
+
+	return stop, nil
+}
+
+// addStop does not override existing stops because a station has many platforms with the same name
+func (service GTFSStopsService) addStop(name string, stop GTFSStop) {
+	if _, ok := service.stops[service.key(name)]; ok {
+		return
+	}
+	service.stops[service.key(name)] = stop
+}
+
+func (service GTFSStopsService) key(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
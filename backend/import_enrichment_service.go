@@ -0,0 +1,113 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ImportEnrichmentService enriches all the raw records of an import and stores the results.
+// Records which cannot be enriched are added to the review queue so that an admin can resolve them.
+type ImportEnrichmentService struct {
+	rawRecordsRepository      RawRecordsRepository
+	enrichedRecordsRepository EnrichedRecordsRepository
+	enrichmentService         RawRecordsEnrichmentService
+	reviewQueue               ReviewQueueRepository
+	referenceData             ReferenceDataService
+}
+
+// NewImportEnrichmentService creates a new instance of the ImportEnrichmentService
+func NewImportEnrichmentService(
+	rawRecordsRepository RawRecordsRepository,
+	enrichedRecordsRepository EnrichedRecordsRepository,
+	enrichmentService RawRecordsEnrichmentService,
+	reviewQueue ReviewQueueRepository,
+	referenceData ReferenceDataService,
+) ImportEnrichmentService {
+	return ImportEnrichmentService{rawRecordsRepository, enrichedRecordsRepository, enrichmentService, reviewQueue, referenceData}
+}
+
+// EnrichImport replaces the enriched records of an import with newly enriched records.
+// The records are stamped with the versions of the reference data which were used.
+func (service ImportEnrichmentService) EnrichImport(transactionID TransactionID) (results RawRecordsEnrichmentResults, err error) {
+	rawRecords, err := service.FetchRawRecords(transactionID)
+	if err != nil {
+		return results, err
+	}
+
+	return service.EnrichRawRecords(transactionID, rawRecords)
+}
+
+// FetchRawRecords returns the raw records of an import sorted by the transaction time
+func (service ImportEnrichmentService) FetchRawRecords(transactionID TransactionID) (rawRecords []RawRecord, err error) {
+	rawRecords, err = service.rawRecordsRepository.GetByTransactionID(GetRawRecordsOptions{
+		TransactionID: transactionID,
+		SortBy:        "transaction_timestamp",
+		SortDirection: "ASC",
+	})
+	if err != nil {
+		return rawRecords, errors.Wrapf(err, "cannot fetch raw records for transaction id '%s'", transactionID.String())
+	}
+
+	return rawRecords, nil
+}
+
+// EnrichRawRecords replaces the enriched records of an import with the enriched raw records
+func (service ImportEnrichmentService) EnrichRawRecords(transactionID TransactionID, rawRecords []RawRecord) (results RawRecordsEnrichmentResults, err error) {
+	versions, err := service.referenceData.CurrentVersions()
+	if err != nil {
+		return results, err
+	}
+
+	results = service.enrichmentService.Enrich(rawRecords)
+	for index := range results.ValidRecords {
+		results.ValidRecords[index].ReferenceDataVersions = versions
+	}
+
+	err = service.enrichedRecordsRepository.DeleteAllForTransactionID(transactionID)
+	if err != nil {
+		return results, err
+	}
+
+	if len(results.ValidRecords) > 0 {
+		err = service.enrichedRecordsRepository.Store(results.ValidRecords)
+		if err != nil {
+			return results, errors.Wrapf(err, "cannot store enriched records for transaction id '%s'", transactionID.String())
+		}
+	}
+
+	err = service.reviewQueue.ReplaceFailedEnrichments(transactionID, service.getReviewQueueItems(transactionID, results.Error))
+	if err != nil {
+		return results, err
+	}
+
+	return results, nil
+}
+
+func (service ImportEnrichmentService) getReviewQueueItems(transactionID TransactionID, enrichmentError RawRecordsEnrichmentError) (items []ReviewQueueItem) {
+	for _, errorRecord := range enrichmentError.ErrorRecords {
+		importID := transactionID
+		items = append(items, ReviewQueueItem{
+			ID:            NewTransactionID(),
+			Type:          reviewQueueItemTypeFailedEnrichment,
+			Text:          service.getRecordText(errorRecord.Record),
+			Status:        reviewQueueItemStatusOpen,
+			Occurrences:   1,
+			Reason:        errorRecord.Error.Error(),
+			StationName:   strings.ToLower(strings.TrimSpace(errorRecord.StationName)),
+			RawRecordID:   errorRecord.Record.ID,
+			TransactionID: &importID,
+		})
+	}
+
+	return items
+}
+
+// getRecordText returns a short description of the record which is shown to the admin
+func (service ImportEnrichmentService) getRecordText(record RawRecord) string {
+	text := record.TransactionName.String() + " " + record.TransactionInfo
+	if record.CheckInInfo != "" {
+		text = record.CheckInInfo + " - " + text
+	}
+	return strings.TrimSpace(text)
+}
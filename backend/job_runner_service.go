@@ -0,0 +1,312 @@
+package main
+
+import (
+	"context"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrorImportCredentialsExpired is returned when an import job runs after the credentials of the account expired.
+	// The job is not retried because the credentials will not come back.
+	ErrorImportCredentialsExpired = errors.New("the ov-chipkaart credentials for the import have expired")
+)
+
+// JobRunnerService runs the background jobs which are queued by the API, when the reference data changes or when an
+// admin resolves an item in the review queue. The progress of each job is saved so it can be shown to the user.
+// Jobs are claimed before they run so several workers can share the same queue.
+type JobRunnerService struct {
+	jobsRepository            JobsRepository
+	rawRecordsRepository      RawRecordsRepository
+	enrichedRecordsRepository EnrichedRecordsRepository
+	transactionFetcher        TransactionFetcher
+	credentialsStore          ImportCredentialsStore
+	importEnrichment          ImportEnrichmentService
+	referenceData             ReferenceDataService
+	calculationService        CalculationService
+	errorHandler              ErrorHandler
+}
+
+// NewJobRunnerService creates a new instance of the JobRunnerService
+func NewJobRunnerService(
+	jobsRepository JobsRepository,
+	rawRecordsRepository RawRecordsRepository,
+	enrichedRecordsRepository EnrichedRecordsRepository,
+	transactionFetcher TransactionFetcher,
+	credentialsStore ImportCredentialsStore,
+	importEnrichment ImportEnrichmentService,
+	referenceData ReferenceDataService,
+	calculationService CalculationService,
+	errorHandler ErrorHandler,
+) JobRunnerService {
+	return JobRunnerService{
+		jobsRepository,
+		rawRecordsRepository,
+		enrichedRecordsRepository,
+		transactionFetcher,
+		credentialsStore,
+		importEnrichment,
+		referenceData,
+		calculationService,
+		errorHandler,
+	}
+}
+
+// Work runs jobs until the context is cancelled. The worker waits for new jobs when the queue is empty.
+func (service JobRunnerService) Work(ctx context.Context, workerID string) {
+	log.Printf("worker '%s' started", workerID)
+	for {
+		ran, err := service.RunNext(workerID)
+		if err != nil {
+			service.errorHandler.HandleSoftError(errors.Wrapf(err, "worker '%s' cannot run the next job", workerID))
+		}
+
+		if ran && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Printf("worker '%s' stopped", workerID)
+			return
+		case <-time.After(jobPollInterval):
+		}
+	}
+}
+
+// RunPendingJobs runs jobs until the queue is empty. A job which fails does not stop the other jobs.
+func (service JobRunnerService) RunPendingJobs(workerID string) (err error) {
+	for {
+		ran, err := service.RunNext(workerID)
+		if !ran {
+			return err
+		}
+		if err != nil {
+			service.errorHandler.HandleSoftError(err)
+		}
+	}
+}
+
+// RunNext claims and runs the next job in the queue. ran is false when there is no job to run.
+func (service JobRunnerService) RunNext(workerID string) (ran bool, err error) {
+	job, err := service.jobsRepository.Claim(workerID, jobLockDuration)
+	if err == ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	log.Printf("worker '%s' is running %s job with id '%s' (attempt %d of %d)", workerID, job.Type, job.ID.String(), job.Attempts, job.MaxAttempts)
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		service.keepLocked(job.ID, workerID, jobLockRenewInterval, stop)
+	}()
+
+	err = service.run(job)
+	close(stop)
+	wg.Wait()
+	if err != nil {
+		return true, errors.Wrapf(err, "%s job with id '%s' failed", job.Type, job.ID.String())
+	}
+
+	return true, nil
+}
+
+// keepLocked extends the lock of a job every interval until stop is closed so that another worker does not claim a job
+// which takes longer than jobLockDuration
+func (service JobRunnerService) keepLocked(jobID TransactionID, workerID string, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			err := service.jobsRepository.ExtendLock(jobID, workerID, jobLockDuration)
+			if err != nil {
+				service.errorHandler.HandleSoftError(errors.Wrapf(err, "worker '%s' cannot extend the lock of job with id '%s'", workerID, jobID.String()))
+			}
+		}
+	}
+}
+
+func (service JobRunnerService) run(job Job) (err error) {
+	versions, err := service.referenceData.CurrentVersions()
+	if err != nil {
+		return service.fail(job, err)
+	}
+
+	job.ReferenceDataVersions = versions
+	job.ProcessedItems = 0
+	err = service.jobsRepository.Update(job)
+	if err != nil {
+		return err
+	}
+
+	switch job.Type {
+	case jobTypeImport:
+		err = service.importTransactions(&job)
+	case jobTypeReEnrichment:
+		err = service.reEnrich(&job)
+	case jobTypeRecalculation:
+		err = service.recalculate(&job)
+	default:
+		err = errors.Errorf("unknown job type '%s'", job.Type)
+	}
+
+	if err != nil {
+		return service.fail(job, err)
+	}
+
+	job.Status = jobStatusDone
+	job.Error = ""
+	return service.jobsRepository.Update(job)
+}
+
+// importTransactions fetches the transactions of an ov-chipkaart, stores them as raw records and queues the enrichment.
+// The raw records of a previous attempt are deleted so that retrying the job does not store transactions twice.
+func (service JobRunnerService) importTransactions(job *Job) (err error) {
+	if job.Import == nil {
+		return errors.New("the import job does not have the card details")
+	}
+
+	credentials, err := service.credentialsStore.Get(job.ID)
+	if err == ErrNotFound {
+		return ErrorImportCredentialsExpired
+	}
+	if err != nil {
+		return err
+	}
+
+	records, err := service.transactionFetcher.FetchTransactions(TransactionFetchOptions{
+		Username:   credentials.Username,
+		Password:   credentials.Password,
+		CardNumber: job.Import.CardNumber,
+		StartDate:  job.Import.StartDate,
+		EndDate:    job.Import.EndDate,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "cannot fetch transactions for card '%s'", job.Import.CardNumber)
+	}
+
+	err = service.rawRecordsRepository.DeleteAllForTransactionID(job.TransactionID)
+	if err != nil {
+		return err
+	}
+
+	source := rawRecordSourceAPI
+	for index := range records {
+		recordID := NewTransactionID()
+		records[index].ID = &recordID
+		records[index].TransactionID = &job.TransactionID
+		records[index].Source = &source
+	}
+
+	job.TotalItems = len(records)
+	if len(records) > 0 {
+		err = service.rawRecordsRepository.Store(records)
+		if err != nil {
+			return errors.Wrapf(err, "cannot store raw records for transaction id '%s'", job.TransactionID.String())
+		}
+	}
+	job.ProcessedItems = len(records)
+
+	// the enrichment is queued before the credentials are deleted so a retry can import the transactions again when
+	// the enrichment cannot be queued
+	enrichment := NewJob(jobTypeReEnrichment, job.TransactionID, "transactions were imported")
+	enrichment.UserID = job.UserID
+	err = service.jobsRepository.Store(enrichment)
+	if err != nil {
+		return err
+	}
+
+	err = service.credentialsStore.Delete(job.ID)
+	if err != nil {
+		service.errorHandler.HandleSoftError(err)
+	}
+
+	return nil
+}
+
+// reEnrich enriches the raw records of the import again and queues a recalculation of the prices
+func (service JobRunnerService) reEnrich(job *Job) (err error) {
+	rawRecords, err := service.importEnrichment.FetchRawRecords(job.TransactionID)
+	if err != nil {
+		return err
+	}
+
+	job.TotalItems = len(rawRecords)
+	err = service.jobsRepository.Update(*job)
+	if err != nil {
+		return err
+	}
+
+	results, err := service.importEnrichment.EnrichRawRecords(job.TransactionID, rawRecords)
+	if err != nil {
+		return err
+	}
+	log.Printf("%d enriched records and %d failed records", len(results.ValidRecords), len(results.Error.ErrorRecords))
+
+	job.ProcessedItems = len(rawRecords)
+
+	recalculation := NewJob(jobTypeRecalculation, job.TransactionID, "records were enriched again")
+	recalculation.UserID = job.UserID
+	return service.jobsRepository.Store(recalculation)
+}
+
+// recalculate calculates and stores the price of every discount product for the import
+func (service JobRunnerService) recalculate(job *Job) (err error) {
+	records, err := service.enrichedRecordsRepository.FetchAllForTransactionID(job.TransactionID)
+	if err != nil {
+		return errors.Wrapf(err, "cannot fetch enriched records for transaction id '%s'", job.TransactionID.String())
+	}
+
+	job.TotalItems = service.calculationService.ProductsCount()
+
+	var progressErr error
+	_, err = service.calculationService.Calculate(job.TransactionID, &job.ID, records, func() {
+		job.ProcessedItems++
+		if updateErr := service.jobsRepository.Update(*job); updateErr != nil {
+			progressErr = updateErr
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	return progressErr
+}
+
+// fail queues the job again with an exponential backoff or marks it as failed when it cannot be retried
+func (service JobRunnerService) fail(job Job, err error) error {
+	job.Error = err.Error()
+
+	if job.CanRetry() && errors.Cause(err) != ErrorImportCredentialsExpired {
+		job.Status = jobStatusPending
+		job.RunAfter = time.Now().UTC().Add(jobRetryDelay * time.Duration(math.Pow(2, float64(job.Attempts-1))))
+	} else {
+		job.Status = jobStatusFailed
+		if job.Type == jobTypeImport {
+			if deleteErr := service.credentialsStore.Delete(job.ID); deleteErr != nil {
+				service.errorHandler.HandleSoftError(deleteErr)
+			}
+		}
+	}
+
+	updateErr := service.jobsRepository.Update(job)
+	if updateErr != nil {
+		return errors.Wrap(updateErr, err.Error())
+	}
+
+	return err
+}
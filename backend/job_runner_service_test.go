@@ -0,0 +1,258 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// memoryRawRecordsRepository keeps the raw records in memory
+type memoryRawRecordsRepository struct {
+	RawRecordsRepository
+	records []RawRecord
+}
+
+func (repository *memoryRawRecordsRepository) Store(records []RawRecord) (err error) {
+	repository.records = append(repository.records, records...)
+	return nil
+}
+
+func (repository *memoryRawRecordsRepository) DeleteAllForTransactionID(transactionID TransactionID) (err error) {
+	var remaining []RawRecord
+	for _, record := range repository.records {
+		if *record.TransactionID != transactionID {
+			remaining = append(remaining, record)
+		}
+	}
+	repository.records = remaining
+	return nil
+}
+
+// failingEnrichedRecordsRepository is an EnrichedRecordsRepository which cannot be reached
+type failingEnrichedRecordsRepository struct {
+	EnrichedRecordsRepository
+}
+
+func (repository failingEnrichedRecordsRepository) FetchAllForTransactionID(transactionID TransactionID) (records []EnrichedRecord, err error) {
+	return records, errors.New("connection refused")
+}
+
+// fixedTransactionFetcher returns the same transactions for every card
+type fixedTransactionFetcher []RawRecord
+
+func (fetcher fixedTransactionFetcher) FetchTransactions(options TransactionFetchOptions) (records []RawRecord, err error) {
+	return append(records, fetcher...), nil
+}
+
+// memoryCredentialsStore keeps the credentials of the import jobs in memory
+type memoryCredentialsStore map[TransactionID]ImportCredentials
+
+func (store memoryCredentialsStore) Get(jobID TransactionID) (credentials ImportCredentials, err error) {
+	credentials, ok := store[jobID]
+	if !ok {
+		return credentials, ErrNotFound
+	}
+	return credentials, nil
+}
+
+func (store memoryCredentialsStore) Delete(jobID TransactionID) (err error) {
+	delete(store, jobID)
+	return nil
+}
+
+func newTestJobRunner(jobsRepository JobsRepository, rawRecordsRepository RawRecordsRepository, credentialsStore ImportCredentialsStore) JobRunnerService {
+	return NewJobRunnerService(
+		jobsRepository,
+		rawRecordsRepository,
+		failingEnrichedRecordsRepository{},
+		fixedTransactionFetcher{{TransactionName: transactionNameCheckIn}, {TransactionName: transactionNameCheckOut}},
+		credentialsStore,
+		ImportEnrichmentService{},
+		NewReferenceDataService(countingVersionsRepository{}, nil, nil, nil),
+		CalculationService{},
+		discardErrorHandler{},
+	)
+}
+
+func TestJobRunnerServiceRetriesFailedJobsWithBackoff(t *testing.T) {
+	jobsRepository := &memoryJobsRepository{}
+	job := NewJob(jobTypeRecalculation, NewTransactionID(), "test")
+	job.MaxAttempts = 3
+	_ = jobsRepository.Store(job)
+
+	runner := newTestJobRunner(jobsRepository, &memoryRawRecordsRepository{}, memoryCredentialsStore{})
+
+	tests := []struct {
+		attempt int
+		status  JobStatus
+		delay   time.Duration
+	}{
+		{attempt: 1, status: jobStatusPending, delay: jobRetryDelay},
+		{attempt: 2, status: jobStatusPending, delay: 2 * jobRetryDelay},
+		{attempt: 3, status: jobStatusFailed},
+	}
+
+	for _, test := range tests {
+		start := time.Now().UTC()
+		ran, err := runner.RunNext("worker")
+		if !ran || err == nil {
+			t.Fatalf("attempt %d: RunNext() = %t, %v, want a failed job", test.attempt, ran, err)
+		}
+
+		stored, _ := jobsRepository.find(job.ID)
+		if stored.Status != test.status || stored.Attempts != test.attempt {
+			t.Errorf("attempt %d: job is %s after %d attempts, want %s", test.attempt, stored.Status, stored.Attempts, test.status)
+		}
+		if stored.LockedBy != "" || !stored.LockedUntil.IsZero() {
+			t.Errorf("attempt %d: job is still locked by '%s' until %s", test.attempt, stored.LockedBy, stored.LockedUntil)
+		}
+		if stored.Error == "" {
+			t.Errorf("attempt %d: the error of the job is not stored", test.attempt)
+		}
+
+		if test.status == jobStatusPending {
+			if delay := stored.RunAfter.Sub(start); delay < test.delay || delay > test.delay+time.Second {
+				t.Errorf("attempt %d: job runs again after %s, want %s", test.attempt, delay, test.delay)
+			}
+
+			// the job cannot be claimed before the delay
+			if ran, _ := runner.RunNext("worker"); ran {
+				t.Fatalf("attempt %d: the job was claimed before the retry delay", test.attempt)
+			}
+
+			stored.RunAfter = time.Now().UTC()
+			_ = jobsRepository.Update(stored)
+		}
+	}
+
+	if ran, err := runner.RunNext("worker"); ran || err != nil {
+		t.Errorf("RunNext() after the last attempt = %t, %v, want no job", ran, err)
+	}
+}
+
+func TestJobRunnerServiceImportWithExpiredCredentialsIsNotRetried(t *testing.T) {
+	jobsRepository := &memoryJobsRepository{}
+	job := NewJob(jobTypeImport, NewTransactionID(), "test")
+	job.Import = &ImportJobOptions{CardNumber: "3528000000000000"}
+	_ = jobsRepository.Store(job)
+
+	_, err := newTestJobRunner(jobsRepository, &memoryRawRecordsRepository{}, memoryCredentialsStore{}).RunNext("worker")
+	if errors.Cause(err) != ErrorImportCredentialsExpired {
+		t.Fatalf("RunNext() error = %v, want %v", err, ErrorImportCredentialsExpired)
+	}
+
+	if stored, _ := jobsRepository.find(job.ID); stored.Status != jobStatusFailed {
+		t.Errorf("job status = %s, want %s", stored.Status, jobStatusFailed)
+	}
+}
+
+func TestJobRunnerServiceImportDeletesCredentialsAfterQueueingEnrichment(t *testing.T) {
+	tests := []struct {
+		name              string
+		storeErr          error
+		status            JobStatus
+		keepsCredentials  bool
+		enrichmentsQueued int
+	}{
+		{name: "enrichment is queued", status: jobStatusDone, enrichmentsQueued: 1},
+		{name: "enrichment cannot be queued", storeErr: errors.New("connection refused"), status: jobStatusPending, keepsCredentials: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			userID := NewTransactionID()
+			jobsRepository := &memoryJobsRepository{}
+			job := NewJob(jobTypeImport, NewTransactionID(), "test")
+			job.UserID = &userID
+			job.Import = &ImportJobOptions{CardNumber: "3528000000000000"}
+			_ = jobsRepository.Store(job)
+			jobsRepository.storeErr = test.storeErr
+
+			credentialsStore := memoryCredentialsStore{job.ID: {Username: "user", Password: "password"}}
+			rawRecordsRepository := &memoryRawRecordsRepository{}
+
+			_, _ = newTestJobRunner(jobsRepository, rawRecordsRepository, credentialsStore).RunNext("worker")
+
+			if stored, _ := jobsRepository.find(job.ID); stored.Status != test.status {
+				t.Errorf("import job status = %s, want %s", stored.Status, test.status)
+			}
+
+			if _, err := credentialsStore.Get(job.ID); (err == nil) != test.keepsCredentials {
+				t.Errorf("credentials kept = %t, want %t", err == nil, test.keepsCredentials)
+			}
+
+			if len(rawRecordsRepository.records) != 2 {
+				t.Errorf("%d raw records stored, want 2", len(rawRecordsRepository.records))
+			}
+
+			var enrichments []Job
+			for _, stored := range jobsRepository.jobs {
+				if stored.Type == jobTypeReEnrichment {
+					enrichments = append(enrichments, stored)
+				}
+			}
+			if len(enrichments) != test.enrichmentsQueued {
+				t.Fatalf("%d enrichment jobs queued, want %d", len(enrichments), test.enrichmentsQueued)
+			}
+			for _, enrichment := range enrichments {
+				if enrichment.UserID == nil || *enrichment.UserID != userID || enrichment.TransactionID != job.TransactionID {
+					t.Errorf("enrichment job = %+v, want a job for the import of user %s", enrichment, userID)
+				}
+			}
+		})
+	}
+}
+
+func TestJobRunnerServiceClaimsJobsWithExpiredLocks(t *testing.T) {
+	jobsRepository := &memoryJobsRepository{}
+	job := NewJob(jobTypeRecalculation, NewTransactionID(), "test")
+	job.Status = jobStatusRunning
+	job.Attempts = 1
+	job.LockedBy = "crashed-worker"
+	job.LockedUntil = time.Now().UTC().Add(-time.Minute)
+	_ = jobsRepository.Store(job)
+
+	ran, _ := newTestJobRunner(jobsRepository, &memoryRawRecordsRepository{}, memoryCredentialsStore{}).RunNext("worker")
+	if !ran {
+		t.Fatal("RunNext() did not claim the job of the crashed worker")
+	}
+
+	if stored, _ := jobsRepository.find(job.ID); stored.Attempts != 2 {
+		t.Errorf("attempts = %d, want 2", stored.Attempts)
+	}
+}
+
+func TestJobRunnerServiceKeepLockedExtendsTheLock(t *testing.T) {
+	jobsRepository := &memoryJobsRepository{}
+	_ = jobsRepository.Store(NewJob(jobTypeRecalculation, NewTransactionID(), "test"))
+	job, err := jobsRepository.Claim("worker", 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runner := newTestJobRunner(jobsRepository, &memoryRawRecordsRepository{}, memoryCredentialsStore{})
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		runner.keepLocked(job.ID, "worker", 10*time.Millisecond, stop)
+		close(done)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+
+	// saving the progress of the job does not shorten the extended lock
+	job.ProcessedItems = 1
+	_ = jobsRepository.Update(job)
+
+	close(stop)
+	<-done
+
+	if ran, _ := runner.RunNext("other-worker"); ran {
+		t.Error("another worker claimed the job while it was running")
+	}
+
+	if stored, _ := jobsRepository.find(job.ID); time.Until(stored.LockedUntil) < jobLockDuration-time.Minute {
+		t.Errorf("the job is locked until %s, want %s from now", stored.LockedUntil, jobLockDuration)
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+// OVJourneyReconstructionService groups consecutive raw records into door-to-door journeys.
+// A check-in which happens within the transfer window after the previous check-out, or which is an "overstap" record,
+// continues the current journey even when the operator or the modal type changes.
+type OVJourneyReconstructionService struct {
+	transferWindow time.Duration
+}
+
+// NewOVJourneyReconstructionService creates a new instance of the OVJourneyReconstructionService
+func NewOVJourneyReconstructionService(transferWindow time.Duration) OVJourneyReconstructionService {
+	return OVJourneyReconstructionService{transferWindow}
+}
+
+// Reconstruct groups the raw records into journeys. The records must be sorted by the transaction timestamp.
+func (service OVJourneyReconstructionService) Reconstruct(records []RawRecord) (journeys []Journey) {
+	var (
+		journey *Journey
+		openLeg *JourneyLeg
+	)
+
+	closeJourney := func() {
+		if openLeg != nil {
+			journey.Legs = append(journey.Legs, *openLeg)
+			openLeg = nil
+		}
+
+		if journey != nil && len(journey.Legs) > 0 {
+			journeys = append(journeys, *journey)
+		}
+		journey = nil
+	}
+
+	closeLeg := func() {
+		if openLeg != nil {
+			journey.Legs = append(journey.Legs, *openLeg)
+			openLeg = nil
+		}
+	}
+
+	for index := range records {
+		record := records[index]
+
+		switch {
+		case record.IsCheckIn() || record.IsTransfer():
+			// A new check-in while the previous leg is still open means the traveller did not check out.
+			// We don't know when that leg ended so it also ends the journey unless this is a transfer record.
+			leftOpen := openLeg != nil
+			closeLeg()
+
+			if journey == nil || !service.continuesJourney(*journey, record, leftOpen) {
+				closeJourney()
+				journey = service.newJourney(record)
+			}
+
+			openLeg = &JourneyLeg{
+				CheckIn:    &record,
+				IsTransfer: record.IsTransfer() || len(journey.Legs) > 0,
+			}
+
+		case record.IsCheckOut():
+			if openLeg != nil && service.checkOutMatchesLeg(*openLeg, record) {
+				openLeg.CheckOut = &record
+				closeLeg()
+				continue
+			}
+
+			// The check-out has no matching check-in so it starts its own leg
+			closeLeg()
+			if journey == nil || !service.continuesJourney(*journey, record, false) {
+				closeJourney()
+				journey = service.newJourney(record)
+			}
+
+			journey.Legs = append(journey.Legs, JourneyLeg{
+				CheckOut:   &record,
+				IsTransfer: len(journey.Legs) > 0,
+			})
+
+		case record.IsAborted():
+			// The correction belongs to the leg where the traveller did not check out.
+			if openLeg != nil {
+				openLeg.Correction = &record
+				closeJourney()
+				continue
+			}
+
+			closeJourney()
+			journey = service.newJourney(record)
+			journey.Legs = append(journey.Legs, JourneyLeg{Correction: &record})
+			closeJourney()
+
+		case record.IsNSSupplement():
+			if openLeg != nil {
+				openLeg.Supplements = append(openLeg.Supplements, record)
+				continue
+			}
+
+			if journey != nil && len(journey.Legs) > 0 {
+				last := &journey.Legs[len(journey.Legs)-1]
+				last.Supplements = append(last.Supplements, record)
+				continue
+			}
+
+			closeJourney()
+			journey = service.newJourney(record)
+			journey.Legs = append(journey.Legs, JourneyLeg{Supplements: []RawRecord{record}})
+		}
+
+		// Any other record (e.g topping up the ov-chipkaart) is not part of a journey.
+	}
+
+	closeJourney()
+
+	return journeys
+}
+
+// continuesJourney determines if a record belongs to the same journey as the previous legs.
+func (service OVJourneyReconstructionService) continuesJourney(journey Journey, record RawRecord, previousLegLeftOpen bool) bool {
+	if record.IsTransfer() {
+		return true
+	}
+
+	if previousLegLeftOpen || len(journey.Legs) == 0 {
+		return false
+	}
+
+	last := journey.Legs[len(journey.Legs)-1]
+	if !last.HasCheckOut() {
+		return false
+	}
+
+	gap := record.TransactionDateTime.ToTime().Sub(last.EndTime().ToTime())
+	return gap >= 0 && gap <= service.transferWindow
+}
+
+// checkOutMatchesLeg determines if a check-out record closes the leg which is currently open.
+func (service OVJourneyReconstructionService) checkOutMatchesLeg(leg JourneyLeg, checkOut RawRecord) bool {
+	if !leg.HasCheckIn() || checkOut.CheckInInfo == "" {
+		return true
+	}
+
+	station := leg.CheckInStationName()
+	return station == "" || strings.EqualFold(station, checkOut.CheckInInfo)
+}
+
+func (service OVJourneyReconstructionService) newJourney(record RawRecord) *Journey {
+	journeyID := NewTransactionID()
+	return &Journey{
+		ID:            &journeyID,
+		TransactionID: record.TransactionID,
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testJourneyStart = time.Date(2020, 6, 1, 8, 0, 0, 0, time.UTC)
+
+// testRawRecord creates a transaction minutes after the start of the test journeys at a station
+func testRawRecord(name TransactionName, minutes int, checkInStation string) RawRecord {
+	return RawRecord{
+		TransactionName:     name,
+		TransactionDateTime: TimeInMilliSeconds(testJourneyStart.Add(time.Duration(minutes)*time.Minute).UnixNano() / int64(time.Millisecond)),
+		CheckInInfo:         checkInStation,
+	}
+}
+
+// describeJourneys summarises the legs of the journeys e.g "in-out" or "transfer:in-out+1" for a transfer with a supplement
+func describeJourneys(journeys []Journey) (descriptions [][]string) {
+	for _, journey := range journeys {
+		var legs []string
+		for _, leg := range journey.Legs {
+			var parts []string
+			if leg.HasCheckIn() {
+				parts = append(parts, "in")
+			}
+			if leg.HasCheckOut() {
+				parts = append(parts, "out")
+			}
+			if leg.Correction != nil {
+				parts = append(parts, "aborted")
+			}
+
+			description := strings.Join(parts, "-")
+			if len(leg.Supplements) > 0 {
+				description += "+" + strconv.Itoa(len(leg.Supplements))
+			}
+			if leg.IsTransfer {
+				description = "transfer:" + description
+			}
+			legs = append(legs, description)
+		}
+		descriptions = append(descriptions, legs)
+	}
+	return descriptions
+}
+
+func TestOVJourneyReconstructionServiceReconstruct(t *testing.T) {
+	tests := []struct {
+		name     string
+		records  []RawRecord
+		journeys [][]string
+	}{
+		{
+			name: "single leg",
+			records: []RawRecord{
+				testRawRecord(transactionNameCheckIn, 0, "Utrecht Centraal"),
+				testRawRecord(transactionNameCheckOut, 30, "Utrecht Centraal"),
+			},
+			journeys: [][]string{{"in-out"}},
+		},
+		{
+			name: "check-in within the transfer window continues the journey",
+			records: []RawRecord{
+				testRawRecord(transactionNameCheckIn, 0, "Utrecht Centraal"),
+				testRawRecord(transactionNameCheckOut, 30, "Utrecht Centraal"),
+				testRawRecord(transactionNameCheckIn, 40, "Amsterdam Centraal"),
+				testRawRecord(transactionNameCheckOut, 60, "Amsterdam Centraal"),
+			},
+			journeys: [][]string{{"in-out", "transfer:in-out"}},
+		},
+		{
+			name: "check-in after the transfer window starts a new journey",
+			records: []RawRecord{
+				testRawRecord(transactionNameCheckIn, 0, "Utrecht Centraal"),
+				testRawRecord(transactionNameCheckOut, 30, "Utrecht Centraal"),
+				testRawRecord(transactionNameCheckIn, 30+int(transferTimeWindow/time.Minute)+1, "Amsterdam Centraal"),
+				testRawRecord(transactionNameCheckOut, 90, "Amsterdam Centraal"),
+			},
+			journeys: [][]string{{"in-out"}, {"in-out"}},
+		},
+		{
+			name: "overstap record continues the journey after the transfer window",
+			records: []RawRecord{
+				testRawRecord(transactionNameCheckIn, 0, "Utrecht Centraal"),
+				testRawRecord(transactionNameCheckOut, 30, "Utrecht Centraal"),
+				testRawRecord(transactionNameTransfer, 100, "Amsterdam Centraal"),
+				testRawRecord(transactionNameCheckOut, 120, "Amsterdam Centraal"),
+			},
+			journeys: [][]string{{"in-out", "transfer:in-out"}},
+		},
+		{
+			name: "missing check-out ends the journey",
+			records: []RawRecord{
+				testRawRecord(transactionNameCheckIn, 0, "Utrecht Centraal"),
+				testRawRecord(transactionNameCheckIn, 40, "Amsterdam Centraal"),
+				testRawRecord(transactionNameCheckOut, 60, "Amsterdam Centraal"),
+			},
+			journeys: [][]string{{"in"}, {"in-out"}},
+		},
+		{
+			name: "aborted journey is corrected",
+			records: []RawRecord{
+				testRawRecord(transactionNameCheckIn, 0, "Utrecht Centraal"),
+				testRawRecord(transactionNameAborted, 300, ""),
+			},
+			journeys: [][]string{{"in-aborted"}},
+		},
+		{
+			name: "check-out without a check-in",
+			records: []RawRecord{
+				testRawRecord(transactionNameCheckOut, 10, "Utrecht Centraal"),
+			},
+			journeys: [][]string{{"out"}},
+		},
+		{
+			name: "check-out for another check-in station",
+			records: []RawRecord{
+				testRawRecord(transactionNameCheckIn, 0, "Utrecht Centraal"),
+				testRawRecord(transactionNameCheckOut, 30, "Amsterdam Centraal"),
+			},
+			journeys: [][]string{{"in"}, {"out"}},
+		},
+		{
+			name: "supplement belongs to the open leg",
+			records: []RawRecord{
+				testRawRecord(transactionNameCheckIn, 0, "Rotterdam Centraal"),
+				testRawRecord(transactionNameIntercityDirectSurcharge, 1, "Rotterdam Centraal"),
+				testRawRecord(transactionNameCheckOut, 30, "Rotterdam Centraal"),
+			},
+			journeys: [][]string{{"in-out+1"}},
+		},
+		{
+			name: "topping up is not part of a journey",
+			records: []RawRecord{
+				testRawRecord(TransactionName("Saldo opgeladen"), 0, ""),
+				testRawRecord(transactionNameCheckIn, 5, "Utrecht Centraal"),
+				testRawRecord(transactionNameCheckOut, 30, "Utrecht Centraal"),
+			},
+			journeys: [][]string{{"in-out"}},
+		},
+	}
+
+	service := NewOVJourneyReconstructionService(transferTimeWindow)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			journeys := describeJourneys(service.Reconstruct(test.records))
+			if !reflect.DeepEqual(journeys, test.journeys) {
+				t.Errorf("Reconstruct() = %v, want %v", journeys, test.journeys)
+			}
+		})
+	}
+}
@@ -5,222 +5,302 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	lfucache "github.com/NdoleStudio/lfu-cache"
-	"github.com/davecgh/go-spew/spew"
-
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/metrics"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/tracing"
 	"github.com/getsentry/sentry-go"
+	"github.com/go-redis/redis/v8"
 	"github.com/joho/godotenv"
-	"github.com/lunux2008/xulu"
 	"github.com/pkg/errors"
+	"github.com/urfave/cli"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
-	"go.uber.org/ratelimit"
 )
 
 const localeEnglish = "en-EN"
 
+// The config file which is loaded when the --config flag is not set
+const defaultConfigFile = ".env"
+
+// Global flags
+const (
+	flagConfig             = "config"
+	flagOutput             = "output"
+	flagMongoDBURI         = "mongodb-uri"
+	flagMongoDBName        = "mongodb-db"
+	flagRedisAddress       = "redis-address"
+	flagRedisPassword      = "redis-password"
+	flagSentryDSN          = "sentry-dsn"
+	flagNSAPIKey           = "ns-api-key"
+	flagCalendarificAPIKey = "calendarific-api-key"
+	flagClientID           = "client-id"
+	flagClientSecret       = "client-secret"
+	flagTracingExporter    = "tracing-exporter"
+)
+
 func main() {
-	err := godotenv.Load()
+	// The config file sets environment variables so it must be loaded before the flags read them
+	err := loadConfigFile(os.Args)
 	if err != nil {
-		log.Fatal("error loading .env file")
+		log.Fatal(err.Error())
 	}
 
-	err = sentry.Init(sentry.ClientOptions{Dsn: os.Getenv("SENTRY_DSN")})
-	if err != nil {
-		log.Fatalf("sentry.Init: %s", err)
+	app := cli.NewApp()
+	app.Name = "ov-chipkaart"
+	app.Usage = "import, enrich and price the transactions of an ov-chipkaart"
+	app.Flags = []cli.Flag{
+		cli.StringFlag{Name: flagConfig, Value: defaultConfigFile, Usage: "file with the settings as KEY=value lines. Flags and environment variables take precedence", EnvVar: "CONFIG_FILE"},
+		cli.StringFlag{Name: flagOutput + ", o", Value: outputFormatTable, Usage: "output format: table or json", EnvVar: "OUTPUT_FORMAT"},
+		cli.StringFlag{Name: flagMongoDBURI, Usage: "connection string of the MongoDB server", EnvVar: "MONGODB_URI"},
+		cli.StringFlag{Name: flagMongoDBName, Usage: "name of the MongoDB database", EnvVar: "MONGODB_DB_NAME"},
+		cli.StringFlag{Name: flagRedisAddress, Usage: "address of the redis server which holds the credentials of import jobs", EnvVar: "REDIS_ADDRESS"},
+		cli.StringFlag{Name: flagRedisPassword, Usage: "password of the redis server", EnvVar: "REDIS_PASSWORD"},
+		cli.StringFlag{Name: flagSentryDSN, Usage: "DSN for reporting errors to sentry", EnvVar: "SENTRY_DSN"},
+		cli.StringFlag{Name: flagNSAPIKey, Usage: "key for the NS public travel information API", EnvVar: "NS_API_KEY_PUBLIC_TRAVEL_INFORMATION"},
+		cli.StringFlag{Name: flagCalendarificAPIKey, Usage: "key for the calendarific API", EnvVar: "CALENDARIFIC_API_KEY"},
+		cli.StringFlag{Name: flagClientID, Usage: "client ID for the ov-chipkaart API", EnvVar: "CLIENT_ID"},
+		cli.StringFlag{Name: flagClientSecret, Usage: "client secret for the ov-chipkaart API", EnvVar: "CLIENT_SECRET"},
+		cli.StringFlag{Name: flagTracingExporter, Value: tracing.ExporterNone, Usage: "exporter of the OpenTelemetry spans: none or stdout", EnvVar: "TRACING_EXPORTER"},
+	}
+	app.Commands = commands()
+
+	app.Before = func(c *cli.Context) error {
+		err := tracing.Install(c.GlobalString(flagTracingExporter))
+		if err != nil {
+			return err
+		}
+
+		err = sentry.Init(sentry.ClientOptions{Dsn: c.GlobalString(flagSentryDSN)})
+		return errors.Wrap(err, "cannot initialize sentry")
 	}
 
 	// Flush buffered events before the program terminates.
-	defer sentry.Flush(2 * time.Second)
-	sentry.CaptureMessage("It works!")
+	app.After = func(c *cli.Context) error {
+		sentry.Flush(2 * time.Second)
+		return nil
+	}
 
-	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(os.Getenv("MONGODB_URI")))
+	err = app.Run(os.Args)
 	if err != nil {
-		log.Fatal(errors.Wrapf(err, "cannot connect to mongoDB"))
+		log.Fatal(err.Error())
 	}
+}
 
-	mongodb := client.Database(os.Getenv("MONGODB_DB_NAME"))
-	//loadNsStations(mongodb)
+// loadConfigFile loads the settings in the config file into environment variables which are not set.
+// The default config file is optional but a config file which is passed with --config must exist.
+func loadConfigFile(args []string) error {
+	fileName, isSet := os.Getenv("CONFIG_FILE"), os.Getenv("CONFIG_FILE") != ""
+	for index, arg := range args {
+		if arg == "--"+flagConfig && index+1 < len(args) {
+			fileName, isSet = args[index+1], true
+		}
+		if strings.HasPrefix(arg, "--"+flagConfig+"=") {
+			fileName, isSet = strings.TrimPrefix(arg, "--"+flagConfig+"="), true
+		}
+	}
 
-	/*err = mongodb.Collection(collectionRawRecords).Drop(context.Background())
+	if !isSet {
+		fileName = defaultConfigFile
+		if _, err := os.Stat(fileName); os.IsNotExist(err) {
+			return nil
+		}
+	}
+
+	return errors.Wrapf(godotenv.Load(fileName), "cannot load the config file '%s'", fileName)
+}
+
+// connectMongoDB connects to the database which is set with the global flags
+func connectMongoDB(c *cli.Context) (*mongo.Database, error) {
+	if c.GlobalString(flagMongoDBURI) == "" || c.GlobalString(flagMongoDBName) == "" {
+		return nil, errors.Errorf("the --%s and --%s flags are required", flagMongoDBURI, flagMongoDBName)
+	}
+
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(c.GlobalString(flagMongoDBURI)).SetMonitor(metrics.NewMongoDBMonitor(tracing.NewMongoDBMonitor())))
 	if err != nil {
-		log.Fatalf(err.Error())
-	}*/
+		return nil, errors.Wrapf(err, "cannot connect to mongoDB")
+	}
 
-	//err = mongodb.Collection(collectionNSEnrichedRecords).Drop(context.Background())
-	//if err != nil {
-	//	log.Fatalf(err.Error())
-	//}
+	return client.Database(c.GlobalString(flagMongoDBName)), nil
+}
 
-	//storeNSTransactions(mongodb)
-	//storeNationalHolidays(mongodb)
+// Pipeline are the services which import, enrich and price the transactions of an ov-chipkaart
+type Pipeline struct {
+	mongodb                   *mongo.Database
+	errorHandler              ErrorHandler
+	rawRecordsRepository      RawRecordsRepository
+	enrichedRecordsRepository EnrichedRecordsRepository
+	referenceData             ReferenceDataService
+	importEnrichment          ImportEnrichmentService
+	calculationService        CalculationService
+	jobRunner                 JobRunnerService
+}
+
+// initializePipeline creates the services which are needed to enrich and price imports
+func initializePipeline(c *cli.Context, mongodb *mongo.Database) (pipeline Pipeline, err error) {
+	lfuCache, err := lfucache.New(100)
+	if err != nil {
+		return pipeline, errors.Wrap(err, "cannot create cache")
+	}
+	cache := NewMetricsLFUCache("stations_and_prices", lfuCache)
+
+	stopsRepository := NewMongoGTFSStopsRepository(mongodb, collectionGTFSStops, NewBsonService())
+	stops, err := loadGTFSStops(stopsRepository)
+	if err != nil {
+		return pipeline, err
+	}
 
-	//
 	bsonService := NewBsonService()
 	errorHandler := NewSentryErrorHandler()
 	rawRecordsRepository := InitializeRawRecordsRepository(collectionRawRecords, mongodb)
-
 	enrichedRecordsRepository := NewMongoNSEnrichedRecordsRepository(mongodb, collectionNSEnrichedRecords, bsonService)
-	cache, err := lfucache.New(100)
-	nsClient := NewNSAPIClient(&http.Client{}, os.Getenv("NS_API_KEY_PUBLIC_TRAVEL_INFORMATION"))
+	nsClient := NewNSAPIClient(&http.Client{}, c.GlobalString(flagNSAPIKey))
 	pricesRepository := NewMongoNSPricesRepository(mongodb, collectionNSPrices, bsonService)
 	stationsRepository := NewMongoNSStationsRepository(mongodb, collectionNSStations, bsonService)
 	priceFetcher := NewNSPriceFetcher(nsClient, pricesRepository, errorHandler, cache)
-	stationCodeService := NewNSStationsCodeService(stationsRepository, errorHandler, cache)
-	enrichmentService := NewNSRawRecordsEnrichmentService(stationCodeService, priceFetcher)
-	//
-	log.Println("Fetching first transaction")
-	id, err := rawRecordsRepository.First()
-	if err != nil {
-		errorHandler.HandleHardError(err)
-	}
-	log.Println("Finished fetching first transaction")
-
-	globalTransactionID := *id.TransactionID
-	//getOptions := GetRawRecordsOptions{
-	//	TransactionID: globalTransactionID,
-	//	SortBy:        "transaction_timestamp",
-	//	SortDirection: "ASC",
-	//}
-
-	//log.Println("fetching raw records from DB")
-	//rawRecords, err := rawRecordsRepository.GetByTransactionID(getOptions)
-	//if err != nil {
-	//	errorHandler.HandleHardError(err)
-	//}
-	////
-	//log.Printf("%d raw records fetched\n", len(rawRecords))
-	//
-	//log.Println("Fetching enriched records")
-	//enrichmentResult := enrichmentService.Enrich(rawRecords)
-	//log.Println("Finished enriching records")
-	//
-	//log.Printf("%d enriched records and %d failed records\n", len(enrichmentResult.ValidRecords), len(enrichmentResult.Error.ErrorRecords))
-	//
-	//log.Println("Starting storing of enriched records")
-	//err = enrichedRecordsRepository.Store(enrichmentResult.ValidRecords)
-	//if err != nil {
-	//	errorHandler.HandleHardError(err)
-	//}
-	//log.Println("Finished storing of enriched records")
-
+	reviewQueueRepository := NewMongoReviewQueueRepository(mongodb, collectionReviewQueue, bsonService)
+	stationCodeService := NewNSStationsCodeService(stationsRepository, NewNSStationNameResolver(stationsRepository, NewMongoReferenceDataVersionsRepository(mongodb, collectionReferenceData, bsonService)), reviewQueueRepository, errorHandler, cache)
 	nationalHolidayRepository := InitializeNationalHolidaysRepository(collectionNationalHolidays, mongodb)
-	offPeakService := NewNSOffPeakService(nationalHolidayRepository, InitializeCache(100), NewSentryErrorHandler())
-	noDiscountCalculatorService := NewNSNoDiscountCalculator(priceFetcher, offPeakService)
-
-	enrichedRecords, err := enrichedRecordsRepository.FetchAllForTransactionID(globalTransactionID)
+	offPeakService := NewNSOffPeakService(nationalHolidayRepository, NewMetricsLFUCache("national_holidays", InitializeCache(100)), NewSentryErrorHandler())
+	operators := registerOperators(stops, stationCodeService, priceFetcher, offPeakService)
+	journeyService := NewOVJourneyReconstructionService(transferTimeWindow)
+	missingCheckOutService := NewMissingCheckOutService(stationCodeService, priceFetcher, errorHandler)
+	enrichmentService := NewNSRawRecordsEnrichmentService(journeyService, missingCheckOutService, operators)
+	jobsRepository := NewMongoJobsRepository(mongodb, collectionJobs, bsonService)
+	err = jobsRepository.CreateIndexes()
 	if err != nil {
-		log.Fatalf(err.Error())
+		return pipeline, err
 	}
-
-	result := noDiscountCalculatorService.Calculate(enrichedRecords)
-	spew.Dump(result)
-
-	darVoordeelCalculator := NewNSDalVoordeelCalculator(priceFetcher, offPeakService)
-	dalVoordeel := darVoordeelCalculator.Calculate(enrichedRecords)
-	spew.Dump(dalVoordeel)
-
-	altijdVoordeelCalculator := NewNSAltijdVoordeelCalculator(priceFetcher, offPeakService)
-	altijdVoordeel := altijdVoordeelCalculator.Calculate(enrichedRecords)
-
-	spew.Dump(altijdVoordeel)
-
-	dalVrijCalculator := NewNSDalVrijCalculator(priceFetcher, offPeakService)
-	dalVrij := dalVrijCalculator.Calculate(enrichedRecords)
-
-	spew.Dump(dalVrij)
-
-	xulu.Use(enrichmentService)
+	referenceDataService := initializeReferenceDataService(mongodb)
+	importEnrichmentService := NewImportEnrichmentService(rawRecordsRepository, enrichedRecordsRepository, enrichmentService, reviewQueueRepository, referenceDataService)
+	calculationResultsRepository := NewMongoCalculationResultsRepository(mongodb, collectionCalculations, bsonService)
+	calculationService := NewCalculationService(operators, calculationResultsRepository, referenceDataService)
+	jobRunner := NewJobRunnerService(
+		jobsRepository,
+		rawRecordsRepository,
+		enrichedRecordsRepository,
+		initializeTransactionFetcher(c),
+		NewRedisImportCredentialsStore(initializeRedis(c)),
+		importEnrichmentService,
+		referenceDataService,
+		calculationService,
+		errorHandler,
+	)
+
+	return Pipeline{
+		mongodb:                   mongodb,
+		errorHandler:              errorHandler,
+		rawRecordsRepository:      rawRecordsRepository,
+		enrichedRecordsRepository: enrichedRecordsRepository,
+		referenceData:             referenceDataService,
+		importEnrichment:          importEnrichmentService,
+		calculationService:        calculationService,
+		jobRunner:                 jobRunner,
+	}, nil
 }
 
-func storeNationalHolidays(db *mongo.Database) {
-	nationalHolidaysRepository := InitializeNationalHolidaysRepository(collectionNationalHolidays, db)
-	holidaysClient := NewCalendarificAPIClient(os.Getenv("CALENDARIFIC_API_KEY"), &http.Client{})
-
-	rateLimiter := ratelimit.New(1)
-	for i := 0; i < 3; i++ {
-		rateLimiter.Take()
-
-		holidays, err := holidaysClient.FetchNationalHolidays(time.Now().AddDate(i-1, 0, 0))
-		if err != nil {
-			log.Fatalf(err.Error())
-		}
+// runJobWorkers runs the jobs in the queue until the process receives SIGINT or SIGTERM.
+func runJobWorkers(jobRunner JobRunnerService, workersCount int) {
+	if workersCount < 1 {
+		workersCount = 1
+	}
 
-		err = nationalHolidaysRepository.Store(holidays)
-		if err != nil {
-			log.Fatalf(err.Error())
-		}
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-signals
+		log.Println("Stopping workers after their current jobs")
+		cancel()
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workersCount; i++ {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			jobRunner.Work(ctx, workerID(index))
+		}(i)
 	}
+	wg.Wait()
 }
-func loadNsStations(mongodb *mongo.Database) {
-	bsonService := BsonService{}
-	nsStationsRepository := NewMongoNSStationsRepository(mongodb, collectionNSStations, bsonService)
-	//
-	log.Printf("Fetching Stations")
-	nsClient := NewNSAPIClient(&http.Client{}, os.Getenv("NS_API_KEY_PUBLIC_TRAVEL_INFORMATION"))
-	stations, err := nsClient.GetAllStations()
-	if err != nil {
-		log.Fatalf(err.Error())
-	}
-	log.Printf("Stations fetch finished")
 
-	log.Printf("Storing stations in the database")
-	err = nsStationsRepository.Store(stations)
+// workerID identifies a worker so that we know which process locked a job
+func workerID(index int) string {
+	hostname, err := os.Hostname()
 	if err != nil {
-		log.Fatalf(err.Error())
+		hostname = "localhost"
 	}
-	log.Printf("Finished storing stations")
+	return hostname + "-" + strconv.Itoa(os.Getpid()) + "-" + strconv.Itoa(index)
 }
 
-func storeNSTransactions(mongodb *mongo.Database) {
-	bsonService := NewBsonService()
-	rawRecordsRepository := NewMongodbRawRecordsRepository(mongodb, collectionRawRecords, bsonService)
+func initializeRedis(c *cli.Context) *redis.Client {
+	return redis.NewClient(&redis.Options{
+		Addr:     c.GlobalString(flagRedisAddress),
+		Password: c.GlobalString(flagRedisPassword),
+		DB:       0,
+	})
+}
 
-	//
-	config := TransactionFetcherAPIServiceConfig{
-		ClientID:     os.Getenv("CLIENT_ID"),
-		ClientSecret: os.Getenv("CLIENT_SECRET"),
+func initializeTransactionFetcher(c *cli.Context) TransactionFetcher {
+	return NewAPIService(TransactionFetcherAPIServiceConfig{
+		ClientID:     c.GlobalString(flagClientID),
+		ClientSecret: c.GlobalString(flagClientSecret),
 		Locale:       localeEnglish,
 		Client:       &http.Client{},
-	}
-	apiService := NewAPIService(config)
+	})
+}
 
-	transactionConfig := TransactionFetchOptions{
-		Username:   os.Getenv("OV_CHIPKAAT_USERNAME"),
-		Password:   os.Getenv("OV_CHIPKAAT_PASSWORD"),
-		CardNumber: os.Getenv("OV_CHIPKAAT_CARD_NUMBER"),
-		StartDate:  time.Unix(0, 0),
-		EndDate:    time.Now(),
-	}
+// registerOperators registers the operators whose journeys can be enriched and priced
+func registerOperators(stops []GTFSStop, stationCodeService NSStationsCodeService, priceFetcher NSPriceFetcherService, offPeakService NSOffPeakService) *OperatorRegistry {
+	stopsService := NewGTFSStopsService(stops)
 
-	globalTransactionID := NewTransactionID()
+	operators := NewOperatorRegistry()
+	operators.Register(NewNSOperator(stationCodeService, priceFetcher, offPeakService))
+	operators.Register(NewRETOperator(stopsService.ForCompany(companyNameRET), offPeakService))
+	operators.Register(NewRegionalOperators(stopsService, offPeakService)...)
 
-	//
-	log.Println("Fetching Transactions")
-	records, err := apiService.FetchTransactions(transactionConfig)
+	return operators
+}
+
+// initializeReferenceDataService creates the service which queues jobs when the reference data changes
+func initializeReferenceDataService(mongodb *mongo.Database) ReferenceDataService {
+	bsonService := NewBsonService()
+	return NewReferenceDataService(
+		NewMongoReferenceDataVersionsRepository(mongodb, collectionReferenceData, bsonService),
+		NewMongoNSEnrichedRecordsRepository(mongodb, collectionNSEnrichedRecords, bsonService),
+		NewMongoJobsRepository(mongodb, collectionJobs, bsonService),
+		NewMongoImportOwnersRepository(mongodb, collectionImports, bsonService),
+	)
+}
+
+func loadGTFSStops(stopsRepository GTFSStopsRepository) (stops []GTFSStop, err error) {
+	log.Printf("Loading GTFS stops")
+	stops, err = stopsRepository.FetchAll()
 	if err != nil {
-		log.Panicf(errors.Wrapf(err, "%+v", err).Error())
+		return stops, errors.Wrap(err, "cannot load GTFS stops")
 	}
-	log.Println("Finished Fetching transactions")
+	log.Printf("Finished loading %d GTFS stops", len(stops))
+
+	return stops, nil
+}
 
-	// Enriching records
-	source := rawRecordSourceAPI
-	transactionID := globalTransactionID
-	for index := range records {
-		recordID := NewTransactionID()
-		records[index].TransactionID = &transactionID
-		records[index].Source = &source
-		records[index].ID = &recordID
-		println(records[index].TransactionName)
+// parseDate parses a date flag. The fallback is used when the flag is empty.
+func parseDate(value string, fallback time.Time) (time.Time, error) {
+	if value == "" {
+		return fallback, nil
 	}
 
-	log.Println(len(records))
-	log.Println("Inserting into database")
-	err = rawRecordsRepository.Store(records)
+	date, err := time.Parse(dateFormat, value)
 	if err != nil {
-		log.Panicf(errors.Wrapf(err, "%+v", err).Error())
+		return date, errors.Errorf("the date '%s' does not have the format YYYY-MM-DD", value)
 	}
 
+	return date, nil
 }
@@ -0,0 +1,26 @@
+package main
+
+import "github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/metrics"
+
+// MetricsLFUCache records the hits and the misses of an LFU cache
+type MetricsLFUCache struct {
+	name  string
+	cache LFUCache
+}
+
+// NewMetricsLFUCache creates a new instance of the MetricsLFUCache. The name is the label of the cache in the metrics.
+func NewMetricsLFUCache(name string, cache LFUCache) LFUCache {
+	return MetricsLFUCache{name: name, cache: cache}
+}
+
+// Get returns a value from the cache and records if it was found
+func (cache MetricsLFUCache) Get(key interface{}) (value interface{}, err error) {
+	value, err = cache.cache.Get(key)
+	metrics.CountCacheRequest(cache.name, err == nil)
+	return value, err
+}
+
+// Set stores a value in the cache
+func (cache MetricsLFUCache) Set(key interface{}, value interface{}) (err error) {
+	return cache.cache.Set(key, value)
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"log"
+
+	"github.com/pkg/errors"
+)
+
+// MissingCheckOutService detects journeys where the traveller forgot to check out.
+// The maximum fare is deducted for these journeys and the difference with the actual fare can be reclaimed from the operator.
+type MissingCheckOutService struct {
+	stationsCodeService NSStationsCodeService
+	priceFetcher        NSPriceFetcherService
+	errorHandler        ErrorHandler
+}
+
+// NewMissingCheckOutService creates a new instance of the MissingCheckOutService
+func NewMissingCheckOutService(stationsCodeService NSStationsCodeService, priceFetcher NSPriceFetcherService, errorHandler ErrorHandler) MissingCheckOutService {
+	return MissingCheckOutService{stationsCodeService, priceFetcher, errorHandler}
+}
+
+// Detect returns an enriched record for every incomplete leg in the journeys
+func (service MissingCheckOutService) Detect(journeys []Journey) (records []EnrichedRecord) {
+	for journeyIndex, journey := range journeys {
+		for legIndex, leg := range journey.Legs {
+			if !leg.IsIncomplete() {
+				continue
+			}
+
+			records = append(records, service.getEnrichedRecord(journey, leg, service.nextStationName(journeys, journeyIndex, legIndex)))
+		}
+	}
+
+	return records
+}
+
+func (service MissingCheckOutService) getEnrichedRecord(journey Journey, leg JourneyLeg, nextStationName string) EnrichedRecord {
+	record := leg.Records()[0]
+	enrichedRecordID := NewTransactionID()
+
+	enrichedRecord := EnrichedRecord{
+		RawRecordID:      record.ID,
+		TransactionID:    record.TransactionID,
+		ID:               &enrichedRecordID,
+		StartTime:        leg.StartTime(),
+		EndTime:          leg.EndTime(),
+		StartTimeIsExact: leg.HasCheckIn(),
+		CompanyName:      leg.CompanyName(),
+		TransactionType:  transactionTypeMissingCheckOut,
+		JourneyID:        journey.ID,
+		RawRecordIDs:     leg.RawRecordIDs(),
+		DeductedAmount:   leg.DeductedAmount(),
+	}
+
+	// The boarding tariff is always charged so it can never be refunded
+	enrichedRecord.RefundAmount = enrichedRecord.DeductedAmount - basicFare
+
+	if enrichedRecord.CompanyName != companyNameNS || leg.CheckInStationName() == "" {
+		return service.withValidRefund(enrichedRecord)
+	}
+
+	fromStation, err := service.stationsCodeService.GetCodeForStationName(leg.CheckInStationName())
+	if err != nil {
+		service.errorHandler.HandleSoftError(errors.Wrapf(err, "cannot get code for station: %s", leg.CheckInStationName()))
+		return service.withValidRefund(enrichedRecord)
+	}
+	enrichedRecord.FromStationCode = fromStation.Code
+
+	if nextStationName == "" {
+		return service.withValidRefund(enrichedRecord)
+	}
+
+	// We assume that the traveller's destination is the station where they checked in next.
+	toStation, err := service.stationsCodeService.GetCodeForStationName(nextStationName)
+	if err != nil {
+		log.Printf("cannot estimate the destination of the journey from station '%s'", nextStationName)
+		return service.withValidRefund(enrichedRecord)
+	}
+	enrichedRecord.ToStationCode = toStation.Code
+
+	// The traveller checked in and out at the same station so the whole amount can be refunded
+	if fromStation.Code == toStation.Code {
+		enrichedRecord.RefundAmount = enrichedRecord.DeductedAmount
+		return service.withValidRefund(enrichedRecord)
+	}
+
+	price, err := service.priceFetcher.FetchPrice(NewNSJourney(leg.StartTime().ToTime(), fromStation.Code, toStation.Code))
+	if err != nil {
+		service.errorHandler.HandleSoftError(errors.Wrap(err, "cannot fetch price for journey with a missing check-out"))
+		return service.withValidRefund(enrichedRecord)
+	}
+
+	enrichedRecord.RefundAmount = enrichedRecord.DeductedAmount - price.SecondClassSingleFarePrice
+	return service.withValidRefund(enrichedRecord)
+}
+
+// nextStationName returns the name of the station where the traveller checked in after an incomplete leg
+func (service MissingCheckOutService) nextStationName(journeys []Journey, journeyIndex, legIndex int) string {
+	var legs []JourneyLeg
+	legs = append(legs, journeys[journeyIndex].Legs[legIndex+1:]...)
+	if journeyIndex+1 < len(journeys) {
+		legs = append(legs, journeys[journeyIndex+1].Legs...)
+	}
+
+	for _, leg := range legs {
+		if name := leg.CheckInStationName(); name != "" {
+			return name
+		}
+	}
+
+	return ""
+}
+
+func (service MissingCheckOutService) withValidRefund(record EnrichedRecord) EnrichedRecord {
+	if record.RefundAmount < 0 {
+		record.RefundAmount = 0
+	}
+	return record
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"testing"
+)
+
+// testLegRecord creates a transaction of a company at a station where e.g a check-in deducted amount euros
+func testLegRecord(name TransactionName, minutes int, company CompanyName, checkInStation string, amount float64) *RawRecord {
+	record := testRawRecord(name, minutes, checkInStation)
+	record.Pto = company.String()
+	record.ModalType = "Trein"
+	mutation := -amount
+	record.EPurseMut = &mutation
+	return &record
+}
+
+func TestMissingCheckOutServiceDetect(t *testing.T) {
+	tests := []struct {
+		name     string
+		price    int
+		journeys []Journey
+		want     []EnrichedRecord
+	}{
+		{
+			name:  "complete legs are skipped",
+			price: 1000,
+			journeys: []Journey{{Legs: []JourneyLeg{{
+				CheckIn:  testLegRecord(transactionNameCheckIn, 0, companyNameNS, "Utrecht Centraal", 20),
+				CheckOut: testLegRecord(transactionNameCheckOut, 30, companyNameNS, "Utrecht Centraal", 0),
+			}}}},
+		},
+		{
+			name:  "the destination is the next check-in station",
+			price: 1000,
+			journeys: []Journey{
+				{Legs: []JourneyLeg{{CheckIn: testLegRecord(transactionNameCheckIn, 0, companyNameNS, "Utrecht Centraal", 20)}}},
+				{Legs: []JourneyLeg{{CheckIn: testLegRecord(transactionNameCheckIn, 120, companyNameNS, "Amsterdam Centraal", 20)}}},
+			},
+			want: []EnrichedRecord{
+				{FromStationCode: "ut", ToStationCode: "asd", DeductedAmount: 2000, RefundAmount: 1000},
+				{FromStationCode: "asd", DeductedAmount: 2000, RefundAmount: 2000 - basicFare},
+			},
+		},
+		{
+			name:  "a check-in at the same station is refunded completely",
+			price: 1000,
+			journeys: []Journey{
+				{Legs: []JourneyLeg{{CheckIn: testLegRecord(transactionNameCheckIn, 0, companyNameNS, "Utrecht Centraal", 20)}}},
+				{Legs: []JourneyLeg{{
+					CheckIn:  testLegRecord(transactionNameCheckIn, 5, companyNameNS, "Utrecht Centraal", 20),
+					CheckOut: testLegRecord(transactionNameCheckOut, 35, companyNameNS, "Utrecht Centraal", 0),
+				}}},
+			},
+			want: []EnrichedRecord{{FromStationCode: "ut", ToStationCode: "ut", DeductedAmount: 2000, RefundAmount: 2000}},
+		},
+		{
+			name:  "a price above the deducted amount is not refunded",
+			price: 3000,
+			journeys: []Journey{
+				{Legs: []JourneyLeg{{CheckIn: testLegRecord(transactionNameCheckIn, 0, companyNameNS, "Utrecht Centraal", 20)}}},
+				{Legs: []JourneyLeg{{
+					CheckIn:  testLegRecord(transactionNameCheckIn, 120, companyNameNS, "Amsterdam Centraal", 20),
+					CheckOut: testLegRecord(transactionNameCheckOut, 150, companyNameNS, "Amsterdam Centraal", 0),
+				}}},
+			},
+			want: []EnrichedRecord{{FromStationCode: "ut", ToStationCode: "asd", DeductedAmount: 2000, RefundAmount: 0}},
+		},
+		{
+			name:  "an unknown station only keeps the boarding tariff",
+			price: 1000,
+			journeys: []Journey{
+				{Legs: []JourneyLeg{{CheckIn: testLegRecord(transactionNameCheckIn, 0, companyNameNS, "Nergenshuizen", 20)}}},
+				{Legs: []JourneyLeg{{CheckIn: testLegRecord(transactionNameCheckIn, 120, companyNameNS, "Amsterdam Centraal", 20)}}},
+			},
+			want: []EnrichedRecord{
+				{DeductedAmount: 2000, RefundAmount: 2000 - basicFare},
+				{FromStationCode: "asd", DeductedAmount: 2000, RefundAmount: 2000 - basicFare},
+			},
+		},
+		{
+			name:     "other operators only keep the boarding tariff",
+			price:    1000,
+			journeys: []Journey{{Legs: []JourneyLeg{{CheckIn: testLegRecord(transactionNameCheckIn, 0, companyNameRET, "Beurs", 4)}}}},
+			want:     []EnrichedRecord{{DeductedAmount: 400, RefundAmount: 400 - basicFare}},
+		},
+		{
+			name:  "an aborted leg uses the amount of the correction",
+			price: 1000,
+			journeys: []Journey{{Legs: []JourneyLeg{{
+				CheckIn:    testLegRecord(transactionNameCheckIn, 0, companyNameRET, "Beurs", 4),
+				CheckOut:   testLegRecord(transactionNameCheckOut, 20, companyNameRET, "Beurs", 0),
+				Correction: testLegRecord(transactionNameAborted, 20, companyNameRET, "Beurs", 2.5),
+			}}}},
+			want: []EnrichedRecord{{DeductedAmount: 250, RefundAmount: 250 - basicFare}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repository := &memoryNSStationsRepository{stations: testNSStations}
+			stationsCodeService := NewNSStationsCodeService(
+				repository,
+				NewNSStationNameResolver(repository, countingVersionsRepository{}),
+				NewLogReviewQueueRepository(t.Logf),
+				discardErrorHandler{},
+				missCache{},
+			)
+			priceFetcher := NewNSPriceFetcher(nil, fixedNSPricesRepository{test.price}, discardErrorHandler{}, missCache{})
+
+			records := NewMissingCheckOutService(stationsCodeService, priceFetcher, discardErrorHandler{}).Detect(test.journeys)
+			if len(records) != len(test.want) {
+				t.Fatalf("Detect() returned %d records, want %d", len(records), len(test.want))
+			}
+
+			for index, record := range records {
+				want := test.want[index]
+				if record.FromStationCode != want.FromStationCode || record.ToStationCode != want.ToStationCode {
+					t.Errorf("record %d is from '%s' to '%s', want from '%s' to '%s'", index, record.FromStationCode, record.ToStationCode, want.FromStationCode, want.ToStationCode)
+				}
+				if record.DeductedAmount != want.DeductedAmount || record.RefundAmount != want.RefundAmount {
+					t.Errorf("record %d deducted %d and refunds %d, want %d and %d", index, record.DeductedAmount, record.RefundAmount, want.DeductedAmount, want.RefundAmount)
+				}
+				if record.TransactionType != transactionTypeMissingCheckOut {
+					t.Errorf("record %d has type %s, want %s", index, record.TransactionType, transactionTypeMissingCheckOut)
+				}
+			}
+		})
+	}
+}
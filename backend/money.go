@@ -22,10 +22,16 @@ func NewEUR(amount int) Money {
 	return NewMoney(currency.EUR, amount)
 }
 
-// Multiply multiplies the money amount by a float and rounds the value up
+// NewEURFromFloat creates a new EURO money from an amount in euros e.g 4.56
+func NewEURFromFloat(amount float64) Money {
+	return NewEUR(int(math.Round(amount * 100)))
+}
+
+// Multiply returns the money amount multiplied by a float e.g 0.6 for a 40% discount. The result is rounded to the
+// nearest base unit.
 func (money Money) Multiply(value float64) Money {
 	newAmount := int(math.Round(float64(money.value) * value))
-	return money.AddAmount(newAmount)
+	return NewMoney(money.Currency(), newAmount)
 }
 
 // AddAmount increments the current money by an amount
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+
+	"golang.org/x/text/currency"
+)
+
+func TestMoneyMultiply(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount int
+		factor float64
+		want   int
+	}{
+		{name: "off-peak discount", amount: 1000, factor: offPeakPriceDiscount, want: 600},
+		{name: "peak discount", amount: 1000, factor: peakPriceDiscount, want: 800},
+		{name: "rounds up", amount: 333, factor: 0.6, want: 200},
+		{name: "rounds down", amount: 334, factor: 0.6, want: 200},
+		{name: "rounds half away from zero", amount: 5, factor: 0.5, want: 3},
+		{name: "zero", amount: 0, factor: 0.6, want: 0},
+		{name: "unchanged", amount: 1234, factor: 1, want: 1234},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := NewEUR(test.amount).Multiply(test.factor)
+			if result.Value() != test.want {
+				t.Errorf("NewEUR(%d).Multiply(%v) = %d, want %d", test.amount, test.factor, result.Value(), test.want)
+			}
+
+			if result.Currency() != currency.EUR {
+				t.Errorf("currency = %s, want EUR", result.Currency())
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoCalculationResultsRepository is responsible for persisting/loading the calculation results of imports
+type MongoCalculationResultsRepository struct {
+	MongodbRepository
+}
+
+// NewMongoCalculationResultsRepository is used to initialize this class
+func NewMongoCalculationResultsRepository(db *mongo.Database, collection string, bsonService BsonService) *MongoCalculationResultsRepository {
+	return &MongoCalculationResultsRepository{MongodbRepository{db, collection, bsonService}}
+}
+
+// Store stores the results of a calculation run
+func (repository *MongoCalculationResultsRepository) Store(results []StoredCalculationResult) (err error) {
+	if len(results) == 0 {
+		return nil
+	}
+
+	var documents []interface{}
+	for _, result := range results {
+		document, err := repository.bsonService.EncodeToBsonM(result)
+		if err != nil {
+			return errors.Wrap(err, "cannot convert calculation result to bson.M")
+		}
+		document[keyCreatedAt] = time.Now().UTC()
+		document[keyUpdatedAt] = time.Now().UTC()
+		documents = append(documents, document)
+	}
+
+	_, err = repository.db.Collection(repository.collection).InsertMany(context.Background(), documents)
+	if err != nil {
+		return errors.Wrap(err, "cannot insert calculation results into the database")
+	}
+
+	return nil
+}
+
+// FetchLatestForTransactionID returns the results of the last calculation run for an import
+func (repository *MongoCalculationResultsRepository) FetchLatestForTransactionID(transactionID TransactionID) (results []StoredCalculationResult, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbOperationTimeout)
+	defer cancel()
+
+	var latest StoredCalculationResult
+	err = repository.db.Collection(repository.collection).FindOne(
+		ctx,
+		bson.M{keyTransactionID: transactionID.String()},
+		options.FindOne().SetSort(bson.M{"calculated_at": -1}),
+	).Decode(&latest)
+	if err == mongo.ErrNoDocuments {
+		return results, ErrNotFound
+	}
+	if err != nil {
+		return results, errors.Wrapf(err, "cannot fetch the latest calculation run for transaction id '%s'", transactionID.String())
+	}
+
+	return repository.find(bson.M{"run_id": latest.RunID.String()})
+}
+
+// FetchHistoryForTransactionID returns the results of all the calculation runs for an import with the newest run first
+func (repository *MongoCalculationResultsRepository) FetchHistoryForTransactionID(transactionID TransactionID) (results []StoredCalculationResult, err error) {
+	return repository.find(bson.M{keyTransactionID: transactionID.String()})
+}
+
+func (repository *MongoCalculationResultsRepository) find(filter bson.M) (results []StoredCalculationResult, err error) {
+	ctx := context.Background()
+	cursor, err := repository.db.Collection(repository.collection).Find(
+		ctx,
+		filter,
+		options.Find().SetSort(bson.D{{Key: "calculated_at", Value: -1}, {Key: "product", Value: 1}}),
+	)
+	if err != nil {
+		return results, errors.Wrap(err, "cannot fetch calculation results")
+	}
+	defer func() { _ = cursor.Close(context.Background()) }()
+
+	for cursor.Next(ctx) {
+		var result StoredCalculationResult
+		err := cursor.Decode(&result)
+		if err != nil {
+			return results, errors.Wrap(err, "cannot decode bson.M to calculation result")
+		}
+		results = append(results, result)
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		return results, errors.Wrap(err, "DB error")
+	}
+
+	return results, nil
+}
@@ -23,6 +23,12 @@ const (
 	collectionNSPrices          = "ns_journey_prices"
 	collectionNSEnrichedRecords = "ns_enriched_records"
 	collectionNationalHolidays  = "national_holidays"
+	collectionGTFSStops         = "gtfs_stops"
+	collectionReviewQueue       = "review_queue"
+	collectionJobs              = "jobs"
+	collectionReferenceData     = "reference_data_versions"
+	collectionImports           = "imports"
+	collectionCalculations      = "calculation_results"
 )
 
 // db keys names
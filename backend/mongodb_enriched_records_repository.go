@@ -61,3 +61,48 @@ func (repository *MongoNSEnrichedRecordsRepository) FetchAllForTransactionID(id
 
 	return enrichedRecords, nil
 }
+
+// DeleteAllForTransactionID deletes the enriched records of an import so that the raw records can be enriched again
+func (repository *MongoNSEnrichedRecordsRepository) DeleteAllForTransactionID(id TransactionID) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbOperationTimeout)
+	defer cancel()
+
+	_, err = repository.db.Collection(repository.collection).DeleteMany(ctx, bson.M{keyTransactionID: id.String()})
+	if err != nil {
+		return errors.Wrapf(err, "cannot delete enriched records for transaction id '%s'", id.String())
+	}
+
+	return nil
+}
+
+// FetchStaleTransactionIDs returns the imports with enriched records which used an older version of the reference data
+func (repository *MongoNSEnrichedRecordsRepository) FetchStaleTransactionIDs(name ReferenceDataName, version int) (transactionIDs []TransactionID, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbOperationTimeout)
+	defer cancel()
+
+	key := "reference_data_versions." + name.String()
+	values, err := repository.db.Collection(repository.collection).Distinct(ctx, keyTransactionID, bson.M{
+		"$or": bson.A{
+			bson.M{key: bson.M{"$lt": version}},
+			bson.M{key: bson.M{"$exists": false}},
+		},
+	})
+	if err != nil {
+		return transactionIDs, errors.Wrapf(err, "cannot fetch imports which are enriched with an old version of %s", name)
+	}
+
+	for _, value := range values {
+		id, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		transactionID, err := NewTransactionIDFromString(id)
+		if err != nil {
+			return transactionIDs, errors.Wrapf(err, "cannot parse transaction id '%s'", id)
+		}
+		transactionIDs = append(transactionIDs, transactionID)
+	}
+
+	return transactionIDs, nil
+}
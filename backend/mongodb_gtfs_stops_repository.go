@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MongoGTFSStopsRepository is responsible for persisting/loading the stops imported from a GTFS feed
+type MongoGTFSStopsRepository struct {
+	MongodbRepository
+}
+
+// NewMongoGTFSStopsRepository is used to initialize this class
+func NewMongoGTFSStopsRepository(db *mongo.Database, collection string, bsonService BsonService) *MongoGTFSStopsRepository {
+	return &MongoGTFSStopsRepository{MongodbRepository{db, collection, bsonService}}
+}
+
+// Store stores a list of GTFS stops to the database
+func (repository *MongoGTFSStopsRepository) Store(stops []GTFSStop) (err error) {
+	if len(stops) == 0 {
+		return nil
+	}
+
+	var documents []interface{}
+	for _, stop := range stops {
+		document, err := repository.bsonService.EncodeToBsonM(stop)
+		if err != nil {
+			return errors.Wrap(err, "cannot convert stop to bson.M")
+		}
+		documents = append(documents, repository.SetTimestampFields(document))
+	}
+
+	_, err = repository.db.Collection(repository.collection).InsertMany(context.Background(), documents)
+	if err != nil {
+		return errors.Wrapf(err, "cannot insert stops into the database")
+	}
+
+	return nil
+}
+
+// DeleteAll removes all the GTFS stops from the database
+func (repository *MongoGTFSStopsRepository) DeleteAll() (err error) {
+	_, err = repository.db.Collection(repository.collection).DeleteMany(context.Background(), bson.M{})
+	if err != nil {
+		return errors.Wrap(err, "cannot delete stops from the database")
+	}
+
+	return nil
+}
+
+// FetchAll returns all the GTFS stops in the database
+func (repository *MongoGTFSStopsRepository) FetchAll() (stops []GTFSStop, err error) {
+	ctx := context.Background()
+	cursor, err := repository.db.Collection(repository.collection).Find(ctx, bson.M{})
+	if err != nil {
+		return stops, errors.Wrap(err, "cannot fetch stops from the database")
+	}
+	defer func() { _ = cursor.Close(context.Background()) }()
+
+	for cursor.Next(ctx) {
+		var stop GTFSStop
+		err := cursor.Decode(&stop)
+		if err != nil {
+			return stops, errors.Wrap(err, "cannot decode bson.M to GTFS stop")
+		}
+		stops = append(stops, stop)
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		return stops, errors.Wrap(err, "DB error")
+	}
+
+	return stops, nil
+}
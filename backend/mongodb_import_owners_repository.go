@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoImportOwnersRepository stores the owner of each import in the database
+type MongoImportOwnersRepository struct {
+	MongodbRepository
+}
+
+// NewMongoImportOwnersRepository is used to initialize this class
+func NewMongoImportOwnersRepository(db *mongo.Database, collection string, bsonService BsonService) *MongoImportOwnersRepository {
+	return &MongoImportOwnersRepository{MongodbRepository{db, collection, bsonService}}
+}
+
+// Store saves the owner of an import. The owner of an import never changes so an existing owner is kept.
+func (repository *MongoImportOwnersRepository) Store(owner ImportOwner) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbOperationTimeout)
+	defer cancel()
+
+	_, err = repository.db.Collection(repository.collection).UpdateOne(
+		ctx,
+		bson.M{keyTransactionID: owner.TransactionID.String()},
+		bson.M{"$setOnInsert": bson.M{
+			keyTransactionID: owner.TransactionID.String(),
+			"user_id":        owner.UserID.String(),
+			keyCreatedAt:     time.Now().UTC(),
+		}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "cannot store the owner of transaction id '%s'", owner.TransactionID.String())
+	}
+
+	return nil
+}
+
+// FetchUserID returns the ID of the user who owns the import
+func (repository *MongoImportOwnersRepository) FetchUserID(transactionID TransactionID) (userID TransactionID, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbOperationTimeout)
+	defer cancel()
+
+	var owner ImportOwner
+	err = repository.db.Collection(repository.collection).FindOne(ctx, bson.M{keyTransactionID: transactionID.String()}).Decode(&owner)
+	if err == mongo.ErrNoDocuments {
+		return userID, ErrNotFound
+	}
+	if err != nil {
+		return userID, errors.Wrapf(err, "cannot fetch the owner of transaction id '%s'", transactionID.String())
+	}
+
+	return owner.UserID, nil
+}
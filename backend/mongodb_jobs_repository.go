@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/jobs"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoJobsRepository is responsible for persisting/loading background jobs
+type MongoJobsRepository struct {
+	MongodbRepository
+}
+
+// NewMongoJobsRepository is used to initialize this class
+func NewMongoJobsRepository(db *mongo.Database, collection string, bsonService BsonService) *MongoJobsRepository {
+	return &MongoJobsRepository{MongodbRepository{db, collection, bsonService}}
+}
+
+// CreateIndexes creates the indexes of the jobs
+func (repository *MongoJobsRepository) CreateIndexes() (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbOperationTimeout)
+	defer cancel()
+
+	return jobs.CreateIndexes(ctx, repository.db.Collection(repository.collection))
+}
+
+// Store saves a job. A job with an idempotency key is stored once per user and a job without a key is not stored
+// when a pending job of the same type already exists for the import.
+func (repository *MongoJobsRepository) Store(job Job) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbOperationTimeout)
+	defer cancel()
+
+	document, err := repository.bsonService.EncodeToBsonM(job)
+	if err != nil {
+		return errors.Wrap(err, "cannot convert job to bson.M")
+	}
+	document[keyCreatedAt] = time.Now().UTC()
+	document[keyUpdatedAt] = time.Now().UTC()
+
+	return jobs.Store(ctx, repository.db.Collection(repository.collection), document)
+}
+
+// Claim locks the oldest job which is ready to run so that no other worker runs it. ErrNotFound is returned when
+// there is no job to run. Jobs whose lock expired because a worker crashed are claimed again.
+func (repository *MongoJobsRepository) Claim(workerID string, lockDuration time.Duration) (job Job, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbOperationTimeout)
+	defer cancel()
+
+	now := time.Now().UTC()
+	err = repository.db.Collection(repository.collection).FindOneAndUpdate(
+		ctx,
+		bson.M{"$or": bson.A{
+			bson.M{"status": jobStatusPending, "run_after": bson.M{"$lte": now}},
+			bson.M{"status": jobStatusRunning, "locked_until": bson.M{"$lt": now}},
+		}},
+		bson.M{
+			"$set": bson.M{
+				"status":       jobStatusRunning,
+				"locked_by":    workerID,
+				"locked_until": now.Add(lockDuration),
+				keyUpdatedAt:   now,
+			},
+			"$inc": bson.M{"attempts": 1},
+		},
+		options.FindOneAndUpdate().SetSort(bson.M{keyCreatedAt: 1}).SetReturnDocument(options.After),
+	).Decode(&job)
+
+	if err == mongo.ErrNoDocuments {
+		return job, ErrNotFound
+	}
+	if err != nil {
+		return job, errors.Wrap(err, "cannot claim a job")
+	}
+
+	return job, nil
+}
+
+// ExtendLock keeps a running job locked for lockDuration from now. ErrNotFound is returned when the job is no longer
+// locked by the worker.
+func (repository *MongoJobsRepository) ExtendLock(jobID TransactionID, workerID string, lockDuration time.Duration) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbOperationTimeout)
+	defer cancel()
+
+	now := time.Now().UTC()
+	result, err := repository.db.Collection(repository.collection).UpdateOne(
+		ctx,
+		bson.M{"id": jobID.String(), "status": jobStatusRunning, "locked_by": workerID},
+		bson.M{"$set": bson.M{"locked_until": now.Add(lockDuration), keyUpdatedAt: now}},
+	)
+	if err != nil {
+		return errors.Wrapf(err, "cannot extend the lock of job with id '%s'", jobID.String())
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// Update saves the status and the progress of a job which is locked by job.LockedBy. The lock of a running job is only
+// changed by Claim and ExtendLock so that saving the progress does not shorten a lock which was extended, the lock is
+// released when the job is no longer running. ErrNotFound is returned when the job is no longer locked by the worker.
+func (repository *MongoJobsRepository) Update(job Job) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbOperationTimeout)
+	defer cancel()
+
+	update := bson.M{
+		"status":                  job.Status,
+		"processed_items":         job.ProcessedItems,
+		"total_items":             job.TotalItems,
+		"max_attempts":            job.MaxAttempts,
+		"run_after":               job.RunAfter,
+		"error":                   job.Error,
+		"reference_data_versions": job.ReferenceDataVersions,
+		keyUpdatedAt:              time.Now().UTC(),
+	}
+	if job.Status != jobStatusRunning {
+		update["locked_by"] = ""
+		update["locked_until"] = time.Time{}
+	}
+
+	result, err := repository.db.Collection(repository.collection).UpdateOne(
+		ctx,
+		bson.M{"id": job.ID.String(), "locked_by": job.LockedBy},
+		bson.M{"$set": update},
+	)
+	if err != nil {
+		return errors.Wrapf(err, "cannot update job with id '%s'", job.ID.String())
+	}
+
+	// another worker claimed the job after the lock expired
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -66,3 +66,29 @@ func (repository *MongoNationalHolidaysRepository) GetByTimestamp(timestamp time
 
 	return holiday, nil
 }
+
+// FetchAll returns all the national holidays
+func (repository *MongoNationalHolidaysRepository) FetchAll() (holidays []Holiday, err error) {
+	ctx := context.Background()
+	cursor, err := repository.db.Collection(repository.collection).Find(ctx, bson.M{})
+	if err != nil {
+		return holidays, errors.Wrap(err, "cannot fetch holidays from the database")
+	}
+	defer func() { _ = cursor.Close(context.Background()) }()
+
+	for cursor.Next(ctx) {
+		var holiday Holiday
+		err := cursor.Decode(&holiday)
+		if err != nil {
+			return holidays, errors.Wrap(err, "cannot decode bson.M to holiday")
+		}
+		holidays = append(holidays, holiday)
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		return holidays, errors.Wrap(err, "DB error")
+	}
+
+	return holidays, nil
+}
@@ -52,3 +52,29 @@ func (repository *MongoNSPricesRepository) GetByHash(hash string) (price NSJourn
 
 	return price, nil
 }
+
+// FetchAll returns all the NS prices which have been fetched from the NS API
+func (repository *MongoNSPricesRepository) FetchAll() (prices []NSJourneyPrice, err error) {
+	ctx := context.Background()
+	cursor, err := repository.db.Collection(repository.collection).Find(ctx, bson.M{})
+	if err != nil {
+		return prices, errors.Wrap(err, "cannot fetch prices from the database")
+	}
+	defer func() { _ = cursor.Close(context.Background()) }()
+
+	for cursor.Next(ctx) {
+		var price NSJourneyPrice
+		err := cursor.Decode(&price)
+		if err != nil {
+			return prices, errors.Wrap(err, "cannot decode bson.M to NS journey price")
+		}
+		prices = append(prices, price)
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		return prices, errors.Wrap(err, "DB error")
+	}
+
+	return prices, nil
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"log"
+	"time"
 
 	"github.com/pkg/errors"
 	"go.mongodb.org/mongo-driver/bson"
@@ -19,21 +20,35 @@ func NewMongoNSStationsRepository(db *mongo.Database, collection string, bsonSer
 	return &MongoNSStationsRepository{MongodbRepository{db, collection, bsonService}}
 }
 
-// Store stores a list of  NS station to the database
+// Store inserts the stations or updates the stations with the same code and name. The aliases of a station have the
+// same code and a different name so they are kept when the stations are synced again.
 func (repository *MongoNSStationsRepository) Store(stations []NSStation) (err error) {
-	var documents []interface{}
-	for _, stations := range stations {
-		document, err := repository.bsonService.EncodeToBsonM(stations.ToLower())
+	var models []mongo.WriteModel
+	for _, station := range stations {
+		document, err := repository.bsonService.EncodeToBsonM(station.ToLower())
 		if err != nil {
 			return errors.Wrap(err, "cannot convert station to bson.M")
 		}
-		document = repository.SetTimestampFields(document)
-		documents = append(documents, document)
+		document = repository.SetUpdatedAtField(document)
+
+		// the ID and the creation time of an existing station don't change
+		onInsert := bson.M{"id": document["id"], keyCreatedAt: time.Now().UTC().String()}
+		delete(document, "id")
+		delete(document, keyCreatedAt)
+
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"code": document["code"], "name": document["name"]}).
+			SetUpdate(bson.M{"$set": document, "$setOnInsert": onInsert}).
+			SetUpsert(true))
+	}
+
+	if len(models) == 0 {
+		return nil
 	}
 
-	_, err = repository.db.Collection(repository.collection).InsertMany(context.Background(), documents)
+	_, err = repository.db.Collection(repository.collection).BulkWrite(context.Background(), models)
 	if err != nil {
-		return errors.Wrapf(err, "cannot insert stations into the database")
+		return errors.Wrapf(err, "cannot store stations in the database")
 	}
 
 	return nil
@@ -74,3 +89,29 @@ func (repository *MongoNSStationsRepository) GetByCode(code string) (station NSS
 
 	return station, nil
 }
+
+// FetchAll returns all the NS stations including the synonyms
+func (repository *MongoNSStationsRepository) FetchAll() (stations []NSStation, err error) {
+	ctx := context.Background()
+	cursor, err := repository.db.Collection(repository.collection).Find(ctx, bson.M{})
+	if err != nil {
+		return stations, errors.Wrap(err, "cannot fetch stations from the database")
+	}
+	defer func() { _ = cursor.Close(context.Background()) }()
+
+	for cursor.Next(ctx) {
+		var station NSStation
+		err := cursor.Decode(&station)
+		if err != nil {
+			return stations, errors.Wrap(err, "cannot decode bson.M to NS station")
+		}
+		stations = append(stations, station)
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		return stations, errors.Wrap(err, "DB error")
+	}
+
+	return stations, nil
+}
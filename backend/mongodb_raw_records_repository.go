@@ -81,3 +81,16 @@ func (repository *MongodbRawRecordsRepository) GetByTransactionID(getOptions Get
 
 	return rawRecords, nil
 }
+
+// DeleteAllForTransactionID deletes the raw records of an import so that a failed import can be fetched again
+func (repository *MongodbRawRecordsRepository) DeleteAllForTransactionID(id TransactionID) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbOperationTimeout)
+	defer cancel()
+
+	_, err = repository.db.Collection(repository.collection).DeleteMany(ctx, bson.M{keyTransactionID: id.String()})
+	if err != nil {
+		return errors.Wrapf(err, "cannot delete raw records for transaction id '%s'", id.String())
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoReferenceDataVersionsRepository keeps track of the versions of the reference data in the database
+type MongoReferenceDataVersionsRepository struct {
+	MongodbRepository
+}
+
+// NewMongoReferenceDataVersionsRepository is used to initialize this class
+func NewMongoReferenceDataVersionsRepository(db *mongo.Database, collection string, bsonService BsonService) *MongoReferenceDataVersionsRepository {
+	return &MongoReferenceDataVersionsRepository{MongodbRepository{db, collection, bsonService}}
+}
+
+// Increment increases the version of a reference data set and returns the new version
+func (repository *MongoReferenceDataVersionsRepository) Increment(name ReferenceDataName) (version int, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbOperationTimeout)
+	defer cancel()
+
+	var document struct {
+		Version int `bson:"version"`
+	}
+
+	err = repository.db.Collection(repository.collection).FindOneAndUpdate(
+		ctx,
+		bson.M{"name": name},
+		bson.M{"$inc": bson.M{"version": 1}, "$set": bson.M{keyUpdatedAt: time.Now().UTC()}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&document)
+	if err != nil {
+		return version, errors.Wrapf(err, "cannot increment the version of %s", name)
+	}
+
+	return document.Version, nil
+}
+
+// FetchAll returns the current version of every reference data set
+func (repository *MongoReferenceDataVersionsRepository) FetchAll() (versions ReferenceDataVersions, err error) {
+	ctx := context.Background()
+	cursor, err := repository.db.Collection(repository.collection).Find(ctx, bson.M{})
+	if err != nil {
+		return versions, errors.Wrap(err, "cannot fetch reference data versions")
+	}
+	defer func() { _ = cursor.Close(context.Background()) }()
+
+	versions = ReferenceDataVersions{}
+	for cursor.Next(ctx) {
+		var document struct {
+			Name    string `bson:"name"`
+			Version int    `bson:"version"`
+		}
+		err := cursor.Decode(&document)
+		if err != nil {
+			return versions, errors.Wrap(err, "cannot decode reference data version")
+		}
+		versions[document.Name] = document.Version
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		return versions, errors.Wrap(err, "DB error")
+	}
+
+	return versions, nil
+}
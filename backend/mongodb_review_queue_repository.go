@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoReviewQueueRepository is responsible for persisting/loading the items which need to be reviewed by an admin
+type MongoReviewQueueRepository struct {
+	MongodbRepository
+}
+
+// NewMongoReviewQueueRepository is used to initialize this class
+func NewMongoReviewQueueRepository(db *mongo.Database, collection string, bsonService BsonService) *MongoReviewQueueRepository {
+	return &MongoReviewQueueRepository{MongodbRepository{db, collection, bsonService}}
+}
+
+// Add stores a review queue item. The same text is only stored once for each type so we count the occurrences instead.
+func (repository *MongoReviewQueueRepository) Add(item ReviewQueueItem) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbOperationTimeout)
+	defer cancel()
+
+	now := time.Now().UTC()
+	_, err = repository.db.Collection(repository.collection).UpdateOne(
+		ctx,
+		bson.M{"type": item.Type, "text": item.Text},
+		bson.M{
+			"$setOnInsert": bson.M{
+				"id":         item.ID.String(),
+				"status":     item.Status,
+				keyCreatedAt: now,
+			},
+			"$set": bson.M{
+				"suggestions": item.Suggestions,
+				keyUpdatedAt:  now,
+			},
+			"$inc": bson.M{"occurrences": 1},
+		},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "cannot add '%s' to the review queue", item.Text)
+	}
+
+	return nil
+}
+
+// ReplaceFailedEnrichments removes the failed enrichments of an import which have not been reviewed and stores the new ones
+func (repository *MongoReviewQueueRepository) ReplaceFailedEnrichments(transactionID TransactionID, items []ReviewQueueItem) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbOperationTimeout)
+	defer cancel()
+
+	_, err = repository.db.Collection(repository.collection).DeleteMany(ctx, bson.M{
+		"type":           reviewQueueItemTypeFailedEnrichment,
+		"status":         reviewQueueItemStatusOpen,
+		keyTransactionID: transactionID.String(),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "cannot delete failed enrichments for transaction id '%s'", transactionID.String())
+	}
+
+	if len(items) == 0 {
+		return nil
+	}
+
+	var documents []interface{}
+	for _, item := range items {
+		document, err := repository.bsonService.EncodeToBsonM(item)
+		if err != nil {
+			return errors.Wrap(err, "cannot convert review queue item to bson.M")
+		}
+		document[keyCreatedAt] = time.Now().UTC()
+		document[keyUpdatedAt] = time.Now().UTC()
+		documents = append(documents, document)
+	}
+
+	_, err = repository.db.Collection(repository.collection).InsertMany(ctx, documents)
+	if err != nil {
+		return errors.Wrapf(err, "cannot insert failed enrichments for transaction id '%s'", transactionID.String())
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// NS discount products
+const (
+	productNSNoDiscount     = "NS No Discount"
+	productNSDalVoordeel    = "NS Dal Voordeel"
+	productNSAltijdVoordeel = "NS Altijd Voordeel"
+	productNSDalVrij        = "NS Dal Vrij"
+)
+
+// NSOperator is the operator for journeys with the NS
+type NSOperator struct {
+	stationsCodeService NSStationsCodeService
+	priceFetcher        NSPriceFetcherService
+	offPeakService      NSOffPeakService
+}
+
+// NewNSOperator creates a new instance of the NSOperator
+func NewNSOperator(stationsCodeService NSStationsCodeService, priceFetcher NSPriceFetcherService, offPeakService NSOffPeakService) NSOperator {
+	return NSOperator{stationsCodeService, priceFetcher, offPeakService}
+}
+
+// CompanyName returns the name of the operator
+func (operator NSOperator) CompanyName() CompanyName {
+	return companyNameNS
+}
+
+// OwnsRecord determines if the raw record is an NS train journey
+func (operator NSOperator) OwnsRecord(record RawRecord) bool {
+	return record.IsNS()
+}
+
+// ResolveStop returns the NS station for a station name
+func (operator NSOperator) ResolveStop(name string) (stop Stop, err error) {
+	station, err := operator.stationsCodeService.GetCodeForStationName(name)
+	if err != nil {
+		return stop, err
+	}
+	return station.ToStop(), nil
+}
+
+// EnrichLeg enriches an NS leg. The check-out of an NS leg contains the names of both stations. When the check-in is
+// not in the records, the start time is estimated from the price of the journey.
+func (operator NSOperator) EnrichLeg(leg JourneyLeg) (enrichedRecord EnrichedRecord, errorRecord ErrorRawRecord) {
+	record := *leg.CheckOut
+
+	var startTime int64
+	var startTimeIsExact = false
+	if leg.HasCheckIn() && leg.CheckIn.IsCheckIn() && record.IsNS() && leg.CheckIn.TransactionInfo == record.CheckInInfo {
+		startTime = leg.CheckIn.TransactionDateTime.ToInt64()
+		startTimeIsExact = true
+	}
+
+	fromStation, err := operator.stationsCodeService.GetCodeForStationName(record.CheckInInfo)
+	if err != nil {
+		return enrichedRecord, ErrorRawRecord{
+			Record:      record,
+			Error:       errors.Wrapf(err, "cannot get code for station: %s", record.CheckInInfo),
+			StationName: operator.unresolvedStationName(err, record.CheckInInfo),
+		}
+	}
+
+	toStation, err := operator.stationsCodeService.GetCodeForStationName(record.TransactionInfo)
+	if err != nil {
+		return enrichedRecord, ErrorRawRecord{
+			Record:      record,
+			Error:       errors.Wrapf(err, "cannot get code for station: %s", record.TransactionInfo),
+			StationName: operator.unresolvedStationName(err, record.TransactionInfo),
+		}
+	}
+
+	journey := NewNSJourney(record.TransactionDateTime.ToTime(), fromStation.Code, toStation.Code)
+	if !startTimeIsExact {
+		price, err := operator.priceFetcher.FetchPrice(journey)
+		if err != nil {
+			return enrichedRecord, ErrorRawRecord{
+				Record: record,
+				Error:  errors.Wrap(err, "cannot fetch price for journey"),
+			}
+		}
+		startTime = record.TransactionDateTime.ToInt64() - int64(price.EstimatedDurationInMilliSeconds())
+	}
+
+	enrichedRecordID := NewTransactionID()
+	return EnrichedRecord{
+		FromStationCode:  journey.FromStationCode,
+		ToStationCode:    journey.ToStationCode,
+		Duration:         time.Millisecond * time.Duration(record.TransactionDateTime.ToInt64()-startTime),
+		RawRecordID:      record.ID,
+		TransactionID:    record.TransactionID,
+		ID:               &enrichedRecordID,
+		StartTime:        TimeInMilliSeconds(startTime),
+		StartTimeIsExact: startTimeIsExact,
+		CompanyName:      companyNameNS,
+		TransactionType:  transactionTypeTravel,
+	}, errorRecord
+}
+
+// unresolvedStationName returns the station name if the error was caused by a name which cannot be resolved
+func (operator NSOperator) unresolvedStationName(err error, name string) string {
+	if errors.Cause(err) != ErrorInvalidStationName {
+		return ""
+	}
+	return name
+}
+
+// PriceJourney returns the second class single fare price of an NS journey
+func (operator NSOperator) PriceJourney(record EnrichedRecord) (price Money, err error) {
+	journeyPrice, err := operator.priceFetcher.FetchPrice(record.NSJourney())
+	if err != nil {
+		return price, errors.Wrap(err, "cannot fetch price for record")
+	}
+	return NewEUR(journeyPrice.SecondClassSingleFarePrice), nil
+}
+
+// FareTableVersion returns the version of the NS prices which are fetched from the NS API
+func (operator NSOperator) FareTableVersion(versions ReferenceDataVersions) string {
+	return fmt.Sprintf("%s-v%d", referenceDataNSPrices, versions.Get(referenceDataNSPrices))
+}
+
+// DiscountProducts returns the NS discount products
+func (operator NSOperator) DiscountProducts() []DiscountProduct {
+	return []DiscountProduct{
+		calculatorProduct{productNSNoDiscount, func(records []EnrichedRecord) CalculationResult {
+			result := NewNSNoDiscountCalculator(operator.priceFetcher, operator.offPeakService).Calculate(records)
+			return operator.calculationResult(result.PeakJourneyCount+result.OffPeakJourneyCount, result.PeakSecondClassPrice, result.OffPeakSecondClassPrice, result.SupplementPrice(), NewEUR(0), result)
+		}},
+		calculatorProduct{productNSDalVoordeel, func(records []EnrichedRecord) CalculationResult {
+			result := NewNSDalVoordeelCalculator(operator.priceFetcher, operator.offPeakService).Calculate(records)
+			return operator.calculationResult(result.PeakJourneyCount+result.OffPeakJourneyCount, result.PeakSecondClassPrice, result.OffPeakSecondClassPrice, result.SupplementPrice(), result.SubscriptionPrice, result)
+		}},
+		calculatorProduct{productNSAltijdVoordeel, func(records []EnrichedRecord) CalculationResult {
+			result := NewNSAltijdVoordeelCalculator(operator.priceFetcher, operator.offPeakService).Calculate(records)
+			return operator.calculationResult(result.PeakJourneyCount+result.OffPeakJourneyCount, result.PeakSecondClassPrice, result.OffPeakSecondClassPrice, result.SupplementPrice(), result.SubscriptionPrice, result)
+		}},
+		calculatorProduct{productNSDalVrij, func(records []EnrichedRecord) CalculationResult {
+			result := NewNSDalVrijCalculator(operator.priceFetcher, operator.offPeakService).Calculate(records)
+			return operator.calculationResult(result.PeakJourneyCount+result.OffPeakJourneyCount, result.PeakSecondClassPrice, result.OffPeakSecondClassPrice, result.SupplementPrice(), result.SubscriptionPrice, result)
+		}},
+	}
+}
+
+func (operator NSOperator) calculationResult(journeyCount int, peakPrice, offPeakPrice, supplementPrice, subscriptionPrice Money, details interface{}) CalculationResult {
+	return CalculationResult{
+		CompanyName:       companyNameNS,
+		JourneyCount:      journeyCount,
+		JourneysPrice:     peakPrice.AddAmount(offPeakPrice.Value()).AddAmount(supplementPrice.Value()),
+		SubscriptionPrice: subscriptionPrice,
+		Details:           details,
+	}
+}
@@ -1,27 +1,38 @@
 package main
 
 import (
-	"log"
 	"time"
 
-	"github.com/davecgh/go-spew/spew"
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/metrics"
 	"github.com/pkg/errors"
 	"go.uber.org/ratelimit"
 )
 
-// NSRawRecordsEnrichmentService enriches NS records
+// NSRawRecordsEnrichmentService reconstructs the journeys in the raw records and enriches every leg with the operator
+// which owns the check-out. The operators are taken from the registry so adding an operator does not change this service.
 type NSRawRecordsEnrichmentService struct {
-	stationsCodeService NSStationsCodeService
-	priceFetcher        NSPriceFetcherService
+	journeyService   JourneyReconstructionService
+	missingCheckOuts MissingCheckOutService
+	operators        *OperatorRegistry
 }
 
 // NewNSRawRecordsEnrichmentService creates a new instance of the NSRawRecordsEnrichmentService
-func NewNSRawRecordsEnrichmentService(stationsCodeService NSStationsCodeService, priceFetcher NSPriceFetcherService) NSRawRecordsEnrichmentService {
-	return NSRawRecordsEnrichmentService{stationsCodeService, priceFetcher}
+func NewNSRawRecordsEnrichmentService(
+	journeyService JourneyReconstructionService,
+	missingCheckOuts MissingCheckOutService,
+	operators *OperatorRegistry,
+) NSRawRecordsEnrichmentService {
+	return NSRawRecordsEnrichmentService{journeyService, missingCheckOuts, operators}
 }
 
-// Enrich goes over all the raw records and enriches NS specific records.
+// Enrich reconstructs the journeys from the raw records and enriches the legs of each journey.
+// Legs where the traveller did not check out are flagged so that the deducted amount can be reclaimed.
 func (service NSRawRecordsEnrichmentService) Enrich(records []RawRecord) (results RawRecordsEnrichmentResults) {
+	start := time.Now()
+	defer func() {
+		metrics.ObserveEnrichment(len(results.ValidRecords), len(results.Error.ErrorRecords), time.Since(start))
+	}()
+
 	var (
 		enrichedRecords  []EnrichedRecord
 		enrichmentErrors RawRecordsEnrichmentError
@@ -34,137 +45,137 @@ func (service NSRawRecordsEnrichmentService) Enrich(records []RawRecord) (result
 		}
 	}
 
-	var prev RawRecord
+	journeys := service.journeyService.Reconstruct(service.withoutIgnoredRecords(records))
 
 	rateLimiter := ratelimit.New(5)
-	for _, record := range records {
-		rateLimiter.Take()
-		rawRecordID := record.ID
-		transactionID := record.TransactionID
-		enrichedRecordID := NewTransactionID()
-
-		idString := rawRecordID.String()
-		log.Println("Processing ID: ", idString)
-
-		// Check if record is check-in record Record
-		if record.IsCheckIn() {
-			prev = record
-			continue
-		}
-
-		// We're not setting prev = current here because you normally have to check in before taking the inter-city
-		// supplement we don't want to lose that record.
-		if record.IsNSSupplement() {
-			enrichedRecords = append(enrichedRecords, EnrichedRecord{
-				RawRecordID:      rawRecordID,
-				TransactionID:    transactionID,
-				ID:               &enrichedRecordID,
-				StartTime:        record.TransactionDateTime,
-				EndTime:          record.TransactionDateTime,
-				StartTimeIsExact: true,
-				CompanyName:      companyNameNS,
-				TransactionType:  transactionTypeSupplement,
-			})
-			continue
-		}
+	for _, journey := range journeys {
+		for _, leg := range journey.Legs {
+			rateLimiter.Take()
+
+			// You normally have to check in before taking the inter-city supplement so the supplement is part of the leg.
+			for _, supplement := range leg.Supplements {
+				enrichedRecordID := NewTransactionID()
+				enrichedRecords = append(enrichedRecords, EnrichedRecord{
+					RawRecordID:      supplement.ID,
+					TransactionID:    supplement.TransactionID,
+					ID:               &enrichedRecordID,
+					StartTime:        supplement.TransactionDateTime,
+					EndTime:          supplement.TransactionDateTime,
+					StartTimeIsExact: true,
+					CompanyName:      companyNameNS,
+					TransactionType:  transactionTypeSupplement,
+					JourneyID:        journey.ID,
+					RawRecordIDs:     leg.RawRecordIDs(),
+				})
+			}
 
-		// Record belongs to the RET company and thus it's not an NS record.
-		if record.IsRET() {
-			prev = record
-			continue
-		}
+			// We can only calculate the price of a leg when we know where the traveller checked out
+			if !leg.HasCheckOut() {
+				continue
+			}
 
-		// Record is a checkout record meaning we can calculate the price
-		if record.IsCheckOut() {
-			if record.IsNS() {
-				log.Println("if start")
-				enrichedRecord, errorRecord := service.getEnrichedNsRecord(prev, record, rawRecordID, transactionID, &enrichedRecordID)
-				log.Println("Finished enrich")
-				if errorRecord.Error != nil {
-					spew.Dump(errorRecord.Error)
-					enrichmentErrors.ErrorRecords = append(enrichmentErrors.ErrorRecords, errorRecord)
-				} else {
-					enrichedRecords = append(enrichedRecords, enrichedRecord)
-				}
-			} else {
-				// If we're here we know that the record is a check-out record but we don't know the company to which it belongs.
-				// We'll check if the journey can be an NS journey and if that's the case, we'll enrich it.
-				// If the journey is not a valid NSJourney we don't bother about it.
-				log.Println("else start")
-				enrichedRecord, errorRecord := service.getEnrichedNsRecord(prev, record, rawRecordID, transactionID, &enrichedRecordID)
-				log.Println("else returne")
-				if errorRecord.Error == nil {
+			operator, ok := service.operators.OperatorForRecord(*leg.CheckOut)
+			if !ok {
+				// We don't know the company to which the check-out belongs. The leg is kept when an operator can enrich it,
+				// if no operator can we don't bother about it.
+				if enrichedRecord, ok := service.enrichUnknownLeg(journey, leg); ok {
 					enrichedRecords = append(enrichedRecords, enrichedRecord)
 				}
+				continue
 			}
-		}
 
-		// Record is not check-in/check-out or supplement.
-		// It may be something else like adding money into your ov-chipkaart
-		prev = record
+			enrichedRecord, errorRecord := service.enrichLeg(operator, journey, leg)
+			if errorRecord.Error != nil {
+				enrichmentErrors.ErrorRecords = append(enrichmentErrors.ErrorRecords, errorRecord)
+				continue
+			}
+			enrichedRecords = append(enrichedRecords, enrichedRecord)
+		}
 	}
 
-	log.Println(prev)
+	enrichedRecords = append(enrichedRecords, service.missingCheckOuts.Detect(journeys)...)
+
 	return RawRecordsEnrichmentResults{
 		ValidRecords: enrichedRecords,
 		Error:        enrichmentErrors,
 	}
 }
 
-func (service NSRawRecordsEnrichmentService) getEnrichedNsRecord(prev, record RawRecord, rawRecordID, transactionID, newTransactionID *TransactionID) (enrichedRecord EnrichedRecord, errorRecord ErrorRawRecord) {
-	var startTime int64
-	var startTimeIsExact = false
-	if prev.IsCheckIn() && record.IsNS() && prev.TransactionInfo == record.CheckInInfo {
-		startTime = prev.TransactionDateTime.ToInt64()
-		startTimeIsExact = true
+// enrichLeg enriches a leg with an operator. The stops of the check-in and the check-out are resolved unless the operator
+// enriches its legs itself.
+func (service NSRawRecordsEnrichmentService) enrichLeg(operator Operator, journey Journey, leg JourneyLeg) (enrichedRecord EnrichedRecord, errorRecord ErrorRawRecord) {
+	if enricher, ok := operator.(LegEnricher); ok {
+		enrichedRecord, errorRecord = enricher.EnrichLeg(leg)
 	} else {
-		startTimeIsExact = false
+		enrichedRecord, errorRecord = service.enrichLegWithStops(operator, leg)
 	}
 
-	spew.Dump(record)
+	if errorRecord.Error != nil {
+		return enrichedRecord, errorRecord
+	}
 
-	log.Printf("Fetching from station name for %s ", record.CheckInInfo)
-	fromStation, err := service.stationsCodeService.GetCodeForStationName(record.CheckInInfo)
-	if err != nil {
-		log.Println("error returned")
-		return enrichedRecord, ErrorRawRecord{
-			Record: record,
-			Error:  errors.Wrapf(err, "cannot get code for station: %s", record.CheckInInfo),
+	enrichedRecord.JourneyID = journey.ID
+	enrichedRecord.RawRecordIDs = leg.RawRecordIDs()
+	return enrichedRecord, errorRecord
+}
+
+// enrichUnknownLeg tries the operators which enrich their legs themselves in the order in which they were registered
+func (service NSRawRecordsEnrichmentService) enrichUnknownLeg(journey Journey, leg JourneyLeg) (enrichedRecord EnrichedRecord, ok bool) {
+	for _, operator := range service.operators.Operators() {
+		if _, isEnricher := operator.(LegEnricher); !isEnricher {
+			continue
+		}
+
+		enrichedRecord, errorRecord := service.enrichLeg(operator, journey, leg)
+		if errorRecord.Error == nil {
+			return enrichedRecord, true
 		}
 	}
+	return enrichedRecord, false
+}
+
+func (service NSRawRecordsEnrichmentService) enrichLegWithStops(operator Operator, leg JourneyLeg) (enrichedRecord EnrichedRecord, errorRecord ErrorRawRecord) {
+	record := *leg.CheckOut
 
-	log.Printf("Fetching to station name for %s ", record.TransactionInfo)
-	toStation, err := service.stationsCodeService.GetCodeForStationName(record.TransactionInfo)
+	fromStop, err := operator.ResolveStop(leg.CheckInStationName())
 	if err != nil {
 		return enrichedRecord, ErrorRawRecord{
 			Record: record,
-			Error:  errors.Wrapf(err, "cannot get code for station: %s", record.TransactionInfo),
+			Error:  errors.Wrapf(err, "cannot get %s stop for name: %s", operator.CompanyName(), leg.CheckInStationName()),
 		}
 	}
 
-	journey := NewNSJourney(record.TransactionDateTime.ToTime(), fromStation.Code, toStation.Code)
-	spew.Dump(journey)
-	if !startTimeIsExact {
-		price, err := service.priceFetcher.FetchPrice(journey)
-		if err != nil {
-			return enrichedRecord, ErrorRawRecord{
-				Record: record,
-				Error:  errors.Wrap(err, "cannot fetch price for journey"),
-			}
+	toStop, err := operator.ResolveStop(record.TransactionInfo)
+	if err != nil {
+		return enrichedRecord, ErrorRawRecord{
+			Record: record,
+			Error:  errors.Wrapf(err, "cannot get %s stop for name: %s", operator.CompanyName(), record.TransactionInfo),
 		}
-		startTime = record.TransactionDateTime.ToInt64() - int64(price.EstimatedDurationInMilliSeconds())
 	}
 
+	enrichedRecordID := NewTransactionID()
 	return EnrichedRecord{
-		FromStationCode:  journey.FromStationCode,
-		ToStationCode:    journey.ToStationCode,
-		Duration:         time.Millisecond * time.Duration(record.TransactionDateTime.ToInt64()-startTime),
-		RawRecordID:      rawRecordID,
-		TransactionID:    transactionID,
-		ID:               newTransactionID,
-		StartTime:        TimeInMilliSeconds(startTime),
-		StartTimeIsExact: startTimeIsExact,
-		CompanyName:      companyNameNS,
+		RawRecordID:      record.ID,
+		TransactionID:    record.TransactionID,
+		ID:               &enrichedRecordID,
+		StartTime:        leg.StartTime(),
+		EndTime:          leg.EndTime(),
+		StartTimeIsExact: leg.HasCheckIn(),
+		FromStationCode:  fromStop.Code,
+		ToStationCode:    toStop.Code,
+		CompanyName:      operator.CompanyName(),
 		TransactionType:  transactionTypeTravel,
+		Duration:         time.Millisecond * time.Duration(leg.EndTime().ToInt64()-leg.StartTime().ToInt64()),
+		Distance:         fromStop.DistanceTo(toStop),
 	}, errorRecord
 }
+
+// withoutIgnoredRecords removes the records which an admin has marked as ignored
+func (service NSRawRecordsEnrichmentService) withoutIgnoredRecords(records []RawRecord) (result []RawRecord) {
+	for _, record := range records {
+		if !record.IsIgnored {
+			result = append(result, record)
+		}
+	}
+	return result
+}
@@ -17,19 +17,29 @@ var (
 type NSStationsCodeService struct {
 	cache        LFUCache
 	repository   NSStationsRepository
+	nameResolver NSStationNameResolver
+	reviewQueue  ReviewQueueRepository
 	errorHandler ErrorHandler
 }
 
 // NewNSStationsCodeService is a service for fetching the station code based on a station name
-func NewNSStationsCodeService(repository NSStationsRepository, errorHandler ErrorHandler, cache LFUCache) NSStationsCodeService {
-	return NSStationsCodeService{cache, repository, errorHandler}
+func NewNSStationsCodeService(
+	repository NSStationsRepository,
+	nameResolver NSStationNameResolver,
+	reviewQueue ReviewQueueRepository,
+	errorHandler ErrorHandler,
+	cache LFUCache,
+) NSStationsCodeService {
+	return NSStationsCodeService{cache, repository, nameResolver, reviewQueue, errorHandler}
 }
 
 // GetCodeForStationName gets the station code for a corresponding station name.
-// It's fault tolerant if you pass the station code instead of the station name it won't error
+// It's fault tolerant if you pass the station code instead of the station name it won't error.
+// Names which don't exist in the database are resolved by the NSStationNameResolver and the names which cannot be
+// resolved are added to the review queue.
 func (service *NSStationsCodeService) GetCodeForStationName(stationName string) (nsStation NSStation, error error) {
 	// converting string to lowercase for consistency
-	stationName = strings.ToLower(stationName)
+	stationName = strings.ToLower(strings.TrimSpace(stationName))
 
 	// Search the cache for the code
 	val, err := service.cache.Get(stationName)
@@ -39,33 +49,71 @@ func (service *NSStationsCodeService) GetCodeForStationName(stationName string)
 
 	log.Println("station name cache miss")
 
-	// Search the database for the code
-	nsStation, err = service.repository.GetByName(stationName)
+	nsStation, err = service.fetchStation(stationName)
 	if err != nil {
-		// stationName does not exist find by code instead
-		nsStation, err = service.repository.GetByCode(stationName)
-		if err != nil {
-			return nsStation, ErrorInvalidStationName
-		}
+		return nsStation, err
+	}
 
-		log.Println("Finished fetching by code")
+	// the stationName code exists so update the cache
+	err = service.cache.Set(stationName, nsStation)
+	if err != nil {
+		// log this error for debugging
+		service.errorHandler.HandleSoftError(err)
+	}
 
+	return nsStation, nil
+}
+
+func (service *NSStationsCodeService) fetchStation(stationName string) (nsStation NSStation, err error) {
+	// Search the database for the code
+	nsStation, err = service.repository.GetByName(stationName)
+	if err == nil {
+		return nsStation, nil
+	}
+
+	// stationName does not exist find by code instead
+	nsStation, err = service.repository.GetByCode(stationName)
+	if err == nil {
 		// log this error for debugging
 		service.errorHandler.HandleSoftError(
 			errors.New(fmt.Sprintf("GetCodeForStationName() called with short code '%s' instead of stationName name", stationName)),
 		)
+		return nsStation, nil
 	}
 
-	log.Println("finished fetching name")
-	// the stationName code exists so update the cache
-	err = service.cache.Set(nsStation.Name, nsStation)
-	log.Println("finished setting cache", err)
+	match, candidates, err := service.nameResolver.Resolve(stationName)
+	if err == ErrorInvalidStationName {
+		service.addToReviewQueue(stationName, candidates)
+		return nsStation, ErrorInvalidStationName
+	}
 	if err != nil {
-		// log this error for debugging
-		service.errorHandler.HandleSoftError(err)
+		service.errorHandler.HandleSoftError(errors.Wrapf(err, "cannot resolve station name '%s'", stationName))
+		return nsStation, ErrorInvalidStationName
+	}
+
+	log.Printf("station name '%s' resolved to '%s' with confidence %.2f", stationName, match.Station.Code, match.Confidence)
+	return match.Station, nil
+}
+
+// addToReviewQueue stores the unresolved station name so that an admin can map it to a station
+func (service *NSStationsCodeService) addToReviewQueue(stationName string, candidates []NSStationMatch) {
+	item := ReviewQueueItem{
+		ID:     NewTransactionID(),
+		Type:   reviewQueueItemTypeUnresolvedStationName,
+		Text:   stationName,
+		Status: reviewQueueItemStatusOpen,
 	}
 
-	log.Println("returning")
+	for _, candidate := range candidates {
+		item.Suggestions = append(item.Suggestions, ReviewQueueSuggestion{
+			Code:       candidate.Station.Code,
+			Name:       candidate.Station.CurrentName,
+			Confidence: candidate.Confidence,
+		})
+	}
 
-	return nsStation, nil
+	err := service.reviewQueue.Add(item)
+	if err != nil {
+		service.errorHandler.HandleSoftError(errors.Wrapf(err, "cannot add unresolved station name '%s' to the review queue", stationName))
+	}
 }
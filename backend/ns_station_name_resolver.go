@@ -0,0 +1,282 @@
+package main
+
+import (
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// The minimum confidence of a fuzzy match before we use the station
+const stationNameMinConfidence = 0.8
+
+// Two candidates whose confidence is closer than this margin are ambiguous
+const stationNameAmbiguityMargin = 0.02
+
+// The number of suggestions which are stored in the review queue for an unresolved station name
+const stationNameMaxSuggestions = 3
+
+// How often the resolver checks if the stations were synced since the index of station names was built
+const stationNameIndexCheckInterval = time.Minute
+
+// The index of station names is rebuilt after this duration so the aliases which are added in the API are also used
+const stationNameIndexMaxAge = 15 * time.Minute
+
+// Abbreviations which are used in the ov-chipkaart transactions
+var stationNameAbbreviations = map[string]string{
+	"c":       "centraal",
+	"cs":      "centraal",
+	"centr":   "centraal",
+	"hs":      "holland spoor",
+	"hbf":     "hauptbahnhof",
+	"bhf":     "bahnhof",
+	"str":     "straat",
+	"zd":      "zuid",
+	"nrd":     "noord",
+	"aansl":   "aansluiting",
+	"s":       "",
+	"station": "",
+}
+
+// NSStationNameResolver finds the station for the names used in ov-chipkaart transactions.
+// Names are normalised so "'s-Hertogenbosch", "s Hertogenbosch" and "'S HERTOGENBOSCH" are the same.
+// When no name matches exactly the stations are ranked by how similar their names are.
+// The index of names is rebuilt when the version of the NS stations changes after a sync.
+type NSStationNameResolver struct {
+	repository         NSStationsRepository
+	versionsRepository ReferenceDataVersionsRepository
+	state              *nsStationNameResolverState
+}
+
+type nsStationNameResolverState struct {
+	mutex     sync.Mutex
+	now       func() time.Time
+	index     *nsStationNameIndex
+	checkedAt time.Time
+}
+
+// nsStationNameIndex is not changed after it is built so it can be used without holding the lock
+type nsStationNameIndex struct {
+	stations map[string]NSStation
+	names    []string
+	version  int
+	builtAt  time.Time
+}
+
+// NewNSStationNameResolver creates a new instance of the NSStationNameResolver
+func NewNSStationNameResolver(repository NSStationsRepository, versionsRepository ReferenceDataVersionsRepository) NSStationNameResolver {
+	return NSStationNameResolver{repository, versionsRepository, &nsStationNameResolverState{now: time.Now}}
+}
+
+// Resolve returns the best matching station for a name. ErrorInvalidStationName is returned together with the closest
+// matches when we are not confident enough about the best match.
+func (resolver NSStationNameResolver) Resolve(name string) (match NSStationMatch, candidates []NSStationMatch, err error) {
+	index, err := resolver.load()
+	if err != nil {
+		return match, candidates, err
+	}
+
+	normalizedName := normalizeStationName(name)
+	if normalizedName == "" {
+		return match, candidates, ErrorInvalidStationName
+	}
+
+	if station, ok := index.stations[normalizedName]; ok {
+		return NSStationMatch{Station: station, Confidence: 1}, candidates, nil
+	}
+
+	candidates = index.rank(normalizedName)
+	if len(candidates) == 0 || candidates[0].Confidence < stationNameMinConfidence {
+		return match, candidates, ErrorInvalidStationName
+	}
+
+	if len(candidates) > 1 && candidates[0].Confidence-candidates[1].Confidence < stationNameAmbiguityMargin {
+		return match, candidates, ErrorInvalidStationName
+	}
+
+	return candidates[0], candidates, nil
+}
+
+// rank returns the closest station for every station code sorted by the confidence
+func (index *nsStationNameIndex) rank(normalizedName string) (matches []NSStationMatch) {
+	bestMatches := map[string]NSStationMatch{}
+	for _, name := range index.names {
+		confidence := stationNameSimilarity(normalizedName, name)
+		station := index.stations[name]
+
+		if best, ok := bestMatches[station.Code]; !ok || confidence > best.Confidence {
+			bestMatches[station.Code] = NSStationMatch{Station: station, Confidence: confidence}
+		}
+	}
+
+	for _, match := range bestMatches {
+		matches = append(matches, match)
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Confidence == matches[j].Confidence {
+			return matches[i].Station.Code < matches[j].Station.Code
+		}
+		return matches[i].Confidence > matches[j].Confidence
+	})
+
+	if len(matches) > stationNameMaxSuggestions {
+		matches = matches[:stationNameMaxSuggestions]
+	}
+
+	return matches
+}
+
+// load returns the index of normalised station names. The index is built the first time it's needed and it is rebuilt
+// when the stations were synced or when it is older than stationNameIndexMaxAge. The previous index is used when the
+// stations cannot be loaded again.
+func (resolver NSStationNameResolver) load() (*nsStationNameIndex, error) {
+	state := resolver.state
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	now := state.now()
+	if state.index != nil && now.Sub(state.checkedAt) < stationNameIndexCheckInterval {
+		return state.index, nil
+	}
+
+	versions, err := resolver.versionsRepository.FetchAll()
+	if err != nil {
+		return resolver.previousIndex(errors.Wrap(err, "cannot load the version of the stations for resolving station names"))
+	}
+
+	state.checkedAt = now
+	version := versions.Get(referenceDataNSStations)
+	if state.index != nil && state.index.version == version && now.Sub(state.index.builtAt) < stationNameIndexMaxAge {
+		return state.index, nil
+	}
+
+	stations, err := resolver.repository.FetchAll()
+	if err != nil {
+		return resolver.previousIndex(errors.Wrap(err, "cannot load the stations for resolving station names"))
+	}
+
+	state.index = newNSStationNameIndex(stations, version, now)
+	return state.index, nil
+}
+
+// previousIndex returns the index which was built before when the index cannot be rebuilt
+func (resolver NSStationNameResolver) previousIndex(err error) (*nsStationNameIndex, error) {
+	if resolver.state.index == nil {
+		return nil, err
+	}
+
+	log.Printf("using the previous index of station names: %s", err.Error())
+	return resolver.state.index, nil
+}
+
+func newNSStationNameIndex(stations []NSStation, version int, builtAt time.Time) *nsStationNameIndex {
+	index := &nsStationNameIndex{stations: map[string]NSStation{}, version: version, builtAt: builtAt}
+	for _, station := range stations {
+		for _, name := range []string{station.Name, station.CurrentName, station.Code} {
+			index.addName(name, station)
+		}
+	}
+	return index
+}
+
+// addName prefers the current names of the stations over synonyms which are no longer used
+func (index *nsStationNameIndex) addName(name string, station NSStation) {
+	normalizedName := normalizeStationName(name)
+	if normalizedName == "" {
+		return
+	}
+
+	existing, ok := index.stations[normalizedName]
+	if !ok {
+		index.names = append(index.names, normalizedName)
+	}
+
+	if !ok || (existing.IsDepreciated && !station.IsDepreciated) {
+		index.stations[normalizedName] = station
+	}
+}
+
+// normalizeStationName removes diacritics and punctuation and expands common abbreviations
+func normalizeStationName(name string) string {
+	withoutDiacritics, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), name)
+	if err == nil {
+		name = withoutDiacritics
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var normalizedWords []string
+	for _, word := range words {
+		if expanded, ok := stationNameAbbreviations[word]; ok {
+			word = expanded
+		}
+		if word != "" {
+			normalizedWords = append(normalizedWords, word)
+		}
+	}
+
+	return strings.Join(normalizedWords, " ")
+}
+
+// stationNameSimilarity returns a number between 0 and 1 which indicates how similar 2 normalised names are.
+func stationNameSimilarity(a, b string) float64 {
+	similarity := levenshteinSimilarity(a, b)
+
+	// Transactions often contain a shortened name e.g "Amsterdam Zuid" for "Amsterdam Zuid WTC"
+	if strings.HasPrefix(b, a+" ") || strings.HasPrefix(a, b+" ") {
+		similarity = (1 + similarity) / 2
+	}
+
+	return similarity
+}
+
+func levenshteinSimilarity(a, b string) float64 {
+	first, second := []rune(a), []rune(b)
+	longest := len(first)
+	if len(second) > longest {
+		longest = len(second)
+	}
+	if longest == 0 {
+		return 1
+	}
+
+	previous := make([]int, len(second)+1)
+	current := make([]int, len(second)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(first); i++ {
+		current[0] = i
+		for j := 1; j <= len(second); j++ {
+			cost := 1
+			if first[i-1] == second[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return 1 - float64(previous[len(second)])/float64(longest)
+}
+
+func minInt(values ...int) int {
+	min := values[0]
+	for _, value := range values[1:] {
+		if value < min {
+			min = value
+		}
+	}
+	return min
+}
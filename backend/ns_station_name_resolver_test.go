@@ -0,0 +1,158 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestNSStationNameResolverResolve(t *testing.T) {
+	resolver := NewNSStationNameResolver(&memoryNSStationsRepository{stations: testNSStations}, countingVersionsRepository{})
+
+	tests := []struct {
+		name       string
+		code       string
+		confidence float64
+		err        error
+	}{
+		{name: "Amsterdam Centraal", code: "asd", confidence: 1},
+		{name: "AMSTERDAM C.", code: "asd", confidence: 1},
+		{name: "Amsterdam CS", code: "asd", confidence: 1},
+		{name: "s Hertogenbosch", code: "ht", confidence: 1},
+		{name: "'S-HERTOGENBOSCH", code: "ht", confidence: 1},
+		{name: "Utrecht Centraall", code: "ut", confidence: 1 - 1.0/17},
+		{name: "Amsterdam Zuid WTC", code: "asdz", confidence: 1},
+		{name: "Amsterdam Zd", code: "asdz", confidence: 1},
+		{name: "Zaandax", err: ErrorInvalidStationName},
+		{name: "Rotterdam", err: ErrorInvalidStationName},
+		{name: "...", err: ErrorInvalidStationName},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			match, _, err := resolver.Resolve(test.name)
+			if err != test.err {
+				t.Fatalf("Resolve(%s) error = %v, want %v", test.name, err, test.err)
+			}
+
+			if err == nil && (match.Station.Code != test.code || math.Abs(match.Confidence-test.confidence) > 0.001) {
+				t.Errorf("Resolve(%s) = %s with confidence %f, want %s with confidence %f", test.name, match.Station.Code, match.Confidence, test.code, test.confidence)
+			}
+		})
+	}
+}
+
+func TestNSStationNameResolverResolveSuggestsCandidates(t *testing.T) {
+	resolver := NewNSStationNameResolver(&memoryNSStationsRepository{stations: testNSStations}, countingVersionsRepository{})
+
+	_, candidates, err := resolver.Resolve("Zaandax")
+	if err != ErrorInvalidStationName {
+		t.Fatalf("Resolve() error = %v, want %v", err, ErrorInvalidStationName)
+	}
+
+	if len(candidates) < 2 || candidates[0].Station.Code != "zdk" || candidates[1].Station.Code != "zdm" {
+		t.Errorf("candidates = %+v, want the ambiguous stations zdk and zdm", candidates)
+	}
+}
+
+func TestNSStationNameResolverRebuildsTheIndex(t *testing.T) {
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	repository := &memoryNSStationsRepository{stations: testNSStations[:1]}
+	versions := countingVersionsRepository{}
+	resolver := NewNSStationNameResolver(repository, versions)
+	resolver.state.now = func() time.Time { return now }
+
+	resolve := func() error {
+		_, _, err := resolver.Resolve("Utrecht Centraal")
+		return err
+	}
+
+	tests := []struct {
+		name    string
+		change  func()
+		wait    time.Duration
+		loads   int
+		isFound bool
+	}{
+		{name: "the index is built", loads: 1},
+		{name: "a station is added", change: func() { repository.stations = testNSStations }, wait: time.Second, loads: 1},
+		{name: "the version is checked", wait: stationNameIndexCheckInterval, loads: 1},
+		{name: "the stations are synced", change: func() { _, _ = versions.Increment(referenceDataNSStations) }, wait: time.Second, loads: 1},
+		{name: "the version changed", wait: stationNameIndexCheckInterval, loads: 2, isFound: true},
+		{name: "the index is old", wait: stationNameIndexMaxAge, loads: 3, isFound: true},
+		{name: "the stations cannot be loaded", change: func() { repository.err = errors.New("connection refused") }, wait: stationNameIndexMaxAge, loads: 4, isFound: true},
+	}
+
+	for _, test := range tests {
+		if test.change != nil {
+			test.change()
+		}
+		now = now.Add(test.wait)
+
+		err := resolve()
+		if (err == nil) != test.isFound {
+			t.Errorf("%s: Resolve() error = %v, want found = %t", test.name, err, test.isFound)
+		}
+
+		if repository.loads != test.loads {
+			t.Errorf("%s: the stations were loaded %d times, want %d", test.name, repository.loads, test.loads)
+		}
+	}
+}
+
+func TestNSStationNameResolverReturnsTheLoadError(t *testing.T) {
+	resolver := NewNSStationNameResolver(&memoryNSStationsRepository{err: errors.New("connection refused")}, countingVersionsRepository{})
+
+	_, _, err := resolver.Resolve("Utrecht Centraal")
+	if err == nil || err == ErrorInvalidStationName {
+		t.Errorf("Resolve() error = %v, want the error of the repository", err)
+	}
+}
+
+func TestLevenshteinSimilarity(t *testing.T) {
+	tests := []struct {
+		a          string
+		b          string
+		similarity float64
+	}{
+		{a: "", b: "", similarity: 1},
+		{a: "utrecht", b: "utrecht", similarity: 1},
+		{a: "utrecht", b: "", similarity: 0},
+		{a: "kitten", b: "sitting", similarity: 1 - 3.0/7},
+		{a: "zaandam", b: "zaandak", similarity: 1 - 1.0/7},
+		{a: "düren", b: "duren", similarity: 1 - 1.0/5},
+	}
+
+	for _, test := range tests {
+		similarity := levenshteinSimilarity(test.a, test.b)
+		if math.Abs(similarity-test.similarity) > 0.0001 {
+			t.Errorf("levenshteinSimilarity(%s, %s) = %f, want %f", test.a, test.b, similarity, test.similarity)
+		}
+
+		if reversed := levenshteinSimilarity(test.b, test.a); reversed != similarity {
+			t.Errorf("levenshteinSimilarity(%s, %s) = %f, want %f", test.b, test.a, reversed, similarity)
+		}
+	}
+}
+
+func TestNormalizeStationName(t *testing.T) {
+	tests := []struct {
+		name       string
+		normalized string
+	}{
+		{name: "'s-Hertogenbosch", normalized: "hertogenbosch"},
+		{name: "Den Haag HS", normalized: "den haag holland spoor"},
+		{name: "Amsterdam C.", normalized: "amsterdam centraal"},
+		{name: "Köln Hbf", normalized: "koln hauptbahnhof"},
+		{name: "Station Zwolle", normalized: "zwolle"},
+		{name: "  ", normalized: ""},
+	}
+
+	for _, test := range tests {
+		if normalized := normalizeStationName(test.name); normalized != test.normalized {
+			t.Errorf("normalizeStationName(%s) = '%s', want '%s'", test.name, normalized, test.normalized)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+// OperatorRegistry contains the operators which are registered at startup
+type OperatorRegistry struct {
+	operators []Operator
+}
+
+// NewOperatorRegistry creates a new instance of the OperatorRegistry
+func NewOperatorRegistry() *OperatorRegistry {
+	return &OperatorRegistry{}
+}
+
+// Register adds operators to the registry
+func (registry *OperatorRegistry) Register(operators ...Operator) {
+	registry.operators = append(registry.operators, operators...)
+}
+
+// Operators returns all the registered operators
+func (registry *OperatorRegistry) Operators() []Operator {
+	return registry.operators
+}
+
+// OperatorForRecord returns the first registered operator which owns the raw record
+func (registry *OperatorRegistry) OperatorForRecord(record RawRecord) (operator Operator, ok bool) {
+	for _, operator := range registry.operators {
+		if operator.OwnsRecord(record) {
+			return operator, true
+		}
+	}
+	return operator, false
+}
+
+// Operator returns the registered operator with a company name
+func (registry *OperatorRegistry) Operator(companyName CompanyName) (operator Operator, ok bool) {
+	for _, operator := range registry.operators {
+		if operator.CompanyName() == companyName {
+			return operator, true
+		}
+	}
+	return operator, false
+}
+
+// DiscountProducts returns the discount products of all the registered operators
+func (registry *OperatorRegistry) DiscountProducts() (products []DiscountProduct) {
+	for _, operator := range registry.operators {
+		products = append(products, operator.DiscountProducts()...)
+	}
+	return products
+}
+
+// calculatorProduct adapts a calculator to the DiscountProduct interface
+type calculatorProduct struct {
+	name      string
+	calculate func(records []EnrichedRecord) CalculationResult
+}
+
+// Name returns the name of the discount product
+func (product calculatorProduct) Name() string {
+	return product.name
+}
+
+// RuleSetVersion returns the version of the rules which are used by the calculators
+func (product calculatorProduct) RuleSetVersion() string {
+	return calculationRuleSetVersion
+}
+
+// Calculate calculates the price of the journeys with the discount product
+func (product calculatorProduct) Calculate(records []EnrichedRecord) CalculationResult {
+	result := product.calculate(records)
+	result.Product = product.name
+	return result
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+)
+
+// The API stores the credentials of an import job under this key followed by the job ID
+const keyPrefixImportCredentials = "import-credentials:"
+
+// RedisImportCredentialsStore reads the credentials of import jobs which were queued by the API
+type RedisImportCredentialsStore struct {
+	client *redis.Client
+}
+
+// NewRedisImportCredentialsStore creates a new instance of the RedisImportCredentialsStore
+func NewRedisImportCredentialsStore(client *redis.Client) RedisImportCredentialsStore {
+	return RedisImportCredentialsStore{client}
+}
+
+// Get returns the credentials of an import job. ErrNotFound is returned when the credentials have expired.
+func (store RedisImportCredentialsStore) Get(jobID TransactionID) (credentials ImportCredentials, err error) {
+	value, err := store.client.Get(context.Background(), keyPrefixImportCredentials+jobID.String()).Result()
+	if err == redis.Nil {
+		return credentials, ErrNotFound
+	}
+	if err != nil {
+		return credentials, errors.Wrapf(err, "cannot fetch credentials for job with id '%s'", jobID.String())
+	}
+
+	err = json.Unmarshal([]byte(value), &credentials)
+	if err != nil {
+		return credentials, errors.Wrapf(err, "cannot decode credentials for job with id '%s'", jobID.String())
+	}
+
+	return credentials, nil
+}
+
+// Delete removes the credentials of an import job once they are no longer needed
+func (store RedisImportCredentialsStore) Delete(jobID TransactionID) (err error) {
+	err = store.client.Del(context.Background(), keyPrefixImportCredentials+jobID.String()).Err()
+	if err != nil {
+		return errors.Wrapf(err, "cannot delete credentials for job with id '%s'", jobID.String())
+	}
+
+	return nil
+}
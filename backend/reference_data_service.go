@@ -0,0 +1,70 @@
+package main
+
+import (
+	"log"
+
+	"github.com/pkg/errors"
+)
+
+// ReferenceDataService keeps track of the versions of the reference data and queues jobs for the imports which were
+// processed with an older version when the data changes.
+type ReferenceDataService struct {
+	versionsRepository        ReferenceDataVersionsRepository
+	enrichedRecordsRepository EnrichedRecordsRepository
+	jobsRepository            JobsRepository
+	importOwnersRepository    ImportOwnersRepository
+}
+
+// NewReferenceDataService creates a new instance of the ReferenceDataService
+func NewReferenceDataService(
+	versionsRepository ReferenceDataVersionsRepository,
+	enrichedRecordsRepository EnrichedRecordsRepository,
+	jobsRepository JobsRepository,
+	importOwnersRepository ImportOwnersRepository,
+) ReferenceDataService {
+	return ReferenceDataService{versionsRepository, enrichedRecordsRepository, jobsRepository, importOwnersRepository}
+}
+
+// CurrentVersions returns the current version of all the reference data
+func (service ReferenceDataService) CurrentVersions() (versions ReferenceDataVersions, err error) {
+	return service.versionsRepository.FetchAll()
+}
+
+// Changed bumps the version of the reference data and queues the jobs for the affected imports.
+// Holidays are only used to calculate prices so the imports don't have to be enriched again.
+// The jobs belong to the owner of the import so that the owner can follow their progress.
+func (service ReferenceDataService) Changed(name ReferenceDataName) (err error) {
+	version, err := service.versionsRepository.Increment(name)
+	if err != nil {
+		return err
+	}
+
+	transactionIDs, err := service.enrichedRecordsRepository.FetchStaleTransactionIDs(name, version)
+	if err != nil {
+		return err
+	}
+
+	jobType := jobTypeReEnrichment
+	if name == referenceDataNationalHolidays {
+		jobType = jobTypeRecalculation
+	}
+
+	log.Printf("%s changed to version %d, queueing %s jobs for %d imports", name, version, jobType, len(transactionIDs))
+	for _, transactionID := range transactionIDs {
+		job := NewJob(jobType, transactionID, name.String()+" changed")
+		userID, err := service.importOwnersRepository.FetchUserID(transactionID)
+		if err != nil && err != ErrNotFound {
+			return errors.Wrapf(err, "cannot queue job after %s changed", name)
+		}
+		if err == nil {
+			job.UserID = &userID
+		}
+
+		err = service.jobsRepository.Store(job)
+		if err != nil {
+			return errors.Wrapf(err, "cannot queue job after %s changed", name)
+		}
+	}
+
+	return nil
+}
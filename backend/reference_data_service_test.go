@@ -0,0 +1,75 @@
+package main
+
+import (
+	"testing"
+)
+
+// staleRecordsRepository is an EnrichedRecordsRepository where the same imports are always stale
+type staleRecordsRepository struct {
+	EnrichedRecordsRepository
+	transactionIDs []TransactionID
+}
+
+func (repository staleRecordsRepository) FetchStaleTransactionIDs(name ReferenceDataName, version int) (transactionIDs []TransactionID, err error) {
+	return repository.transactionIDs, nil
+}
+
+// countingVersionsRepository is a ReferenceDataVersionsRepository which keeps the versions in memory
+type countingVersionsRepository ReferenceDataVersions
+
+func (repository countingVersionsRepository) Increment(name ReferenceDataName) (version int, err error) {
+	repository[name.String()]++
+	return repository[name.String()], nil
+}
+
+func (repository countingVersionsRepository) FetchAll() (versions ReferenceDataVersions, err error) {
+	return ReferenceDataVersions(repository), nil
+}
+
+func TestReferenceDataServiceChangedQueuesJobsForTheOwner(t *testing.T) {
+	ownedImport, userID, cliImport := NewTransactionID(), NewTransactionID(), NewTransactionID()
+
+	tests := []struct {
+		name    string
+		data    ReferenceDataName
+		jobType JobType
+	}{
+		{name: "stations are enriched again", data: referenceDataNSStations, jobType: jobTypeReEnrichment},
+		{name: "holidays are calculated again", data: referenceDataNationalHolidays, jobType: jobTypeRecalculation},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jobsRepository := &memoryJobsRepository{}
+			service := NewReferenceDataService(
+				countingVersionsRepository{},
+				staleRecordsRepository{transactionIDs: []TransactionID{ownedImport, cliImport}},
+				jobsRepository,
+				memoryImportOwnersRepository{ownedImport: userID},
+			)
+
+			err := service.Changed(test.data)
+			if err != nil {
+				t.Fatalf("Changed() error = %v", err)
+			}
+
+			if len(jobsRepository.jobs) != 2 {
+				t.Fatalf("Changed() queued %d jobs, want 2", len(jobsRepository.jobs))
+			}
+
+			for _, job := range jobsRepository.jobs {
+				if job.Type != test.jobType {
+					t.Errorf("job type = %s, want %s", job.Type, test.jobType)
+				}
+			}
+
+			if owner := jobsRepository.jobs[0].UserID; owner == nil || *owner != userID {
+				t.Errorf("UserID of the owned import job = %v, want %s", owner, userID)
+			}
+
+			if owner := jobsRepository.jobs[1].UserID; owner != nil {
+				t.Errorf("UserID of the import without an owner = %s, want nil", owner)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+// Tariffs of the regional bus, tram and metro operators for 2020.
+var regionalOperatorTariffs = []struct {
+	companyName       CompanyName
+	boardingTariff    int
+	pricePerKilometre float64
+}{
+	{companyNameGVB, 99, 16.8},
+	{companyNameHTM, 99, 16.5},
+	{companyNameArriva, 99, 17.8},
+	{companyNameConnexxion, 99, 17.5},
+	{companyNameKeolis, 99, 17.0},
+	{companyNameQbuzz, 99, 17.6},
+}
+
+// NewRegionalOperators creates the operators for the regional bus, tram and metro companies.
+// Travellers can only travel on these operators without a discount for now.
+func NewRegionalOperators(stopsService GTFSStopsService, offPeakService NSOffPeakService) (operators []Operator) {
+	for _, tariff := range regionalOperatorTariffs {
+		fareService := NewDistanceFareService(tariff.companyName, tariff.boardingTariff, tariff.pricePerKilometre)
+		operators = append(operators, NewDistanceFareOperator(
+			fareService,
+			stopsService.ForCompany(tariff.companyName),
+			newDistanceFareNoDiscountProduct(fareService, offPeakService),
+		))
+	}
+	return operators
+}
@@ -0,0 +1,44 @@
+package main
+
+// RET tariffs for 2020. A journey costs the boarding tariff plus a price per kilometre.
+const (
+	retBoardingTariff    = 99
+	retPricePerKilometre = float64(16.2)
+)
+
+// RET discount products
+const (
+	productRETDalVoordeel = "RET Dal Voordeel"
+	productRETMaand       = "RET Maandabonnement"
+)
+
+// NewRETOperator creates the operator for journeys with the RET in Rotterdam
+func NewRETOperator(stopsService GTFSStopsService, offPeakService NSOffPeakService) DistanceFareOperator {
+	fareService := NewDistanceFareService(companyNameRET, retBoardingTariff, retPricePerKilometre)
+
+	return NewDistanceFareOperator(
+		fareService,
+		stopsService,
+		newDistanceFareNoDiscountProduct(fareService, offPeakService),
+		calculatorProduct{productRETDalVoordeel, func(records []EnrichedRecord) CalculationResult {
+			result := NewRETDalVoordeelCalculator(fareService, offPeakService).Calculate(records)
+			return CalculationResult{
+				CompanyName:       companyNameRET,
+				JourneyCount:      result.PeakJourneyCount + result.OffPeakJourneyCount,
+				JourneysPrice:     result.PeakPrice.AddAmount(result.OffPeakPrice.Value()),
+				SubscriptionPrice: result.SubscriptionPrice,
+				Details:           result,
+			}
+		}},
+		calculatorProduct{productRETMaand, func(records []EnrichedRecord) CalculationResult {
+			result := NewRETMaandCalculator(fareService).Calculate(records)
+			return CalculationResult{
+				CompanyName:       companyNameRET,
+				JourneyCount:      result.JourneyCount,
+				JourneysPrice:     NewEUR(0),
+				SubscriptionPrice: result.SubscriptionPrice,
+				Details:           result,
+			}
+		}},
+	)
+}
@@ -2,8 +2,8 @@ package errorhandler
 
 import (
 	"context"
-	"time"
 
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/tracing"
 	"github.com/getsentry/sentry-go"
 )
 
@@ -19,14 +19,35 @@ func NewSentryErrorHandler(options sentry.ClientOptions) (ErrorHandler, error) {
 		return nil, err
 	}
 
-	// Flush buffered events before the program terminates.
-	// Set the timeout to the maximum duration the program can afford to wait.
-	defer sentry.Flush(2 * time.Second)
-
 	return &SentryErrorHandler{hub: sentry.CurrentHub()}, nil
 }
 
-// CaptureError captures an error
-func (sentry *SentryErrorHandler) CaptureError(_ context.Context, err error) {
-	sentry.hub.CaptureException(err)
+// CaptureError captures an error. The IDs of the request and the trace in the context are added to the event.
+func (handler *SentryErrorHandler) CaptureError(ctx context.Context, err error) {
+	request, hasRequest := tracing.RequestFromContext(ctx)
+	traceID := tracing.TraceID(ctx)
+	if !hasRequest && traceID == "" {
+		handler.hub.CaptureException(err)
+		return
+	}
+
+	// the scope of a clone is not shared with the other requests which capture errors at the same time
+	hub := handler.hub.Clone()
+	hub.ConfigureScope(func(scope *sentry.Scope) {
+		if hasRequest {
+			scope.SetTag("request_id", request.ID)
+			if request.UserID != "" {
+				scope.SetUser(sentry.User{ID: request.UserID})
+			}
+			if request.OperationName != "" {
+				scope.SetTag("operation", request.OperationName)
+			}
+		}
+
+		if traceID != "" {
+			scope.SetTag("trace_id", traceID)
+		}
+	})
+
+	hub.CaptureException(err)
 }
@@ -0,0 +1,64 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// statusPending is the status of a job which has not run yet
+	statusPending = "pending"
+
+	// duplicateKeyErrorCode is the code of the error which is returned when a write violates a unique index
+	duplicateKeyErrorCode = 11000
+)
+
+// CreateIndexes creates the indexes of the jobs. A job with an idempotency key is unique per user so two requests with
+// the same key cannot both queue a job.
+func CreateIndexes(ctx context.Context, collection *mongo.Collection) error {
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "idempotency_key", Value: 1}},
+		Options: options.Index().
+			SetName("user_id_idempotency_key").
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"idempotency_key": bson.M{"$exists": true}}),
+	})
+	return errors.Wrap(err, "cannot create the indexes of the jobs")
+}
+
+// Store saves the document of a job. A job with an idempotency key is stored once per user and a job without a key is
+// not stored when a pending job of the same type already exists for the import. The jobs are stored by the API and by
+// the pipeline so both use this function.
+func Store(ctx context.Context, collection *mongo.Collection, document bson.M) error {
+	filter := bson.M{"type": document["type"], "transaction_id": document["transaction_id"], "status": statusPending}
+	if key, ok := document["idempotency_key"]; ok && key != "" {
+		filter = bson.M{"user_id": document["user_id"], "idempotency_key": key}
+	}
+
+	_, err := collection.UpdateOne(ctx, filter, bson.M{"$setOnInsert": document}, options.Update().SetUpsert(true))
+	// a concurrent request with the same idempotency key stored its job first
+	if isDuplicateKeyError(err) {
+		return nil
+	}
+
+	return errors.Wrapf(err, "cannot store %s job for transaction id '%s'", document["type"], document["transaction_id"])
+}
+
+// isDuplicateKeyError checks if a write failed because of a unique index
+func isDuplicateKeyError(err error) bool {
+	switch err := err.(type) {
+	case mongo.WriteException:
+		for _, writeError := range err.WriteErrors {
+			if writeError.Code == duplicateKeyErrorCode {
+				return true
+			}
+		}
+	case mongo.CommandError:
+		return err.Code == duplicateKeyErrorCode
+	}
+	return false
+}
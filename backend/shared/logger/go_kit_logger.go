@@ -1,8 +1,10 @@
 package logger
 
 import (
+	"context"
 	"io"
 
+	"github.com/NdoleStudio/ov-chipkaart-dashboard/backend/shared/tracing"
 	"github.com/go-kit/kit/log"
 )
 
@@ -27,3 +29,22 @@ func (logger GoKitLogger) Log(keyVals ...interface{}) error {
 
 	return err
 }
+
+// LogContext logs with the IDs of the request and the trace in the context
+func (logger GoKitLogger) LogContext(ctx context.Context, keyVals ...interface{}) error {
+	if request, ok := tracing.RequestFromContext(ctx); ok {
+		keyVals = append(keyVals, "request_id", request.ID)
+		if request.UserID != "" {
+			keyVals = append(keyVals, "user_id", request.UserID)
+		}
+		if request.OperationName != "" {
+			keyVals = append(keyVals, "operation", request.OperationName)
+		}
+	}
+
+	if traceID := tracing.TraceID(ctx); traceID != "" {
+		keyVals = append(keyVals, "trace_id", traceID)
+	}
+
+	return logger.Log(keyVals...)
+}
@@ -1,6 +1,10 @@
 package logger
 
+import "context"
+
 // Logger is the interface used for implementing loggers
 type Logger interface {
 	Log(...interface{}) error
+	// LogContext adds the IDs of the request in the context e.g the request ID so the logs of a request can be correlated
+	LogContext(ctx context.Context, keyVals ...interface{}) error
 }
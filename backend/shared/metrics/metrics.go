@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "ov_chipkaart"
+
+var (
+	graphQLOperationDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "graphql_operation_duration_seconds",
+		Help:      "Duration of the GraphQL operations.",
+	}, []string{"operation"})
+
+	graphQLErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "graphql_errors_total",
+		Help:      "Number of errors in the GraphQL responses by error code.",
+	}, []string{"operation", "code"})
+
+	mongoDBCommandDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "mongodb_command_duration_seconds",
+		Help:      "Duration of the MongoDB commands by collection, every repository has its own collection.",
+	}, []string{"collection", "command", "status"})
+
+	cacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "Number of cache lookups by cache and result (hit or miss).",
+	}, []string{"cache", "result"})
+
+	nsAPIRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ns_api_requests_total",
+		Help:      "Number of requests to the NS API by endpoint and status code.",
+	}, []string{"endpoint", "status"})
+
+	nsAPIFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ns_api_failures_total",
+		Help:      "Number of requests to the NS API which failed or returned a server error.",
+	}, []string{"endpoint"})
+
+	enrichmentRecords = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "enrichment_records_total",
+		Help:      "Number of enriched records by result (enriched or failed).",
+	}, []string{"result"})
+
+	enrichmentDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "enrichment_duration_seconds",
+		Help:      "Duration of the enrichment of a batch of raw records.",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 2, 12),
+	})
+)
+
+// Handler serves the metrics in the Prometheus format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ListenAndServe serves the metrics on /metrics of a separate HTTP server so the metrics are not public when the
+// address is not exposed
+func ListenAndServe(address string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+	return http.ListenAndServe(address, mux)
+}
+
+// ObserveGraphQLOperation records the duration and the error codes of a GraphQL operation
+func ObserveGraphQLOperation(operation string, duration time.Duration, errorCodes []string) {
+	graphQLOperationDuration.WithLabelValues(operation).Observe(duration.Seconds())
+	for _, code := range errorCodes {
+		graphQLErrors.WithLabelValues(operation, code).Inc()
+	}
+}
+
+// CountCacheRequest records a hit or a miss of a cache
+func CountCacheRequest(cache string, isHit bool) {
+	result := "miss"
+	if isHit {
+		result = "hit"
+	}
+
+	cacheRequests.WithLabelValues(cache, result).Inc()
+}
+
+// ObserveNSAPIRequest records a request to the NS API. The status code is 0 when the request failed without a response.
+func ObserveNSAPIRequest(endpoint string, statusCode int, err error) {
+	status := strconv.Itoa(statusCode)
+	if err != nil {
+		status = "error"
+	}
+
+	nsAPIRequests.WithLabelValues(endpoint, status).Inc()
+	if err != nil || statusCode >= http.StatusInternalServerError {
+		nsAPIFailures.WithLabelValues(endpoint).Inc()
+	}
+}
+
+// ObserveEnrichment records the result of the enrichment of a batch of raw records
+func ObserveEnrichment(enrichedCount int, failedCount int, duration time.Duration) {
+	enrichmentRecords.WithLabelValues("enriched").Add(float64(enrichedCount))
+	enrichmentRecords.WithLabelValues("failed").Add(float64(failedCount))
+	enrichmentDuration.Observe(duration.Seconds())
+}
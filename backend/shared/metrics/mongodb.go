@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// NewMongoDBMonitor creates a command monitor which records the duration of the MongoDB commands.
+// The events are also sent to the next monitor e.g the monitor which traces the commands.
+func NewMongoDBMonitor(next *event.CommandMonitor) *event.CommandMonitor {
+	var mu sync.Mutex
+	collections := map[int64]string{}
+
+	observe := func(requestID int64, command string, duration time.Duration, status string) {
+		mu.Lock()
+		collection := collections[requestID]
+		delete(collections, requestID)
+		mu.Unlock()
+
+		mongoDBCommandDuration.WithLabelValues(collection, command, status).Observe(duration.Seconds())
+	}
+
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, startedEvent *event.CommandStartedEvent) {
+			// the first element of a command is the name of the command and the collection e.g {"find": "users"}
+			collection, _ := startedEvent.Command.Lookup(startedEvent.CommandName).StringValueOK()
+
+			mu.Lock()
+			collections[startedEvent.RequestID] = collection
+			mu.Unlock()
+
+			if next != nil && next.Started != nil {
+				next.Started(ctx, startedEvent)
+			}
+		},
+		Succeeded: func(ctx context.Context, succeededEvent *event.CommandSucceededEvent) {
+			observe(succeededEvent.RequestID, succeededEvent.CommandName, time.Duration(succeededEvent.DurationNanos), "succeeded")
+
+			if next != nil && next.Succeeded != nil {
+				next.Succeeded(ctx, succeededEvent)
+			}
+		},
+		Failed: func(ctx context.Context, failedEvent *event.CommandFailedEvent) {
+			observe(failedEvent.RequestID, failedEvent.CommandName, time.Duration(failedEvent.DurationNanos), "failed")
+
+			if next != nil && next.Failed != nil {
+				next.Failed(ctx, failedEvent)
+			}
+		},
+	}
+}
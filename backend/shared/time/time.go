@@ -3,4 +3,7 @@ package time
 const (
 	// DefaultFormat is the default format for timestamps
 	DefaultFormat = "2016-01-02 15:04:05"
+
+	// DateFormat is the format for dates without a time
+	DateFormat = "2006-01-02"
 )
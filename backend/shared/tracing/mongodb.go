@@ -0,0 +1,54 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/api/kv"
+	"go.opentelemetry.io/otel/api/trace"
+)
+
+// NewMongoDBMonitor creates a command monitor for the MongoDB client which starts a span for every command
+func NewMongoDBMonitor() *event.CommandMonitor {
+	var mu sync.Mutex
+	spans := map[int64]trace.Span{}
+
+	endSpan := func(ctx context.Context, requestID int64, err error) {
+		mu.Lock()
+		span, ok := spans[requestID]
+		delete(spans, requestID)
+		mu.Unlock()
+
+		if ok {
+			EndSpan(ctx, span, err)
+		}
+	}
+
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, startedEvent *event.CommandStartedEvent) {
+			// the first element of a command is the name of the command and the collection e.g {"find": "users"}
+			collection, _ := startedEvent.Command.Lookup(startedEvent.CommandName).StringValueOK()
+
+			_, span := StartSpan(
+				ctx,
+				"mongodb."+startedEvent.CommandName,
+				kv.String("db.system", "mongodb"),
+				kv.String("db.name", startedEvent.DatabaseName),
+				kv.String("db.operation", startedEvent.CommandName),
+				kv.String("db.mongodb.collection", collection),
+			)
+
+			mu.Lock()
+			spans[startedEvent.RequestID] = span
+			mu.Unlock()
+		},
+		Succeeded: func(ctx context.Context, succeededEvent *event.CommandSucceededEvent) {
+			endSpan(ctx, succeededEvent.RequestID, nil)
+		},
+		Failed: func(ctx context.Context, failedEvent *event.CommandFailedEvent) {
+			endSpan(ctx, failedEvent.RequestID, errors.New(failedEvent.Failure))
+		},
+	}
+}
@@ -0,0 +1,66 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+)
+
+// Request contains the IDs which correlate the logs, the errors and the spans of an HTTP request
+type Request struct {
+	ID            string
+	UserID        string
+	OperationName string
+}
+
+type requestKey struct{}
+
+// requestHolder is shared by all the contexts of a request so the IDs which are found by the inner handlers are also
+// visible to the outer middlewares e.g the logging middleware logs the user ID which is found by the auth middleware.
+type requestHolder struct {
+	mu      sync.RWMutex
+	request Request
+}
+
+// WithRequest adds a new request to the context
+func WithRequest(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestKey{}, &requestHolder{request: Request{ID: requestID}})
+}
+
+// RequestFromContext returns the request in the context
+func RequestFromContext(ctx context.Context) (request Request, ok bool) {
+	holder, ok := ctx.Value(requestKey{}).(*requestHolder)
+	if !ok {
+		return request, false
+	}
+
+	holder.mu.RLock()
+	defer holder.mu.RUnlock()
+
+	return holder.request, true
+}
+
+// SetUserID sets the ID of the logged in user on the request in the context
+func SetUserID(ctx context.Context, userID string) {
+	update(ctx, func(request *Request) {
+		request.UserID = userID
+	})
+}
+
+// SetOperationName sets the name of the GraphQL operation on the request in the context
+func SetOperationName(ctx context.Context, operationName string) {
+	update(ctx, func(request *Request) {
+		request.OperationName = operationName
+	})
+}
+
+func update(ctx context.Context, fn func(request *Request)) {
+	holder, ok := ctx.Value(requestKey{}).(*requestHolder)
+	if !ok {
+		return
+	}
+
+	holder.mu.Lock()
+	defer holder.mu.Unlock()
+
+	fn(&holder.request)
+}
@@ -0,0 +1,74 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/api/global"
+	"go.opentelemetry.io/otel/api/kv"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/exporters/trace/stdout"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const (
+	tracerName = "github.com/NdoleStudio/ov-chipkaart-dashboard/backend"
+
+	// ExporterNone disables tracing, the spans are not recorded
+	ExporterNone = "none"
+
+	// ExporterStdout writes the spans as JSON to stdout e.g when debugging locally
+	ExporterStdout = "stdout"
+)
+
+// Install sets the global trace provider which exports the spans. The default provider does not record spans.
+func Install(exporter string) error {
+	switch exporter {
+	case "", ExporterNone:
+		return nil
+	case ExporterStdout:
+		stdoutExporter, err := stdout.NewExporter(stdout.Options{})
+		if err != nil {
+			return errors.Wrap(err, "cannot create the stdout span exporter")
+		}
+
+		provider, err := sdktrace.NewProvider(sdktrace.WithSyncer(stdoutExporter))
+		if err != nil {
+			return errors.Wrap(err, "cannot create the trace provider")
+		}
+
+		global.SetTraceProvider(provider)
+		return nil
+	default:
+		return errors.Errorf("the span exporter '%s' is not supported, use '%s' or '%s'", exporter, ExporterStdout, ExporterNone)
+	}
+}
+
+// StartSpan starts a span which is a child of the span in the context. The request ID is added to the span.
+func StartSpan(ctx context.Context, name string, attributes ...kv.KeyValue) (context.Context, trace.Span) {
+	if request, ok := RequestFromContext(ctx); ok {
+		attributes = append(attributes, kv.String("request.id", request.ID))
+	}
+
+	return global.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// EndSpan records the error of the traced operation and ends the span
+func EndSpan(ctx context.Context, span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(ctx, err)
+		span.SetAttributes(kv.Bool("error", true))
+	}
+
+	span.End()
+}
+
+// TraceID returns the ID of the trace of the span in the context. It is empty when tracing is disabled.
+func TraceID(ctx context.Context) string {
+	spanContext := trace.SpanFromContext(ctx).SpanContext()
+	if !spanContext.HasTraceID() {
+		return ""
+	}
+
+	return spanContext.TraceID.String()
+}
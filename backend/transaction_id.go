@@ -31,6 +31,21 @@ func (id *TransactionID) UnmarshalBSONValue(_ bsontype.Type, raw []byte) error {
 	return nil
 }
 
+// MarshalText converts a transaction id into a string e.g when it's encoded as JSON
+func (id TransactionID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText converts a string into a transaction id
+func (id *TransactionID) UnmarshalText(text []byte) error {
+	uid, err := uuid.Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*id = TransactionID(uid)
+	return nil
+}
+
 // NewTransactionID generates a new UUID
 func NewTransactionID() TransactionID {
 	return TransactionID(uuid.New())